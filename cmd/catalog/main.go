@@ -0,0 +1,89 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/catalog"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/config"
+	"github.com/urfave/cli/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func main() {
+	app := &cli.App{
+		Name:  "catalog",
+		Usage: "import products into the local catalog",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "file",
+				Aliases:  []string{"f"},
+				Usage:    "catalog file to import, gzip compressed if it ends with .gz",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "format of the file : csv, off or off-jsonl",
+				Value: string(catalog.FormatCSV),
+			},
+		},
+		Action: run,
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(c *cli.Context) error {
+	ctx := context.Background()
+
+	conf, err := config.NewConfig("config.yml")
+	if err != nil {
+		return err
+	}
+
+	// database client
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(conf.BuildMongoDBConnexionString()))
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(ctx)
+
+	// database connection test
+	if err := client.Ping(ctx, readpref.Primary()); err != nil {
+		return err
+	}
+
+	repo := catalog.NewMongoDBRepository(client.Database(conf.Database.Name).Collection(conf.Database.ProductsCollection))
+
+	file, err := os.Open(c.String("file"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(c.String("file"), ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	report, err := catalog.Import(ctx, repo, r, catalog.Format(c.String("format")))
+	if err != nil {
+		return err
+	}
+
+	log.Printf("read %d products, imported %d, skipped %d", report.Read, report.Imported, report.Skipped)
+	return nil
+}
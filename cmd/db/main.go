@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/NicolasDutronc/shoppinglist-be/internal/database"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
 	"github.com/NicolasDutronc/shoppinglist-be/pkg/mongomigrate"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -51,7 +52,7 @@ func main() {
 	// get database and collections
 	db := client.Database(config.Migrations.DB)
 
-	m := mongomigrate.New(db, config.Migrations.Collection, database.GetMigrations(), database.GetSeeders())
+	m := mongomigrate.New(db, config.Migrations.Collection, database.GetMigrations(), database.GetSeeders(user.NewArgon2idHasher(user.DefaultArgon2idParams())))
 	app := mongomigrate.GetCLI(m)
 
 	if err := app.Run(os.Args); err != nil {
@@ -25,20 +25,20 @@ type mockListItemAdder struct {
 	h hub.Hub
 }
 
-func (m *mockListItemAdder) AddItem(ctx context.Context, listID string, name string, quantity string) (*list.Item, error) {
+func (m *mockListItemAdder) AddItem(ctx context.Context, listID string, name string, quantity string) (*list.Item, bool, error) {
 	if err := m.h.Publish(ctx, &addItemMessage{
 		BaseMessage: hub.NewBaseMessage(time.Now().Unix(), hub.TopicFromString(listID)),
 		Name:        name,
 		Quantity:    quantity,
 		Done:        false,
 	}); err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	return &list.Item{
 		Name:     name,
 		Quantity: quantity,
 		Done:     false,
-	}, nil
+	}, false, nil
 }
 
 func main() {
@@ -60,8 +60,8 @@ func main() {
 	r.StaticFile("/", "./cmd/examples/hubJSON/home.html")
 
 	r.GET("/connect", hub.SubscribeJSONHandler(h))
-	r.POST("/subscribe", hub.SubscriptionHandler(h))
-	r.POST("/unsubscribe", hub.UnsubscriptionHandler(h))
+	r.POST("/subscribe", hub.SubscriptionHandler(h, nil, nil))
+	r.POST("/unsubscribe", hub.UnsubscriptionHandler(h, nil))
 	r.POST("/lists/:id/send", api.AddItemHandler(&mockListItemAdder{h: h}))
 
 	r.Run(":8080")
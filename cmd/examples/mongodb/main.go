@@ -38,12 +38,12 @@ func main() {
 		ShoppinglistsCollection: collection,
 	}
 
-	list, err := repo.StoreList(ctx, "courses")
+	list, err := repo.StoreList(ctx, "courses", "")
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	chocolat, err := repo.AddItem(ctx, list.ID.Hex(), "chocolat", "800g")
+	chocolat, _, err := repo.AddItem(ctx, list.ID.Hex(), "chocolat", "800g")
 	if err != nil {
 		log.Fatal(err)
 	}
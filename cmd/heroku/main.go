@@ -2,17 +2,27 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"time"
 
 	"github.com/NicolasDutronc/autokey"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/account"
 	"github.com/NicolasDutronc/shoppinglist-be/internal/api"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/attachment"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/audit"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/catalog"
 	"github.com/NicolasDutronc/shoppinglist-be/internal/config"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/invite"
 	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/notify"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/oidc"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/store"
 	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
 	"github.com/NicolasDutronc/shoppinglist-be/pkg/hub"
+	"github.com/NicolasDutronc/shoppinglist-be/pkg/keyring"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -62,10 +72,76 @@ func main() {
 	db := client.Database(conf.Database.Name)
 	listCollection := db.Collection(conf.Database.ListsCollection)
 	userCollection := db.Collection(conf.Database.UsersCollection)
+	sessionCollection := db.Collection(conf.Database.SessionsCollection)
+	roleCollection := db.Collection(conf.Database.RolesCollection)
+	inviteCollection := db.Collection(conf.Database.InvitesCollection)
+	resetTokenCollection := db.Collection(conf.Database.ResetTokensCollection)
+	loginAttemptsCollection := db.Collection(conf.Database.LoginAttemptsCollection)
+	securityEventCollection := db.Collection(conf.Database.SecurityEventsCollection)
+	accessTokenCollection := db.Collection(conf.Database.AccessTokensCollection)
+	oidcIdentityCollection := db.Collection(conf.Database.OIDCIdentitiesCollection)
+	oidcAuthorizationCollection := db.Collection(conf.Database.OIDCAuthorizationsCollection)
+	auditEventCollection := db.Collection(conf.Database.AuditEventsCollection)
+	signingKeyCollection := db.Collection(conf.Database.SigningKeysCollection)
+	filterCollection := db.Collection(conf.Database.FiltersCollection)
+	productCollection := db.Collection(conf.Database.ProductsCollection)
+	storeCollection := db.Collection(conf.Database.StoresCollection)
+	priceCollection := db.Collection(conf.Database.PricesCollection)
+
+	// create the item name normalizer
+	normalizer, err := list.NewNormalizer(conf.Items.Normalization...)
+	if err != nil {
+		log.Fatalf("Error creating the item name normalizer : %v", err.Error())
+	}
 
 	// create data repositories
-	listRepository := list.NewMongoDBRepository(listCollection)
+	listRepository := list.NewMongoDBRepository(listCollection, normalizer)
 	userRepository := user.NewMongoDBRepository(userCollection)
+	sessionRepository := user.NewMongoDBSessionRepository(sessionCollection)
+	roleRepository := user.NewMongoDBRoleRepository(roleCollection)
+	resetTokenRepository := user.NewMongoDBResetTokenRepository(resetTokenCollection)
+	loginAttemptsRepository := user.NewMongoDBLoginAttemptsRepository(loginAttemptsCollection)
+	securityEventRepository := user.NewMongoDBSecurityEventRepository(securityEventCollection)
+	accessTokenRepository := user.NewMongoDBAccessTokenRepository(accessTokenCollection)
+	filterRepository := list.NewMongoDBFilterRepository(filterCollection)
+	catalogRepository := catalog.NewMongoDBRepository(productCollection)
+	storeRepository := store.NewMongoDBRepository(storeCollection, priceCollection)
+	inviteRepository := invite.NewMongoDBRepository(inviteCollection)
+	oidcRepository := oidc.NewMongoDBRepository(oidcIdentityCollection, oidcAuthorizationCollection)
+	auditRepository := audit.NewMongoDBRepository(auditEventCollection)
+
+	// create attachment storage
+	var attachmentStorage attachment.Storage
+	switch conf.Attachments.Backend {
+	case "disk":
+		attachmentStorage, err = attachment.NewDiskStorage(conf.Attachments.Directory)
+	case "gridfs":
+		attachmentStorage, err = attachment.NewGridFSStorage(db, conf.Attachments.Bucket)
+	default:
+		err = fmt.Errorf("unknown attachment backend %s", conf.Attachments.Backend)
+	}
+	if err != nil {
+		log.Fatalf("Error creating the attachment storage : %v", err.Error())
+	}
+
+	// create the notifier delivering the password reset tokens
+	var notifier notify.Notifier
+	switch conf.Notifier.Backend {
+	case "log":
+		out := os.Stdout
+		if conf.Notifier.File != "" {
+			out, err = os.OpenFile(conf.Notifier.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+			if err != nil {
+				log.Fatalf("Error opening the notifier file : %v", err.Error())
+			}
+			defer out.Close()
+		}
+		notifier = notify.NewLogNotifier(out)
+	case "smtp":
+		notifier = notify.NewSMTPNotifier(conf.Notifier.SMTP.Host, conf.Notifier.SMTP.Port, conf.Notifier.SMTP.Username, conf.Notifier.SMTP.Password, conf.Notifier.SMTP.From)
+	default:
+		log.Fatalf("Unknown notifier backend %s", conf.Notifier.Backend)
+	}
 
 	// create and start hub
 	// get the current lists to create topics
@@ -77,25 +153,102 @@ func main() {
 	for i, list := range currentLists {
 		topics[i] = hub.TopicFromString(list.ID.Hex())
 	}
+	// as well as the saved filters
+	currentFilters, err := filterRepository.FindAllFilters(ctx)
+	if err != nil {
+		log.Fatalf("Error getting the saved filters : %v", err.Error())
+	}
+	for _, filter := range currentFilters {
+		topics = append(topics, list.FilterTopic(filter.ID.Hex()))
+	}
 	storage := hub.NewStorage()
 	h, err := hub.NewChannelHub(ctx, storage, topics...)
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	// track who is viewing each list
+	presence := hub.NewPresence(h)
+	if err := h.RegisterSubscriptionHook(ctx, presence); err != nil {
+		log.Fatal(err)
+	}
 	go h.Run(ctx, quit)
 	defer h.Close(ctx)
 
-	// create and start the key manager
-	manager := autokey.NewManager(conf, conf.KeyConfig.Size, conf.KeyConfig.ValidDuration)
-	go manager.Start(quit)
-	defer manager.Stop()
+	// create and start the rotation of the keys signing the tokens
+	var signer keyring.Signer
+	switch conf.KeyConfig.Algorithm {
+	case "HS512":
+		manager := autokey.NewManager(conf, conf.KeyConfig.Size, conf.KeyConfig.ValidDuration)
+		go manager.Start(quit)
+		defer manager.Stop()
+		signer = keyring.NewSymmetric(conf)
+	default:
+		keys, err := keyring.New(ctx, keyring.NewMongoDBStore(signingKeyCollection), conf.KeyConfig.Algorithm, conf.KeyConfig.ValidDuration, conf.KeyConfig.GracePeriod)
+		if err != nil {
+			log.Fatalf("Error creating the signing keys : %v", err.Error())
+		}
+		go keys.Start(quit)
+		defer keys.Stop()
+		signer = keys
+	}
 
 	// create services
-	listSrv := list.NewService(listRepository, h)
-	userSrv := user.NewService(userRepository, conf)
+	listSrv := list.NewService(listRepository, filterRepository, attachmentStorage, h)
+	hasher := user.NewArgon2idHasher(&user.Argon2idParams{
+		Memory:      conf.Passwords.Memory,
+		Iterations:  conf.Passwords.Iterations,
+		Parallelism: conf.Passwords.Parallelism,
+		SaltLength:  conf.Passwords.SaltLength,
+		KeyLength:   conf.Passwords.KeyLength,
+	})
+	userSrv := user.NewService(userRepository, sessionRepository, roleRepository, accessTokenRepository, hasher, signer, conf.Tokens.AccessDuration, conf.Tokens.RefreshDuration)
+	if err := userSrv.EnsureRoles(ctx, user.DefaultRoles()...); err != nil {
+		log.Fatalf("Error creating the default roles : %v", err.Error())
+	}
+	resetSrv := user.NewResetService(userSrv, resetTokenRepository, loginAttemptsRepository, notifier, &user.ResetPolicy{
+		AccountThreshold: conf.PasswordReset.AccountThreshold,
+		IPThreshold:      conf.PasswordReset.IPThreshold,
+		Window:           conf.PasswordReset.Window,
+	}, conf.PasswordReset.Validity, conf.PasswordReset.URL)
+	lockoutSrv := user.NewLockoutService(loginAttemptsRepository, securityEventRepository, &user.LockoutPolicy{
+		AccountThreshold: conf.Lockout.AccountThreshold,
+		IPThreshold:      conf.Lockout.IPThreshold,
+		BaseDelay:        conf.Lockout.BaseDelay,
+		MaxDelay:         conf.Lockout.MaxDelay,
+		ResetAfter:       conf.Lockout.ResetAfter,
+	})
+	profileSrv := user.NewProfileService(userRepository, attachmentStorage)
+	catalogSrv := catalog.NewService(catalogRepository)
+	storeSrv := store.NewService(storeRepository)
+	inviteSrv := invite.NewService(inviteRepository, userSrv, conf.Registration.Enabled, conf.Registration.MaxOpenInvites)
+
+	// the login through an identity provider is optional
+	var oidcSrv oidc.Service
+	if conf.OIDC.Enabled {
+		provider, err := oidc.Discover(ctx, &http.Client{Timeout: 10 * time.Second}, conf.OIDC.Issuer)
+		if err != nil {
+			log.Fatalf("Error discovering the identity provider : %v", err.Error())
+		}
+		oidcSrv = oidc.NewService(oidcRepository, userSrv, provider, &oidc.Client{
+			ID:          conf.OIDC.ClientID,
+			Secret:      conf.OIDC.ClientSecret,
+			RedirectURL: conf.OIDC.RedirectURL,
+			Scopes:      conf.OIDC.Scopes,
+		}, conf.OIDC.AutoProvision)
+	}
 
 	// setup routes
-	r := api.SetupRoutes(userSrv, listSrv, h)
+	accountSrv := account.NewService(userSrv, profileSrv, listSrv, inviteSrv, oidcRepository, h)
+	auditSrv := audit.NewService(auditRepository)
+
+	r := api.SetupRoutes(userSrv, resetSrv, lockoutSrv, profileSrv, accountSrv, auditSrv, listSrv, catalogSrv, storeSrv, inviteSrv, oidcSrv, signer, h, presence, &attachment.Limits{
+		MaxSize:      conf.Attachments.MaxSize,
+		AllowedTypes: conf.Attachments.AllowedTypes,
+	}, conf.Server.MaxBodySize)
+	if err := r.SetTrustedProxies(conf.Server.TrustedProxies); err != nil {
+		log.Fatalf("Error configuring the trusted proxies : %v", err.Error())
+	}
 	r.StaticFile("/", "./public/index.html")
 
 	// setup server
@@ -0,0 +1,35 @@
+package account
+
+import (
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/invite"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/oidc"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+)
+
+// Export gathers all the data held about a user, so that it can be handed over to them
+type Export struct {
+	ExportedAt   time.Time            `json:"exported_at"`
+	User         *user.View           `json:"user"`
+	Lists        []*list.Shoppinglist `json:"lists"`
+	AccessTokens []*user.AccessToken  `json:"access_tokens"`
+	Invites      []*invite.Invite     `json:"invites"`
+	Identities   []*oidc.Identity     `json:"identities"`
+}
+
+// DeletionReport tells what happened to the data of a deleted user
+type DeletionReport struct {
+	// TransferredLists were given to the new owner
+	TransferredLists []string `json:"transferred_lists"`
+	// GrantedPermissions were given to the new owner on the transferred lists they could not already read and write
+	GrantedPermissions []*user.Permission `json:"granted_permissions"`
+	// DeletedLists were only shared with the deleted user
+	DeletedLists []string `json:"deleted_lists"`
+	// ReleasedLists are shared with other users, who keep them without an owner
+	ReleasedLists       []string `json:"released_lists"`
+	RevokedAccessTokens int64    `json:"revoked_access_tokens"`
+	DeletedInvites      int64    `json:"deleted_invites"`
+	DeletedIdentities   int64    `json:"deleted_identities"`
+}
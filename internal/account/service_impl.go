@@ -0,0 +1,267 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/oidc"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/NicolasDutronc/shoppinglist-be/pkg/hub"
+)
+
+var (
+	// ErrTransferToSelf is returned when the lists of a deleted user would be transferred to that same user
+	ErrTransferToSelf = common.NewError(common.ErrValidation, "the lists cannot be transferred to the deleted user")
+	// ErrUnknownNewOwner is returned when the user the lists are transferred to does not exist
+	ErrUnknownNewOwner = common.NewError(common.ErrValidation, "the new owner of the lists does not exist")
+)
+
+// listActions are the permissions the new owner of a list is granted on it
+var listActions = []string{"read", "write"}
+
+// ServiceImpl is the concrete implementation of the account service interface
+type ServiceImpl struct {
+	users      Users
+	profiles   Profiles
+	lists      Lists
+	invites    Invites
+	identities oidc.UserIdentities
+	h          hub.Hub
+}
+
+// NewService inits a new account service. The hub is used to disconnect the deleted users from the live updates
+func NewService(users Users, profiles Profiles, lists Lists, invites Invites, identities oidc.UserIdentities, h hub.Hub) Service {
+	return &ServiceImpl{
+		users:      users,
+		profiles:   profiles,
+		lists:      lists,
+		invites:    invites,
+		identities: identities,
+		h:          h,
+	}
+}
+
+// Export gathers the user, the lists they own, their personal access tokens, their invites and their external identities
+func (s *ServiceImpl) Export(ctx context.Context, userID string) (*Export, error) {
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	lists, err := s.lists.FindListsByOwner(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessTokens, err := s.users.FindAccessTokens(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	invites, err := s.invites.FindInvitesByCreator(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	identities, err := s.identities.FindIdentitiesByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Export{
+		ExportedAt:   time.Now(),
+		User:         u.View(user.VisibilityPrivate),
+		Lists:        lists,
+		AccessTokens: accessTokens,
+		Invites:      invites,
+		Identities:   identities,
+	}, nil
+}
+
+// Delete removes the user and everything that belongs to them. The user is disabled first so that nothing changes meanwhile.
+// The lists they own are transferred to the user given by transferTo, who is granted access to them.
+// Without transferTo, the lists only the deleted user had access to are deleted and the shared ones are kept without an owner
+func (s *ServiceImpl) Delete(ctx context.Context, userID string, transferTo string) (*DeletionReport, error) {
+	deleted, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var newOwner *user.User
+	if transferTo != "" {
+		if transferTo == userID {
+			return nil, ErrTransferToSelf
+		}
+		if newOwner, err = s.users.FindByID(ctx, transferTo); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnknownNewOwner, err)
+		}
+	}
+
+	// disabling also revokes the sessions
+	if _, err := s.users.DisableUsers(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := s.h.DisconnectIdentity(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	report := &DeletionReport{
+		TransferredLists:   []string{},
+		GrantedPermissions: []*user.Permission{},
+		DeletedLists:       []string{},
+		ReleasedLists:      []string{},
+	}
+
+	if report.RevokedAccessTokens, err = s.revokeAccessTokens(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.handOverLists(ctx, deleted, newOwner, report); err != nil {
+		return nil, err
+	}
+
+	invites, err := s.invites.FindInvitesByCreator(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range invites {
+		n, err := s.invites.DeleteInvite(ctx, deleted, i.ID.Hex())
+		if err != nil {
+			return nil, err
+		}
+		report.DeletedInvites += n
+	}
+
+	if report.DeletedIdentities, err = s.identities.DeleteIdentitiesByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.profiles.RemoveAvatar(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.users.Delete(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// revokeAccessTokens revokes all the personal access tokens of the user and counts them
+func (s *ServiceImpl) revokeAccessTokens(ctx context.Context, userID string) (int64, error) {
+	accessTokens, err := s.users.FindAccessTokens(ctx, userID)
+	if err != nil {
+		return -1, err
+	}
+
+	var revoked int64
+	for _, accessToken := range accessTokens {
+		n, err := s.users.RevokeAccessToken(ctx, userID, accessToken.ID.Hex())
+		if err != nil {
+			return -1, err
+		}
+		revoked += n
+	}
+
+	return revoked, nil
+}
+
+// handOverLists transfers the lists of the deleted user to the new owner or, without new owner, deletes or releases them
+func (s *ServiceImpl) handOverLists(ctx context.Context, deleted *user.User, newOwner *user.User, report *DeletionReport) error {
+	lists, err := s.lists.FindListsByOwner(ctx, deleted.ID.Hex())
+	if err != nil {
+		return err
+	}
+
+	grants := []*user.Permission{}
+	deletedResources := []string{}
+	for _, l := range lists {
+		listID := l.ID.Hex()
+
+		if newOwner != nil {
+			if _, err := s.lists.TransferList(ctx, listID, newOwner.ID.Hex()); err != nil {
+				return err
+			}
+			grants = append(grants, missingPermissions(newOwner, listResource(l))...)
+			report.TransferredLists = append(report.TransferredLists, listID)
+			continue
+		}
+
+		shared, err := s.isShared(ctx, deleted, l)
+		if err != nil {
+			return err
+		}
+
+		if shared {
+			if _, err := s.lists.TransferList(ctx, listID, ""); err != nil {
+				return err
+			}
+			report.ReleasedLists = append(report.ReleasedLists, listID)
+			continue
+		}
+
+		if _, err := s.lists.DeleteList(ctx, listID); err != nil {
+			return err
+		}
+		deletedResources = append(deletedResources, listResource(l))
+		report.DeletedLists = append(report.DeletedLists, listID)
+	}
+
+	if len(grants) > 0 {
+		if _, err := s.users.AddPermissions(ctx, nil, newOwner.ID.Hex(), grants...); err != nil {
+			return err
+		}
+		report.GrantedPermissions = grants
+	}
+
+	if len(deletedResources) > 0 {
+		if _, err := s.users.RemoveResourcePermissions(ctx, deletedResources...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isShared returns true if a user other than the deleted one holds a permission on the list
+func (s *ServiceImpl) isShared(ctx context.Context, deleted *user.User, l *list.Shoppinglist) (bool, error) {
+	page, err := s.users.SearchUsers(ctx, &user.UserQuery{PermissionResource: listResource(l), PageSize: 2})
+	if err != nil {
+		return false, err
+	}
+
+	for _, u := range page.Users {
+		if u.ID != deleted.ID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// listResource returns the resource id of the permissions on the list
+func listResource(l *list.Shoppinglist) string {
+	return "list-" + l.ID.Hex()
+}
+
+// missingPermissions returns the list permissions the user does not hold yet on the resource
+func missingPermissions(u *user.User, resourceID string) []*user.Permission {
+	missing := []*user.Permission{}
+	for _, action := range listActions {
+		held := false
+		for _, p := range u.Permissions {
+			if p.Action == action && p.ResourceID == resourceID {
+				held = true
+				break
+			}
+		}
+		if !held {
+			missing = append(missing, &user.Permission{Action: action, ResourceID: resourceID})
+		}
+	}
+
+	return missing
+}
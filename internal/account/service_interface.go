@@ -0,0 +1,46 @@
+package account
+
+import (
+	"context"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/invite"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+)
+
+// Users is the part of the user service used to export and delete the users along with their credentials
+type Users interface {
+	FindByID(ctx context.Context, userID string) (*user.User, error)
+	SearchUsers(ctx context.Context, query *user.UserQuery) (*user.UserPage, error)
+	AddPermissions(ctx context.Context, granter *user.User, userID string, permissions ...*user.Permission) (int64, error)
+	RemoveResourcePermissions(ctx context.Context, resourceIDs ...string) (int64, error)
+	DisableUsers(ctx context.Context, userIDs ...string) (int64, error)
+	LogoutEverywhere(ctx context.Context, userID string) (int64, error)
+	FindAccessTokens(ctx context.Context, userID string) ([]*user.AccessToken, error)
+	RevokeAccessToken(ctx context.Context, userID string, tokenID string) (int64, error)
+	Delete(ctx context.Context, userID string) (int64, error)
+}
+
+// Profiles is the part of the profile service used to delete the avatars
+type Profiles interface {
+	RemoveAvatar(ctx context.Context, userID string) (int64, error)
+}
+
+// Lists is the part of the list service used to export, transfer and delete the lists of the users
+type Lists interface {
+	FindListsByOwner(ctx context.Context, ownerID string) ([]*list.Shoppinglist, error)
+	TransferList(ctx context.Context, listID string, newOwnerID string) (int64, error)
+	DeleteList(ctx context.Context, listID string) (int64, error)
+}
+
+// Invites is the part of the invite service used to export and delete the invites created by the users
+type Invites interface {
+	FindInvitesByCreator(ctx context.Context, userID string) ([]*invite.Invite, error)
+	DeleteInvite(ctx context.Context, creator *user.User, inviteID string) (int64, error)
+}
+
+// Service defines the data export and the deletion of the user accounts
+type Service interface {
+	Export(ctx context.Context, userID string) (*Export, error)
+	Delete(ctx context.Context, userID string, transferTo string) (*DeletionReport, error)
+}
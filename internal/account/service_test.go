@@ -0,0 +1,127 @@
+package account_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/account"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/attachment"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/invite"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/oidc"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	mocks "github.com/NicolasDutronc/shoppinglist-be/mocks/pkg/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type fixture struct {
+	users      user.Service
+	lists      list.Service
+	invites    invite.Service
+	identities oidc.Repository
+	hub        *mocks.Hub
+	srv        account.Service
+}
+
+func newFixture(t *testing.T) *fixture {
+	normalizer, err := list.NewNormalizer()
+	assert.NoError(t, err)
+	storage, err := attachment.NewDiskStorage(t.TempDir())
+	assert.NoError(t, err)
+
+	h := &mocks.Hub{}
+	h.On("AddTopic", mock.Anything, mock.Anything).Return(nil)
+	h.On("DeleteTopic", mock.Anything, mock.Anything).Return(nil)
+	h.On("Publish", mock.Anything, mock.Anything).Return(nil)
+	h.On("DisconnectIdentity", mock.Anything, mock.Anything).Return(nil)
+
+	repo := user.NewInMemoryRepository()
+	f := &fixture{
+		users:      user.NewService(repo, user.NewInMemorySessionRepository(), user.NewInMemoryRoleRepository(), user.NewInMemoryAccessTokenRepository(), user.NewArgon2idHasher(&user.Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}), nil, time.Minute, time.Hour),
+		lists:      list.NewService(list.NewInMemoryRepository(normalizer), nil, storage, h),
+		identities: oidc.NewInMemoryRepository(),
+		hub:        h,
+	}
+	f.invites = invite.NewService(invite.NewInMemoryRepository(), f.users, true, 10)
+	f.srv = account.NewService(f.users, user.NewProfileService(repo, storage), f.lists, f.invites, f.identities, h)
+
+	return f
+}
+
+func TestDeleteTransfersLists(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+
+	alice, err := f.users.Store(ctx, "alice", "password")
+	assert.NoError(t, err)
+	bob, err := f.users.Store(ctx, "bob", "password")
+	assert.NoError(t, err)
+	groceries, err := f.lists.StoreList(ctx, "groceries", alice.ID.Hex())
+	assert.NoError(t, err)
+	_, _, err = f.users.CreateAccessToken(ctx, alice, "script", 0)
+	assert.NoError(t, err)
+	_, _, err = f.invites.CreateInvite(ctx, alice, 1, time.Hour)
+	assert.NoError(t, err)
+	assert.NoError(t, f.identities.StoreIdentity(ctx, oidc.NewIdentity("https://idp.example", "alice", alice.ID.Hex())))
+
+	export, err := f.srv.Export(ctx, alice.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", export.User.Name)
+	assert.Len(t, export.Lists, 1)
+	assert.Len(t, export.AccessTokens, 1)
+	assert.Len(t, export.Invites, 1)
+	assert.Len(t, export.Identities, 1)
+
+	_, err = f.srv.Delete(ctx, alice.ID.Hex(), alice.ID.Hex())
+	assert.ErrorIs(t, err, account.ErrTransferToSelf)
+
+	report, err := f.srv.Delete(ctx, alice.ID.Hex(), bob.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{groceries.ID.Hex()}, report.TransferredLists)
+	assert.Equal(t, int64(1), report.RevokedAccessTokens)
+	assert.Equal(t, int64(1), report.DeletedInvites)
+	assert.Equal(t, int64(1), report.DeletedIdentities)
+	f.hub.AssertCalled(t, "DisconnectIdentity", ctx, alice.ID.Hex())
+
+	_, err = f.users.FindByID(ctx, alice.ID.Hex())
+	assert.Error(t, err)
+
+	transferred, err := f.lists.FindListByID(ctx, groceries.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, bob.ID.Hex(), transferred.OwnerID)
+	bob, err = f.users.FindByID(ctx, bob.ID.Hex())
+	assert.NoError(t, err)
+	assert.NoError(t, bob.Can("write", "list-"+groceries.ID.Hex()))
+}
+
+func TestDeleteWithoutNewOwner(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+
+	alice, err := f.users.Store(ctx, "alice", "password")
+	assert.NoError(t, err)
+	bob, err := f.users.Store(ctx, "bob", "password")
+	assert.NoError(t, err)
+	private, err := f.lists.StoreList(ctx, "private", alice.ID.Hex())
+	assert.NoError(t, err)
+	shared, err := f.lists.StoreList(ctx, "shared", alice.ID.Hex())
+	assert.NoError(t, err)
+	_, err = f.users.AddPermissions(ctx, nil, alice.ID.Hex(), &user.Permission{Action: "write", ResourceID: "list-" + private.ID.Hex()})
+	assert.NoError(t, err)
+	_, err = f.users.AddPermissions(ctx, nil, bob.ID.Hex(), &user.Permission{Action: "read", ResourceID: "list-" + shared.ID.Hex()})
+	assert.NoError(t, err)
+
+	report, err := f.srv.Delete(ctx, alice.ID.Hex(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{private.ID.Hex()}, report.DeletedLists)
+	assert.Equal(t, []string{shared.ID.Hex()}, report.ReleasedLists)
+	assert.Empty(t, report.TransferredLists)
+
+	_, err = f.lists.FindListByID(ctx, private.ID.Hex())
+	assert.Error(t, err)
+	released, err := f.lists.FindListByID(ctx, shared.ID.Hex())
+	assert.NoError(t, err)
+	assert.Empty(t, released.OwnerID)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/audit"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/gin-gonic/gin"
+)
+
+// FindMyAccessTokensHandler is a http handler listing the personal access tokens of the current user
+func FindMyAccessTokensHandler(srv user.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		currentUser, err := GetCurrentUser(c)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		accessTokens, err := srv.FindAccessTokens(c.Request.Context(), currentUser.ID.Hex())
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"tokens": accessTokens,
+		})
+	}
+}
+
+// CreateAccessTokenHandler is a http handler for the CreateAccessToken service. The token is only returned here.
+// The validity is optional, the token never expires without it
+func CreateAccessTokenHandler(srv user.Service) gin.HandlerFunc {
+	type request struct {
+		Name     string             `json:"name" binding:"required"`
+		Validity string             `json:"validity"`
+		Scopes   []*user.Permission `json:"scopes" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		var validity time.Duration
+		if req.Validity != "" {
+			parsed, err := time.ParseDuration(req.Validity)
+			if err != nil || parsed <= 0 {
+				abortWithError(c, http.StatusBadRequest, errors.New("validity must be a positive duration"))
+				return
+			}
+			validity = parsed
+		}
+
+		currentUser, err := GetCurrentUser(c)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		accessToken, token, err := srv.CreateAccessToken(c.Request.Context(), currentUser, req.Name, validity, req.Scopes...)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"access_token": accessToken,
+			"token":        token,
+		})
+	}
+}
+
+// RevokeAccessTokenHandler is a http handler revoking a personal access token of the current user
+func RevokeAccessTokenHandler(srv user.Service, auditSrv audit.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		currentUser, err := GetCurrentUser(c)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		n, err := srv.RevokeAccessToken(c.Request.Context(), currentUser.ID.Hex(), c.Param("id"))
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		if n > 0 {
+			recordAudit(c, auditSrv, audit.ActionAccessTokenRevoked, auditActor(c, currentUser), c.Param("id"), "")
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"number_of_deleted": n,
+		})
+	}
+}
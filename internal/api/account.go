@@ -0,0 +1,65 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/account"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/audit"
+	"github.com/gin-gonic/gin"
+)
+
+// errSelfDelete is returned when an admin tries to delete their own account
+var errSelfDelete = errors.New("users cannot delete themselves")
+
+// ExportUserHandler is a http handler sending all the data held about a user as a JSON attachment
+func ExportUserHandler(srv account.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		export, err := srv.Export(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.Header("Content-Disposition", `attachment; filename="export-`+c.Param("id")+`.json"`)
+		c.JSON(http.StatusOK, export)
+	}
+}
+
+// DeleteUserHandler is a http handler deleting a user along with their data.
+// The lists of the user are transferred to the user given by the transfer_to query parameter, if any
+func DeleteUserHandler(srv account.Service, auditSrv audit.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		currentUser, err := GetCurrentUser(c)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+		if currentUser.ID.Hex() == c.Param("id") {
+			abortWithError(c, http.StatusBadRequest, errSelfDelete)
+			return
+		}
+
+		report, err := srv.Delete(c.Request.Context(), c.Param("id"), c.Query("transfer_to"))
+		if errors.Is(err, account.ErrTransferToSelf) || errors.Is(err, account.ErrUnknownNewOwner) {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		details := fmt.Sprintf("%d lists transferred, %d deleted, %d released", len(report.TransferredLists), len(report.DeletedLists), len(report.ReleasedLists))
+		recordAudit(c, auditSrv, audit.ActionUserDeleted, auditActor(c, currentUser), c.Param("id"), details)
+		if len(report.GrantedPermissions) > 0 {
+			recordAudit(c, auditSrv, audit.ActionPermissionsGranted, auditActor(c, currentUser), c.Query("transfer_to"), describePermissions(report.GrantedPermissions))
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"number_of_deleted": 1,
+			"report":            report,
+		})
+	}
+}
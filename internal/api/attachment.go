@@ -0,0 +1,144 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/attachment"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is the room left for the multipart envelope and the text fields when limiting the request body
+const multipartOverhead = 1 << 20
+
+// UploadAttachmentHandler returns a handler attaching an uploaded file to an item.
+// The request is a multipart form with the item name and quantity and the file.
+// The content type is sniffed from the content rather than trusted from the client
+func UploadAttachmentHandler(srv list.AttachmentService, limits *attachment.Limits) gin.HandlerFunc {
+	type request struct {
+		Name     string `form:"name"`
+		Quantity string `form:"quantity"`
+	}
+
+	return func(c *gin.Context) {
+		listID := c.Param("id")
+
+		if limits.MaxSize > 0 {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limits.MaxSize+multipartOverhead)
+		}
+
+		var req request
+		if err := c.ShouldBind(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		file, header, contentType, ok := openUpload(c, limits)
+		if !ok {
+			return
+		}
+		defer file.Close()
+
+		stored, err := srv.AddAttachment(c.Request.Context(), listID, req.Name, req.Quantity, header.Filename, contentType, file)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"attachment": stored,
+		})
+	}
+}
+
+// openUpload opens the file of the multipart form and checks it against the limits. Its content type is sniffed from the content
+// rather than trusted from the client. The request is aborted and false is returned when the file is missing or not within the limits
+func openUpload(c *gin.Context, limits *attachment.Limits) (multipart.File, *multipart.FileHeader, string, bool) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return nil, nil, "", false
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return nil, nil, "", false
+	}
+
+	sniffed := make([]byte, 512)
+	n, err := io.ReadFull(file, sniffed)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		file.Close()
+		abortWithError(c, http.StatusBadRequest, err)
+		return nil, nil, "", false
+	}
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(sniffed[:n]))
+	if err != nil {
+		file.Close()
+		abortWithError(c, http.StatusBadRequest, err)
+		return nil, nil, "", false
+	}
+
+	if err := limits.Check(header.Size, contentType); err != nil {
+		file.Close()
+		switch {
+		case errors.Is(err, attachment.ErrTooLarge):
+			abortWithError(c, http.StatusRequestEntityTooLarge, err)
+		default:
+			abortWithError(c, http.StatusUnsupportedMediaType, err)
+		}
+		return nil, nil, "", false
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		abortWithError(c, http.StatusInternalServerError, err)
+		return nil, nil, "", false
+	}
+
+	return file, header, contentType, true
+}
+
+// DownloadAttachmentHandler returns a handler sending the content of an attachment
+func DownloadAttachmentHandler(srv list.AttachmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		listID := c.Param("id")
+		attachmentID := c.Param("attachmentId")
+
+		stored, content, err := srv.OpenAttachment(c.Request.Context(), listID, attachmentID)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+		defer content.Close()
+
+		c.DataFromReader(http.StatusOK, stored.Size, stored.ContentType, content, map[string]string{
+			"Content-Disposition":    mime.FormatMediaType("inline", map[string]string{"filename": stored.Filename}),
+			"X-Content-Type-Options": "nosniff",
+		})
+	}
+}
+
+// DeleteAttachmentHandler returns a handler detaching and deleting an attachment
+func DeleteAttachmentHandler(srv list.AttachmentService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		listID := c.Param("id")
+		attachmentID := c.Param("attachmentId")
+
+		n, err := srv.RemoveAttachment(c.Request.Context(), listID, attachmentID)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, fmt.Errorf("cannot remove attachment : %w", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"number_of_deleted": n,
+		})
+	}
+}
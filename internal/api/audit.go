@@ -0,0 +1,131 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/audit"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/gin-gonic/gin"
+)
+
+// FindAuditEventsHandler is a http handler listing a page of the audit trail, most recent first.
+// The query parameters action, actor, target, since and until narrow the selection
+func FindAuditEventsHandler(srv audit.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query, err := auditQuery(c)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		if query.Page, err = strconv.ParseInt(c.DefaultQuery("page", "1"), 10, 64); err != nil {
+			abortWithError(c, http.StatusBadRequest, fmt.Errorf("invalid page %v", c.Query("page")))
+			return
+		}
+		if query.PageSize, err = strconv.ParseInt(c.DefaultQuery("page_size", "0"), 10, 64); err != nil {
+			abortWithError(c, http.StatusBadRequest, fmt.Errorf("invalid page size %v", c.Query("page_size")))
+			return
+		}
+
+		page, err := srv.FindEvents(c.Request.Context(), query)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+// ExportAuditEventsHandler is a http handler sending the audit trail as a JSON Lines attachment, oldest first.
+// It takes the same filters as FindAuditEventsHandler, without the pagination
+func ExportAuditEventsHandler(srv audit.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query, err := auditQuery(c)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", `attachment; filename="audit.jsonl"`)
+		c.Status(http.StatusOK)
+
+		// the error is rendered if the export fails before the first line, otherwise the status is already sent and it is only logged
+		if err := srv.Export(c.Request.Context(), query, c.Writer); err != nil {
+			c.Error(err)
+			c.Abort()
+		}
+	}
+}
+
+// auditQuery reads the filters of the audit trail from the query parameters. The times are in RFC 3339 format
+func auditQuery(c *gin.Context) (*audit.Query, error) {
+	query := &audit.Query{
+		Action:   c.Query("action"),
+		ActorID:  c.Query("actor"),
+		TargetID: c.Query("target"),
+	}
+
+	var err error
+	if since := c.Query("since"); since != "" {
+		if query.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			return nil, fmt.Errorf("invalid since %v", since)
+		}
+	}
+	if until := c.Query("until"); until != "" {
+		if query.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			return nil, fmt.Errorf("invalid until %v", until)
+		}
+	}
+
+	return query, nil
+}
+
+// recordAudit appends an event to the audit trail. The action is already done, so a failure is only logged rather than failing the request
+func recordAudit(c *gin.Context, srv audit.Service, action string, actor *audit.Actor, targetID string, details string) {
+	if err := srv.Record(c.Request.Context(), audit.NewEvent(action, actor, targetID, details)); err != nil {
+		log.Printf("cannot record the %v audit event : %v", action, err)
+	}
+}
+
+// auditActor returns the actor of the request, who is the given user or, without one, the current user if the request was authenticated
+func auditActor(c *gin.Context, u *user.User) *audit.Actor {
+	actor := &audit.Actor{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+
+	if u == nil {
+		u, _ = GetCurrentUser(c)
+	}
+	if u != nil {
+		actor.UserID = u.ID.Hex()
+		actor.Name = u.Name
+	}
+
+	return actor
+}
+
+// anonymousActor returns the actor of a request refused before the user could be identified, named after the user they claimed to be
+func anonymousActor(c *gin.Context, name string) *audit.Actor {
+	actor := auditActor(c, nil)
+	actor.Name = name
+
+	return actor
+}
+
+// describePermissions summarizes permissions for the details of an audit event, like "write on users, read on list-*"
+func describePermissions(permissions []*user.Permission) string {
+	descriptions := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		descriptions = append(descriptions, fmt.Sprintf("%s on %s", p.Action, p.ResourceID))
+	}
+
+	return strings.Join(descriptions, ", ")
+}
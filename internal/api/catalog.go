@@ -0,0 +1,79 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/catalog"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
+	"github.com/gin-gonic/gin"
+)
+
+// FindProductHandler returns the product identified by the barcode passed in params
+func FindProductHandler(srv catalog.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		product, err := srv.Lookup(c.Request.Context(), c.Param("barcode"))
+		if err != nil {
+			if errors.Is(err, catalog.ErrInvalidBarcode) {
+				abortWithError(c, http.StatusBadRequest, err)
+				return
+			}
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"product": product,
+		})
+	}
+}
+
+// ScanHandler returns a handler resolving a scanned barcode to a product and adding it to the list.
+// The quantity of the request overrides the default quantity of the product
+func ScanHandler(catalogSrv catalog.Service, listSrv list.ItemAdder) gin.HandlerFunc {
+	type request struct {
+		Barcode  string `json:"barcode"`
+		Quantity string `json:"quantity"`
+	}
+
+	return func(c *gin.Context) {
+		listID := c.Param("id")
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		product, err := catalogSrv.Lookup(c.Request.Context(), req.Barcode)
+		if err != nil {
+			if errors.Is(err, catalog.ErrInvalidBarcode) {
+				abortWithError(c, http.StatusBadRequest, err)
+				return
+			}
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		quantity := req.Quantity
+		if quantity == "" {
+			quantity = product.DefaultQuantity
+		}
+
+		item, merged, err := listSrv.AddItem(c.Request.Context(), listID, product.Name, quantity)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		response := gin.H{
+			"product": product,
+			"item":    item,
+			"merged":  merged,
+		}
+		if merged {
+			response["merged_into"] = item
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/gin-gonic/gin"
+)
+
+// errSelfDisable is returned when an admin tries to disable their own account
+var errSelfDisable = errors.New("users cannot disable themselves")
+
+// SearchUsersHandler is a http handler listing a page of users, sorted by name.
+// The query parameters name, role, permission_action, permission_resource and disabled narrow the selection
+func SearchUsersHandler(srv user.Service) gin.HandlerFunc {
+	type response struct {
+		Users    []*user.View `json:"users"`
+		Total    int64        `json:"total"`
+		Page     int64        `json:"page"`
+		PageSize int64        `json:"page_size"`
+	}
+
+	return func(c *gin.Context) {
+		query := &user.UserQuery{
+			NamePrefix:         c.Query("name"),
+			Role:               c.Query("role"),
+			PermissionAction:   c.Query("permission_action"),
+			PermissionResource: c.Query("permission_resource"),
+		}
+
+		var err error
+		if query.Page, err = strconv.ParseInt(c.DefaultQuery("page", "1"), 10, 64); err != nil {
+			abortWithError(c, http.StatusBadRequest, fmt.Errorf("invalid page %v", c.Query("page")))
+			return
+		}
+		if query.PageSize, err = strconv.ParseInt(c.DefaultQuery("page_size", "0"), 10, 64); err != nil {
+			abortWithError(c, http.StatusBadRequest, fmt.Errorf("invalid page size %v", c.Query("page_size")))
+			return
+		}
+		if disabled, ok := c.GetQuery("disabled"); ok {
+			value, err := strconv.ParseBool(disabled)
+			if err != nil {
+				abortWithError(c, http.StatusBadRequest, fmt.Errorf("invalid disabled filter %v", disabled))
+				return
+			}
+			query.Disabled = &value
+		}
+
+		page, err := srv.SearchUsers(c.Request.Context(), query)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		views := make([]*user.View, 0, len(page.Users))
+		for _, u := range page.Users {
+			views = append(views, UserView(c, u))
+		}
+
+		c.JSON(http.StatusOK, &response{
+			Users:    views,
+			Total:    page.Total,
+			Page:     page.Page,
+			PageSize: page.PageSize,
+		})
+	}
+}
+
+// bulkRequest is the body of the bulk operations on users
+type bulkRequest struct {
+	IDs []string `json:"ids" binding:"required,min=1,max=100"`
+}
+
+// DisableUsersHandler is a http handler disabling users and revoking their sessions. Admins cannot disable themselves
+func DisableUsersHandler(srv user.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req bulkRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		currentUser, err := GetCurrentUser(c)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+		for _, id := range req.IDs {
+			if id == currentUser.ID.Hex() {
+				abortWithError(c, http.StatusBadRequest, errSelfDisable)
+				return
+			}
+		}
+
+		n, err := srv.DisableUsers(c.Request.Context(), req.IDs...)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"number_of_updated": n,
+		})
+	}
+}
+
+// EnableUsersHandler is a http handler enabling disabled users
+func EnableUsersHandler(srv user.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req bulkRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		n, err := srv.EnableUsers(c.Request.Context(), req.IDs...)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"number_of_updated": n,
+		})
+	}
+}
+
+// ForcePasswordResetHandler is a http handler requiring users to reset their password.
+// The users without an email address cannot be sent a reset token and are listed in the response
+func ForcePasswordResetHandler(srv user.ResetService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req bulkRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		n, unreachable, err := srv.ForcePasswordReset(c.Request.Context(), req.IDs...)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"number_of_updated": n,
+			"not_notified":      unreachable,
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	// problemContentType is the media type of the error responses defined by RFC 7807
+	problemContentType = "application/problem+json"
+	// unauthorizedDetail is the detail of every unauthorized response. The actual error would tell an unknown user from a wrong password
+	unauthorizedDetail = "the credentials are invalid"
+)
+
+var (
+	// errRouteNotFound is returned for the requests that match no route
+	errRouteNotFound = common.NewError(common.ErrNotFound, "no such route")
+	// errMethodNotAllowed is returned for the requests that match a route but not its methods
+	errMethodNotAllowed = errors.New("method not allowed on this route")
+)
+
+// kindStatuses are the statuses of the kinds of domain errors
+var kindStatuses = map[error]int{
+	common.ErrNotFound:   http.StatusNotFound,
+	common.ErrConflict:   http.StatusConflict,
+	common.ErrValidation: http.StatusBadRequest,
+	common.ErrForbidden:  http.StatusForbidden,
+}
+
+// Problem is the body of the error responses, as defined by RFC 7807
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// InvalidParams lists the fields of the request body that failed the validation
+	InvalidParams []*InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// InvalidParam is a field of the request body that failed the validation
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// abortWithError aborts the request with an error that ErrorMiddleware renders.
+// Unlike gin's AbortWithError, it does not write the status yet, so that the middleware can still write the headers
+func abortWithError(c *gin.Context, status int, err error) {
+	c.Error(err)
+	c.Status(status)
+	c.Abort()
+}
+
+// ErrorMiddleware renders the last error of the request as a problem, unless a response was already written.
+// The status given by the handler wins, except a generic internal server error that the kind of the error, if any, refines.
+// The details of the server errors are kept in the logs and never sent to the client, and the unauthorized responses all look alike
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		status := c.Writer.Status()
+		if status == http.StatusInternalServerError || status < http.StatusBadRequest {
+			status = statusOf(err)
+		}
+
+		problem := &Problem{
+			Type:     "about:blank",
+			Title:    http.StatusText(status),
+			Status:   status,
+			Instance: c.Request.URL.Path,
+		}
+		switch {
+		case status == http.StatusUnauthorized:
+			problem.Detail = unauthorizedDetail
+		case status < http.StatusInternalServerError:
+			problem.Detail = err.Error()
+			problem.InvalidParams = invalidParams(err)
+		}
+
+		c.Header("Content-Type", problemContentType)
+		c.JSON(status, problem)
+	}
+}
+
+// NoRouteHandler is a http handler rendering the requests that match no route as problems
+func NoRouteHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		abortWithError(c, http.StatusNotFound, errRouteNotFound)
+	}
+}
+
+// NoMethodHandler is a http handler rendering the requests that match a route but not its methods as problems
+func NoMethodHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		abortWithError(c, http.StatusMethodNotAllowed, errMethodNotAllowed)
+	}
+}
+
+// statusOf returns the status of the kind of the error, or internal server error if it has none
+func statusOf(err error) int {
+	if status, ok := kindStatuses[common.KindOf(err)]; ok {
+		return status
+	}
+
+	return http.StatusInternalServerError
+}
+
+// invalidParams lists the fields rejected by the schema or the binding validation, if the error comes from one of them
+func invalidParams(err error) []*InvalidParam {
+	if params := schemaParams(err); params != nil {
+		return params
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	params := make([]*InvalidParam, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		reason := fmt.Sprintf("failed on the %s rule", fieldErr.Tag())
+		if fieldErr.Param() != "" {
+			reason = fmt.Sprintf("failed on the %s=%s rule", fieldErr.Tag(), fieldErr.Param())
+		}
+		params = append(params, &InvalidParam{
+			Name:   fieldErr.Field(),
+			Reason: reason,
+		})
+	}
+
+	return params
+}
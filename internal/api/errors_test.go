@@ -0,0 +1,135 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/api"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// errorRouter serves routes failing like the handlers do, behind the error middleware
+func errorRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.HandleMethodNotAllowed = true
+	r.Use(api.ErrorMiddleware())
+	r.NoRoute(api.NoRouteHandler())
+	r.NoMethod(api.NoMethodHandler())
+
+	fail := func(status int, err error) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Error(err)
+			c.Status(status)
+			c.Abort()
+		}
+	}
+	r.GET("/missing", fail(http.StatusInternalServerError, fmt.Errorf("cannot load: %w", common.NewError(common.ErrNotFound, "list %v not found", "abc"))))
+	r.GET("/broken", fail(http.StatusInternalServerError, errors.New("connection refused by 10.0.0.3")))
+	r.GET("/login", fail(http.StatusUnauthorized, common.NewError(common.ErrNotFound, "user alice not found")))
+	r.GET("/denied", func(c *gin.Context) {
+		c.Set("currentUser", user.NewUser("alice", ""))
+	}, api.Authorize(api.Permission("write", "users")), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	r.POST("/users", func(c *gin.Context) {
+		var req struct {
+			Name string `json:"name" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			fail(http.StatusBadRequest, err)(c)
+		}
+	})
+
+	return r
+}
+
+// problemOf serves the request and decodes the problem in the response
+func problemOf(t *testing.T, r *gin.Engine, req *http.Request) *api.Problem {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	var problem api.Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, w.Code, problem.Status)
+
+	return &problem
+}
+
+func TestErrorMiddleware(t *testing.T) {
+	r := errorRouter()
+
+	// the kind of the error refines a generic internal server error
+	problem := problemOf(t, r, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+	assert.Equal(t, "Not Found", problem.Title)
+	assert.Equal(t, "cannot load: list abc not found", problem.Detail)
+	assert.Equal(t, "/missing", problem.Instance)
+
+	// server errors do not leak their details
+	problem = problemOf(t, r, httptest.NewRequest(http.MethodGet, "/broken", nil))
+	assert.Equal(t, http.StatusInternalServerError, problem.Status)
+	assert.Empty(t, problem.Detail)
+
+	// an explicit status wins over the kind
+	problem = problemOf(t, r, httptest.NewRequest(http.MethodGet, "/login", nil))
+	assert.Equal(t, http.StatusUnauthorized, problem.Status)
+	assert.NotContains(t, problem.Detail, "alice")
+
+	problem = problemOf(t, r, httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, []*api.InvalidParam{{Name: "Name", Reason: "failed on the required rule"}}, problem.InvalidParams)
+
+	problem = problemOf(t, r, httptest.NewRequest(http.MethodGet, "/denied", nil))
+	assert.Equal(t, http.StatusForbidden, problem.Status)
+
+	problem = problemOf(t, r, httptest.NewRequest(http.MethodGet, "/nowhere", nil))
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+	problem = problemOf(t, r, httptest.NewRequest(http.MethodDelete, "/users", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, problem.Status)
+}
+
+// failingDeleter fails to delete like a storage would
+type failingDeleter struct {
+	err error
+}
+
+func (d *failingDeleter) DeleteList(ctx context.Context, id string) (int64, error) {
+	return -1, d.err
+}
+
+func (d *failingDeleter) DeleteFilter(ctx context.Context, id string) (int64, error) {
+	return -1, d.err
+}
+
+func TestHandlersLetTheKindSetTheStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	broken := &failingDeleter{err: errors.New("server selection error: context deadline exceeded, current topology: 10.0.0.3")}
+	missing := &failingDeleter{err: common.NewError(common.ErrNotFound, "list %v not found", "abc")}
+
+	r := gin.New()
+	r.Use(api.ErrorMiddleware())
+	r.DELETE("/broken/lists/:id", api.DeleteListHandler(broken))
+	r.DELETE("/broken/filters/:id", api.DeleteFilterHandler(broken))
+	r.DELETE("/missing/lists/:id", api.DeleteListHandler(missing))
+
+	for _, path := range []string{"/broken/lists/abc", "/broken/filters/abc"} {
+		problem := problemOf(t, r, httptest.NewRequest(http.MethodDelete, path, nil))
+		assert.Equal(t, http.StatusInternalServerError, problem.Status, path)
+		assert.Empty(t, problem.Detail, path)
+	}
+
+	problem := problemOf(t, r, httptest.NewRequest(http.MethodDelete, "/missing/lists/abc", nil))
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+	assert.Equal(t, "list abc not found", problem.Detail)
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/audit"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/gin-gonic/gin"
+)
+
+type encodedFilter struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Name      string    `json:"name"`
+	Tags      []string  `json:"tags"`
+	Done      *bool     `json:"done,omitempty"`
+	Topic     string    `json:"topic"`
+}
+
+func encodeFilter(filter *list.Filter) *encodedFilter {
+	return &encodedFilter{
+		ID:        filter.ID.Hex(),
+		CreatedAt: filter.CreatedAt,
+		UpdatedAt: filter.UpdatedAt,
+		Name:      filter.Name,
+		Tags:      filter.Tags,
+		Done:      filter.Done,
+		Topic:     string(list.FilterTopic(filter.ID.Hex())),
+	}
+}
+
+// FindAllFiltersHandler returns all saved filters
+func FindAllFiltersHandler(srv list.FilterFinder) gin.HandlerFunc {
+	type response struct {
+		Filters []*encodedFilter `json:"filters"`
+	}
+
+	return func(c *gin.Context) {
+		filters, err := srv.FindAllFilters(c.Request.Context())
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		response := &response{
+			Filters: []*encodedFilter{},
+		}
+		for _, filter := range filters {
+			response.Filters = append(response.Filters, encodeFilter(filter))
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// StoreFilterHandler saves a new filter and returns it. Its creator is given the permission to write it, which lets them delete it, and the grant is audited
+func StoreFilterHandler(srv list.FilterCreator, userSrv user.Service, auditSrv audit.Service) gin.HandlerFunc {
+	type request struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+		Done *bool    `json:"done"`
+	}
+
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		currentUser, err := GetCurrentUser(c)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		filter, err := srv.StoreFilter(c.Request.Context(), req.Name, req.Tags, req.Done)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		granted := &user.Permission{ResourceID: "filter-" + filter.ID.Hex(), Action: "write"}
+		if _, err := userSrv.AddPermissions(c.Request.Context(), nil, currentUser.ID.Hex(), granted); err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+		recordAudit(c, auditSrv, audit.ActionPermissionsGranted, auditActor(c, currentUser), currentUser.ID.Hex(), describePermissions([]*user.Permission{granted}))
+
+		c.JSON(http.StatusCreated, gin.H{
+			"filter": encodeFilter(filter),
+		})
+	}
+}
+
+// EvaluateFilterHandler returns a filter along with the items it matches in the lists the current user can read
+func EvaluateFilterHandler(srv list.Service) gin.HandlerFunc {
+	type response struct {
+		Filter *encodedFilter       `json:"filter"`
+		Items  []*list.FilteredItem `json:"items"`
+	}
+
+	return func(c *gin.Context) {
+		filterID := c.Param("id")
+		filter, err := srv.FindFilterByID(c.Request.Context(), filterID)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		items, err := srv.EvaluateFilter(c.Request.Context(), filterID)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		// get current user
+		currentUser, err := GetCurrentUser(c)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		response := &response{
+			Filter: encodeFilter(filter),
+			Items:  []*list.FilteredItem{},
+		}
+
+		for _, item := range items {
+			// if the user has the permission to read the list, the item is appended
+			if err := currentUser.Can("read", "list-"+item.ListID); err == nil {
+				response.Items = append(response.Items, item)
+			}
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// DeleteFilterHandler removes a filter based on its id
+func DeleteFilterHandler(srv list.FilterDeleter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		n, err := srv.DeleteFilter(c.Request.Context(), id)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"number_of_deleted": n,
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/NicolasDutronc/autokey"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/api"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/attachment"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/audit"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	mocks "github.com/NicolasDutronc/shoppinglist-be/mocks/pkg/hub"
+	"github.com/NicolasDutronc/shoppinglist-be/pkg/keyring"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newUserService() user.Service {
+	consumer := &autokey.MockConsumer{}
+	consumer.On("Get").Return("superSecretKey", nil)
+
+	return user.NewService(user.NewInMemoryRepository(), user.NewInMemorySessionRepository(), user.NewInMemoryRoleRepository(), user.NewInMemoryAccessTokenRepository(), user.NewArgon2idHasher(&user.Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}), keyring.NewSymmetric(consumer), time.Minute, time.Hour)
+}
+
+// asUser authenticates the requests as the user, reloaded on each request so that the permissions granted along the way apply
+func asUser(userSrv user.Service, userID string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		currentUser, err := userSrv.FindWithRoles(c.Request.Context(), userID)
+		if err != nil {
+			c.AbortWithError(http.StatusUnauthorized, err)
+			return
+		}
+		c.Set("currentUser", currentUser)
+	}
+}
+
+// failingAudit is an audit trail that cannot record anything
+type failingAudit struct {
+	audit.Service
+}
+
+func (failingAudit) Record(ctx context.Context, event *audit.Event) error {
+	return errors.New("audit trail unavailable")
+}
+
+func TestFilterCreatorCanDeleteIt(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	userSrv := newUserService()
+	alice, err := userSrv.Store(ctx, "alice", "password")
+	assert.NoError(t, err)
+	bob, err := userSrv.Store(ctx, "bob", "password")
+	assert.NoError(t, err)
+
+	storage, err := attachment.NewDiskStorage(t.TempDir())
+	assert.NoError(t, err)
+	h := &mocks.Hub{}
+	h.On("AddTopic", mock.Anything, mock.Anything).Return(nil)
+	h.On("DeleteTopic", mock.Anything, mock.Anything).Return(nil)
+	h.On("Publish", mock.Anything, mock.Anything).Return(nil)
+	listSrv := list.NewService(list.NewInMemoryRepository(nil), list.NewInMemoryFilterRepository(), storage, h)
+	auditSrv := audit.NewService(audit.NewInMemoryRepository())
+
+	router := func(userID string) *gin.Engine {
+		r := gin.New()
+		r.Use(api.ErrorMiddleware())
+		r.Use(asUser(userSrv, userID))
+		r.POST("/filters", api.StoreFilterHandler(listSrv, userSrv, auditSrv))
+		r.DELETE("/filters/:id", api.AuthorizationMiddleware("write", "filter-:id"), api.DeleteFilterHandler(listSrv))
+		return r
+	}
+
+	// alice has no role, the filter is still theirs
+	w := send(router(alice.ID.Hex()), http.MethodPost, "/filters", "application/json", `{"name": "unchecked", "done": false}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		Filter struct {
+			ID string `json:"id"`
+		} `json:"filter"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	assert.Equal(t, http.StatusForbidden, send(router(bob.ID.Hex()), http.MethodDelete, "/filters/"+created.Filter.ID, "", "").Code)
+	assert.Equal(t, http.StatusOK, send(router(alice.ID.Hex()), http.MethodDelete, "/filters/"+created.Filter.ID, "", "").Code)
+
+	// the grant to the creator is in the audit trail
+	page, err := auditSrv.FindEvents(ctx, &audit.Query{Action: audit.ActionPermissionsGranted, TargetID: alice.ID.Hex()})
+	assert.NoError(t, err)
+	if assert.Len(t, page.Events, 1) {
+		assert.Equal(t, "write on filter-"+created.Filter.ID, page.Events[0].Details)
+	}
+}
+
+func TestFilterCreatedWhenTheAuditFails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	userSrv := newUserService()
+	alice, err := userSrv.Store(ctx, "alice", "password")
+	assert.NoError(t, err)
+
+	storage, err := attachment.NewDiskStorage(t.TempDir())
+	assert.NoError(t, err)
+	h := &mocks.Hub{}
+	h.On("AddTopic", mock.Anything, mock.Anything).Return(nil)
+	h.On("Publish", mock.Anything, mock.Anything).Return(nil)
+	listSrv := list.NewService(list.NewInMemoryRepository(nil), list.NewInMemoryFilterRepository(), storage, h)
+
+	r := gin.New()
+	r.Use(api.ErrorMiddleware())
+	r.Use(asUser(userSrv, alice.ID.Hex()))
+	r.POST("/filters", api.StoreFilterHandler(listSrv, userSrv, failingAudit{}))
+
+	// the filter and the grant are stored, so the request succeeds even though the audit trail is down
+	w := send(r, http.MethodPost, "/filters", "application/json", `{"name": "unchecked", "done": false}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		Filter struct {
+			ID string `json:"id"`
+		} `json:"filter"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	reloaded, err := userSrv.FindByID(ctx, alice.ID.Hex())
+	assert.NoError(t, err)
+	assert.NoError(t, reloaded.Can("write", "filter-"+created.Filter.ID))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/NicolasDutronc/shoppinglist-be/pkg/hub"
 	"github.com/gin-gonic/gin"
 )
 
@@ -21,3 +22,27 @@ func GetCurrentUser(c *gin.Context) (*user.User, error) {
 
 	return user, nil
 }
+
+// GetCurrentIdentity returns the hub identity of the current user. The user is kept as the principal so that the scopes of a personal access token still apply
+func GetCurrentIdentity(c *gin.Context) (*hub.Identity, error) {
+	currentUser, err := GetCurrentUser(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &hub.Identity{
+		ID:        currentUser.ID.Hex(),
+		Name:      currentUser.Name,
+		Principal: currentUser,
+	}, nil
+}
+
+// UserView projects the user for the current user. Without a current user, like on login, the user is viewing itself
+func UserView(c *gin.Context, u *user.User) *user.View {
+	viewer, err := GetCurrentUser(c)
+	if err != nil {
+		viewer = u
+	}
+
+	return u.View(u.VisibilityFor(viewer))
+}
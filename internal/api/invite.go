@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/audit"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/invite"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/gin-gonic/gin"
+)
+
+// FindMyInvitesHandler is a http handler listing the invites created by the current user
+func FindMyInvitesHandler(srv invite.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		currentUser, err := GetCurrentUser(c)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		invites, err := srv.FindInvitesByCreator(c.Request.Context(), currentUser.ID.Hex())
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"invites": invites,
+		})
+	}
+}
+
+// CreateInviteHandler is a http handler for the CreateInvite service. The code is only returned here
+func CreateInviteHandler(srv invite.Service) gin.HandlerFunc {
+	type request struct {
+		MaxUses     int64              `json:"max_uses" binding:"required"`
+		Validity    string             `json:"validity" binding:"required"`
+		Permissions []*user.Permission `json:"permissions"`
+	}
+
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		validity, err := time.ParseDuration(req.Validity)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		currentUser, err := GetCurrentUser(c)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		created, code, err := srv.CreateInvite(c.Request.Context(), currentUser, req.MaxUses, validity, req.Permissions...)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"invite": created,
+			"code":   code,
+		})
+	}
+}
+
+// DeleteInviteHandler is a http handler revoking an invite of the current user
+func DeleteInviteHandler(srv invite.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		currentUser, err := GetCurrentUser(c)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		n, err := srv.DeleteInvite(c.Request.Context(), currentUser, c.Param("id"))
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"number_of_deleted": n,
+		})
+	}
+}
+
+// RegisterHandler is a http handler creating a user from an invite code. The permissions the invite gives are audited as granted to the new user
+func RegisterHandler(srv invite.Service, auditSrv audit.Service) gin.HandlerFunc {
+	type request struct {
+		Code     string `json:"code" binding:"required"`
+		Name     string `json:"name" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		registered, err := srv.Register(c.Request.Context(), req.Code, req.Name, req.Password)
+		switch {
+		case errors.Is(err, invite.ErrRegistrationDisabled):
+			abortWithError(c, http.StatusForbidden, err)
+			return
+		case errors.Is(err, invite.ErrInvalidCode):
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		case err != nil:
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		if len(registered.Permissions) > 0 {
+			recordAudit(c, auditSrv, audit.ActionPermissionsGranted, auditActor(c, registered), registered.ID.Hex(), describePermissions(registered.Permissions))
+		}
+
+		c.JSON(http.StatusCreated, UserView(c, registered))
+	}
+}
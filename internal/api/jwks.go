@@ -0,0 +1,17 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/NicolasDutronc/shoppinglist-be/pkg/keyring"
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSHandler is a http handler publishing the public keys verifying the access tokens.
+// Verifiers are expected to fetch the keys again when a token is signed by an unknown kid, so the cache is short
+func JWKSHandler(signer keyring.Signer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, signer.PublicKeys())
+	}
+}
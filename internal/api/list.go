@@ -15,6 +15,8 @@ func FindListByIDHandler(srv list.FinderByID) gin.HandlerFunc {
 		CreatedAt time.Time    `json:"created_at"`
 		UpdatedAt time.Time    `json:"updated_at"`
 		Name      string       `json:"name"`
+		OwnerID   string       `json:"owner_id"`
+		Tags      []string     `json:"tags"`
 		Items     []*list.Item `json:"items"`
 	}
 
@@ -22,7 +24,7 @@ func FindListByIDHandler(srv list.FinderByID) gin.HandlerFunc {
 		id := c.Param("id")
 		list, err := srv.FindListByID(c.Request.Context(), id)
 		if err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
@@ -32,6 +34,8 @@ func FindListByIDHandler(srv list.FinderByID) gin.HandlerFunc {
 				CreatedAt: list.CreatedAt,
 				UpdatedAt: list.UpdatedAt,
 				Name:      list.Name,
+				OwnerID:   list.OwnerID,
+				Tags:      list.Tags,
 				Items:     list.Items,
 			},
 		})
@@ -45,6 +49,8 @@ func FindAllListsHandler(srv list.Finder) gin.HandlerFunc {
 		CreatedAt time.Time    `json:"created_at"`
 		UpdatedAt time.Time    `json:"updated_at"`
 		Name      string       `json:"name"`
+		OwnerID   string       `json:"owner_id"`
+		Tags      []string     `json:"tags"`
 		Items     []*list.Item `json:"items"`
 	}
 
@@ -55,14 +61,14 @@ func FindAllListsHandler(srv list.Finder) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		lists, err := srv.FindAllLists(c.Request.Context())
 		if err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
 		// get current user
 		currentUser, err := GetCurrentUser(c)
 		if err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusInternalServerError, err)
 		}
 
 		response := &response{
@@ -77,6 +83,8 @@ func FindAllListsHandler(srv list.Finder) gin.HandlerFunc {
 					CreatedAt: list.CreatedAt,
 					UpdatedAt: list.UpdatedAt,
 					Name:      list.Name,
+					OwnerID:   list.OwnerID,
+					Tags:      list.Tags,
 					Items:     list.Items,
 				})
 			}
@@ -92,6 +100,7 @@ func GetInventoryHandler(srv list.Service) gin.HandlerFunc {
 		CreatedAt time.Time `json:"created_at"`
 		UpdatedAt time.Time `json:"updated_at"`
 		Name      string    `json:"name"`
+		Tags      []string  `json:"tags"`
 		Length    int       `json:"length"`
 	}
 
@@ -101,14 +110,14 @@ func GetInventoryHandler(srv list.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		lists, err := srv.FindAllLists(c.Request.Context())
 		if err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
 		// get current user
 		currentUser, err := GetCurrentUser(c)
 		if err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusInternalServerError, err)
 		}
 
 		response := &response{
@@ -123,6 +132,7 @@ func GetInventoryHandler(srv list.Service) gin.HandlerFunc {
 					CreatedAt: list.CreatedAt,
 					UpdatedAt: list.UpdatedAt,
 					Name:      list.Name,
+					Tags:      list.Tags,
 					Length:    len(list.Items),
 				})
 			}
@@ -138,7 +148,7 @@ func DeleteListHandler(srv list.Deleter) gin.HandlerFunc {
 		id := c.Param("id")
 		n, err := srv.DeleteList(c.Request.Context(), id)
 		if err != nil {
-			c.AbortWithError(http.StatusNotFound, err)
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
@@ -159,19 +169,27 @@ func StoreListHandler(srv list.Creator) gin.HandlerFunc {
 		CreatedAt time.Time    `json:"created_at"`
 		UpdatedAt time.Time    `json:"updated_at"`
 		Name      string       `json:"name"`
+		OwnerID   string       `json:"owner_id"`
+		Tags      []string     `json:"tags"`
 		Items     []*list.Item `json:"items"`
 	}
 
 	return func(c *gin.Context) {
 		var req request
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.AbortWithError(http.StatusBadRequest, err)
+			abortWithError(c, http.StatusBadRequest, err)
 			return
 		}
 
-		list, err := srv.StoreList(c.Request.Context(), req.Name)
+		currentUser, err := GetCurrentUser(c)
 		if err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		list, err := srv.StoreList(c.Request.Context(), req.Name, currentUser.ID.Hex())
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
@@ -181,6 +199,8 @@ func StoreListHandler(srv list.Creator) gin.HandlerFunc {
 				CreatedAt: list.CreatedAt,
 				UpdatedAt: list.UpdatedAt,
 				Name:      list.Name,
+				OwnerID:   list.OwnerID,
+				Tags:      list.Tags,
 				Items:     list.Items,
 			},
 		})
@@ -194,7 +214,7 @@ func RemoveAllItemsHandler(srv list.Clearer) gin.HandlerFunc {
 
 		n, err := srv.RemoveAllItems(c.Request.Context(), listID)
 		if err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
@@ -215,19 +235,26 @@ func AddItemHandler(srv list.ItemAdder) gin.HandlerFunc {
 		listID := c.Param("id")
 		var req request
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.AbortWithError(http.StatusBadRequest, err)
+			abortWithError(c, http.StatusBadRequest, err)
 			return
 		}
 
 		// store new item
-		item, err := srv.AddItem(c.Request.Context(), listID, req.Name, req.Quantity)
+		item, merged, err := srv.AddItem(c.Request.Context(), listID, req.Name, req.Quantity)
 		if err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{
-			"item": &item,
-		})
+		response := gin.H{
+			"item":   &item,
+			"merged": merged,
+		}
+		if merged {
+			response["merged_into"] = &item
+		}
+
+		c.JSON(http.StatusOK, response)
 	}
 }
 
@@ -243,13 +270,13 @@ func UpdateItemHandler(srv list.ItemUpdater) gin.HandlerFunc {
 		listID := c.Param("id")
 		var req request
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.AbortWithError(http.StatusBadRequest, err)
+			abortWithError(c, http.StatusBadRequest, err)
 			return
 		}
 
 		n, err := srv.UpdateItem(c.Request.Context(), listID, req.Name, req.Quantity, req.NewName, req.NewQuantity)
 		if err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
@@ -270,13 +297,13 @@ func ToggleItemHandler(srv list.ItemToggler) gin.HandlerFunc {
 		listID := c.Param("id")
 		var req request
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.AbortWithError(http.StatusBadRequest, err)
+			abortWithError(c, http.StatusBadRequest, err)
 			return
 		}
 
 		n, err := srv.ToggleItem(c.Request.Context(), listID, req.Name, req.Quantity, req.Value)
 		if err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
@@ -296,13 +323,13 @@ func RemoveItemHandler(srv list.ItemRemover) gin.HandlerFunc {
 		listID := c.Param("id")
 		var req request
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.AbortWithError(http.StatusBadRequest, err)
+			abortWithError(c, http.StatusBadRequest, err)
 			return
 		}
 
 		n, err := srv.RemoveItem(c.Request.Context(), listID, req.Name, req.Quantity)
 		if err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
@@ -311,3 +338,55 @@ func RemoveItemHandler(srv list.ItemRemover) gin.HandlerFunc {
 		})
 	}
 }
+
+// AddTagsHandler returns a handler for tagging a list
+func AddTagsHandler(srv list.Tagger) gin.HandlerFunc {
+	type request struct {
+		Tags []string `json:"tags"`
+	}
+
+	return func(c *gin.Context) {
+		listID := c.Param("id")
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		n, err := srv.AddTags(c.Request.Context(), listID, req.Tags...)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"number_of_updated": n,
+		})
+	}
+}
+
+// RemoveTagsHandler returns a handler for removing tags from a list
+func RemoveTagsHandler(srv list.Tagger) gin.HandlerFunc {
+	type request struct {
+		Tags []string `json:"tags"`
+	}
+
+	return func(c *gin.Context) {
+		listID := c.Param("id")
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		n, err := srv.RemoveTags(c.Request.Context(), listID, req.Tags...)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"number_of_updated": n,
+		})
+	}
+}
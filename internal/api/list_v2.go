@@ -0,0 +1,227 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
+	"github.com/gin-gonic/gin"
+)
+
+// listResource is the representation of a list in the v2 api. Its name and its tags can be patched, the items are resources of their own
+type listResource struct {
+	ID        string       `json:"id"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	Name      string       `json:"name"`
+	OwnerID   string       `json:"owner_id"`
+	Tags      []string     `json:"tags"`
+	Items     []*list.Item `json:"items"`
+}
+
+func newListResource(l *list.Shoppinglist) *listResource {
+	return &listResource{
+		ID:        l.ID.Hex(),
+		CreatedAt: l.CreatedAt,
+		UpdatedAt: l.UpdatedAt,
+		Name:      l.Name,
+		OwnerID:   l.OwnerID,
+		Tags:      l.Tags,
+		Items:     l.Items,
+	}
+}
+
+// FindListResourceHandler is a http handler returning a list given by its id
+func FindListResourceHandler(srv list.FinderByID) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		l, err := srv.FindListByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		resource := newListResource(l)
+		if !writeETag(c, resource) {
+			return
+		}
+		c.Header("Accept-Patch", acceptPatch)
+		c.JSON(http.StatusOK, resource)
+	}
+}
+
+// PatchListHandler is a http handler partially updating a list with a JSON Merge Patch or a JSON Patch, in a single update.
+// The tags are a set: only the tags added and removed by the patch matter, not their order.
+// With an If-Match header, the list is only patched if it did not change since the client got it
+func PatchListHandler(srv list.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		listID := c.Param("id")
+		l, err := srv.FindListByID(c.Request.Context(), listID)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		resource := newListResource(l)
+		if !checkIfMatch(c, resource) {
+			return
+		}
+
+		var patched listResource
+		if !applyPatch(c, resource, &patched, "name", "tags") {
+			return
+		}
+
+		// the tags keep their order, the removed ones are left out and the added ones come last
+		removed := missingFrom(patched.Tags, l.Tags)
+		tags := append(missingFrom(removed, l.Tags), missingFrom(l.Tags, patched.Tags)...)
+		if _, err := srv.ReplaceList(c.Request.Context(), listID, l, patched.Name, tags); err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		l, err = srv.FindListByID(c.Request.Context(), listID)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		resource = newListResource(l)
+		if !writeETag(c, resource) {
+			return
+		}
+		c.JSON(http.StatusOK, resource)
+	}
+}
+
+// FindItemsHandler is a http handler returning the items of a list
+func FindItemsHandler(srv list.FinderByID) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		l, err := srv.FindListByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"items": l.Items,
+		})
+	}
+}
+
+// CreateItemHandler is a http handler adding an item to a list. When the list already has an item of the same name,
+// nothing is created and the existing item is returned with the status OK instead of Created
+func CreateItemHandler(srv list.ItemAdder) gin.HandlerFunc {
+	type request struct {
+		Name     string `json:"name" binding:"required"`
+		Quantity string `json:"quantity"`
+	}
+
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		listID := c.Param("id")
+		item, merged, err := srv.AddItem(c.Request.Context(), listID, req.Name, req.Quantity)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		if merged {
+			c.JSON(http.StatusOK, item)
+			return
+		}
+
+		c.Header("Location", "/api/v2/lists/"+listID+"/items/"+item.ID)
+		c.JSON(http.StatusCreated, item)
+	}
+}
+
+// FindItemHandler is a http handler returning an item of a list
+func FindItemHandler(srv list.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		item, err := srv.FindItem(c.Request.Context(), c.Param("id"), c.Param("itemId"))
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		if !writeETag(c, item) {
+			return
+		}
+		c.Header("Accept-Patch", acceptPatch)
+		c.JSON(http.StatusOK, item)
+	}
+}
+
+// PatchItemHandler is a http handler partially updating an item with a JSON Merge Patch or a JSON Patch.
+// The name, the quantity and the done state can be patched in a single update, the attachments are managed by the v1 api.
+// With an If-Match header, the item is only patched if it did not change since the client got it
+func PatchItemHandler(srv list.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		listID, itemID := c.Param("id"), c.Param("itemId")
+		item, err := srv.FindItem(c.Request.Context(), listID, itemID)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		if !checkIfMatch(c, item) {
+			return
+		}
+
+		var patched list.Item
+		if !applyPatch(c, item, &patched, "name", "quantity", "done") {
+			return
+		}
+
+		if _, err := srv.ReplaceItem(c.Request.Context(), listID, item, &patched); err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		item, err = srv.FindItem(c.Request.Context(), listID, itemID)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		if !writeETag(c, item) {
+			return
+		}
+		c.JSON(http.StatusOK, item)
+	}
+}
+
+// DeleteItemHandler is a http handler removing an item from a list along with its attachments
+func DeleteItemHandler(srv list.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, err := srv.RemoveItemByID(c.Request.Context(), c.Param("id"), c.Param("itemId")); err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// missingFrom returns the values that are not in the reference, without duplicates
+func missingFrom(reference []string, values []string) []string {
+	known := map[string]bool{}
+	for _, value := range reference {
+		known[value] = true
+	}
+
+	missing := []string{}
+	for _, value := range values {
+		if !known[value] {
+			missing = append(missing, value)
+			known[value] = true
+		}
+	}
+
+	return missing
+}
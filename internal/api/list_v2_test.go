@@ -0,0 +1,211 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/api"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/attachment"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
+	mocks "github.com/NicolasDutronc/shoppinglist-be/mocks/pkg/hub"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// listV2Router serves the v2 list routes, without authentication, on an in memory list service holding a list with an item
+func listV2Router(t *testing.T) (*gin.Engine, *list.Shoppinglist, *list.Item) {
+	gin.SetMode(gin.TestMode)
+	spec, err := api.LoadSpec()
+	assert.NoError(t, err)
+
+	normalizer, err := list.NewNormalizer("trim", "casefold")
+	assert.NoError(t, err)
+	storage, err := attachment.NewDiskStorage(t.TempDir())
+	assert.NoError(t, err)
+	h := &mocks.Hub{}
+	h.On("AddTopic", mock.Anything, mock.Anything).Return(nil)
+	h.On("Publish", mock.Anything, mock.Anything).Return(nil)
+	srv := list.NewService(list.NewInMemoryRepository(normalizer), list.NewInMemoryFilterRepository(), storage, h)
+
+	ctx := context.Background()
+	l, err := srv.StoreList(ctx, "groceries", "alice")
+	assert.NoError(t, err)
+	_, err = srv.AddTags(ctx, l.ID.Hex(), "weekly")
+	assert.NoError(t, err)
+	item, _, err := srv.AddItem(ctx, l.ID.Hex(), "milk", "1L")
+	assert.NoError(t, err)
+
+	r := gin.New()
+	r.Use(api.ErrorMiddleware())
+	r.Use(api.ValidationMiddleware(spec, 1<<20))
+	r.GET("/api/v2/lists/:id", api.FindListResourceHandler(srv))
+	r.PATCH("/api/v2/lists/:id", api.PatchListHandler(srv))
+	r.GET("/api/v2/lists/:id/items", api.FindItemsHandler(srv))
+	r.POST("/api/v2/lists/:id/items", api.CreateItemHandler(srv))
+	r.GET("/api/v2/lists/:id/items/:itemId", api.FindItemHandler(srv))
+	r.PATCH("/api/v2/lists/:id/items/:itemId", api.PatchItemHandler(srv))
+	r.DELETE("/api/v2/lists/:id/items/:itemId", api.DeleteItemHandler(srv))
+
+	return r, l, item
+}
+
+func send(r *gin.Engine, method string, path string, contentType string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestItemResources(t *testing.T) {
+	r, l, milk := listV2Router(t)
+	items := "/api/v2/lists/" + l.ID.Hex() + "/items"
+
+	w := send(r, http.MethodPost, items, "application/json", `{"name": "eggs", "quantity": "12"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var eggs list.Item
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &eggs))
+	assert.NotEmpty(t, eggs.ID)
+	assert.Equal(t, items+"/"+eggs.ID, w.Header().Get("Location"))
+
+	// a duplicate is merged into the existing item
+	w = send(r, http.MethodPost, items, "application/json", `{"name": "Milk"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), milk.ID)
+
+	// an empty name is rejected by the validation
+	w = send(r, http.MethodPost, items, "application/json", `{"name": ""}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = send(r, http.MethodGet, items, "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var found struct {
+		Items []*list.Item `json:"items"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &found))
+	assert.Len(t, found.Items, 2)
+
+	w = send(r, http.MethodDelete, items+"/"+eggs.ID, "", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, http.StatusNotFound, send(r, http.MethodGet, items+"/"+eggs.ID, "", "").Code)
+	assert.Equal(t, http.StatusNotFound, send(r, http.MethodDelete, items+"/"+eggs.ID, "", "").Code)
+}
+
+func TestPatchItem(t *testing.T) {
+	r, l, milk := listV2Router(t)
+	path := "/api/v2/lists/" + l.ID.Hex() + "/items/" + milk.ID
+
+	w := send(r, http.MethodGet, path, "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/merge-patch+json, application/json-patch+json", w.Header().Get("Accept-Patch"))
+
+	// a merge patch only changes the members it has
+	w = send(r, http.MethodPatch, path, "application/merge-patch+json", `{"done": true}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var patched list.Item
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &patched))
+	assert.Equal(t, list.Item{ID: milk.ID, Name: "milk", Quantity: "1L", Done: true, Attachments: []string{}}, patched)
+
+	w = send(r, http.MethodPatch, path, "application/json-patch+json", `[
+		{"op": "test", "path": "/quantity", "value": "1L"},
+		{"op": "replace", "path": "/quantity", "value": "2L"},
+		{"op": "replace", "path": "/done", "value": false}
+	]`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &patched))
+	assert.Equal(t, list.Item{ID: milk.ID, Name: "milk", Quantity: "2L", Done: false, Attachments: []string{}}, patched)
+
+	// a failed test is a conflict and nothing changes
+	w = send(r, http.MethodPatch, path, "application/json-patch+json", `[{"op": "test", "path": "/quantity", "value": "1L"}, {"op": "replace", "path": "/name", "value": "oat milk"}]`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, send(r, http.MethodGet, path, "", "").Body.String(), `"name":"milk"`)
+
+	// the id and the attachments are read-only
+	w = send(r, http.MethodPatch, path, "application/merge-patch+json", `{"id": "another"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = send(r, http.MethodPatch, path, "application/json-patch+json", `[{"op": "add", "path": "/attachments/-", "value": "abc"}]`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = send(r, http.MethodPatch, path, "application/merge-patch+json", `{"colour": "white"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// the patched item must still be valid
+	w = send(r, http.MethodPatch, path, "application/merge-patch+json", `{"name": 3}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = send(r, http.MethodPatch, path, "application/merge-patch+json", `{"name": null}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = send(r, http.MethodPatch, path, "application/json-patch+json", `{"op": "remove"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = send(r, http.MethodPatch, path, "application/json", `{"done": true}`)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, "application/merge-patch+json, application/json-patch+json", w.Header().Get("Accept-Patch"))
+}
+
+func TestPatchList(t *testing.T) {
+	r, l, _ := listV2Router(t)
+	path := "/api/v2/lists/" + l.ID.Hex()
+
+	w := send(r, http.MethodPatch, path, "application/merge-patch+json", `{"name": "weekend", "tags": ["weekend", "weekly", "weekend"]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var patched struct {
+		Name  string       `json:"name"`
+		Tags  []string     `json:"tags"`
+		Items []*list.Item `json:"items"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &patched))
+	assert.Equal(t, "weekend", patched.Name)
+	assert.ElementsMatch(t, []string{"weekly", "weekend"}, patched.Tags)
+	assert.Len(t, patched.Items, 1)
+
+	w = send(r, http.MethodPatch, path, "application/json-patch+json", `[{"op": "remove", "path": "/tags/0"}]`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &patched))
+	assert.Len(t, patched.Tags, 1)
+
+	// the items are resources of their own
+	w = send(r, http.MethodPatch, path, "application/json-patch+json", `[{"op": "remove", "path": "/items/0"}]`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = send(r, http.MethodPatch, path, "application/merge-patch+json", `{"name": ""}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestConditionalPatch(t *testing.T) {
+	r, l, milk := listV2Router(t)
+	patch := func(path string, etag string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		req.Header.Set("If-Match", etag)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		return w
+	}
+
+	for _, path := range []string{"/api/v2/lists/" + l.ID.Hex(), "/api/v2/lists/" + l.ID.Hex() + "/items/" + milk.ID} {
+		etag := send(r, http.MethodGet, path, "", "").Header().Get("ETag")
+		assert.NotEmpty(t, etag, path)
+
+		w := patch(path, etag, `{"name": "oat milk"}`)
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.NotEqual(t, etag, w.Header().Get("ETag"), path)
+		assert.Equal(t, w.Header().Get("ETag"), send(r, http.MethodGet, path, "", "").Header().Get("ETag"), path)
+
+		// the resource changed since the etag was given, nothing is patched
+		w = patch(path, etag, `{"name": "soy milk"}`)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code, path)
+		assert.Contains(t, send(r, http.MethodGet, path, "", "").Body.String(), `"name":"oat milk"`, path)
+
+		assert.Equal(t, http.StatusOK, patch(path, `"stale", `+w.Header().Get("ETag"), `{"name": "soy milk"}`).Code, path)
+		assert.Equal(t, http.StatusOK, patch(path, "*", `{"name": "rice milk"}`).Code, path)
+	}
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/gin-gonic/gin"
+)
+
+// UnlockUserHandler is a http handler forgetting the failed logins of a user
+func UnlockUserHandler(userSrv user.Service, lockoutSrv user.LockoutService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		currentUser, err := GetCurrentUser(c)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		locked, err := userSrv.FindByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := lockoutSrv.Unlock(c.Request.Context(), locked.Name, currentUser.Name); err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// FindSecurityEventsHandler is a http handler listing the latest security events. The limit query param defaults to 100
+func FindSecurityEventsHandler(srv user.LockoutService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, err := strconv.ParseInt(c.DefaultQuery("limit", "100"), 10, 64)
+		if err != nil || limit < 1 {
+			abortWithError(c, http.StatusBadRequest, fmt.Errorf("invalid limit %v", c.Query("limit")))
+			return
+		}
+
+		events, err := srv.FindSecurityEvents(c.Request.Context(), limit)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"events": events,
+		})
+	}
+}
+
+// abortIfLockedOut aborts the request with a too many requests status if err is a lockout, and returns true if it did
+func abortIfLockedOut(c *gin.Context, err error) bool {
+	var lockedOut *user.LockedOutError
+	if !errors.As(err, &lockedOut) {
+		return false
+	}
+
+	seconds := math.Ceil(lockedOut.RetryAfter(time.Now()).Seconds())
+	c.Header("Retry-After", strconv.Itoa(int(math.Max(seconds, 1))))
+	abortWithError(c, http.StatusTooManyRequests, err)
+
+	return true
+}
@@ -0,0 +1,122 @@
+package api_test
+
+import (
+	"context"
+	"encoding/base32"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/api"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/audit"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecondFactorFailuresCountAgainstTheAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	userSrv := newUserService()
+	alice, err := userSrv.Store(ctx, "alice", "password")
+	assert.NoError(t, err)
+	enrollment, err := userSrv.EnrollTOTP(ctx, alice.ID.Hex())
+	assert.NoError(t, err)
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+	assert.NoError(t, err)
+	_, err = userSrv.ConfirmTOTP(ctx, alice.ID.Hex(), user.TOTPCode(secret, time.Now().Unix()/30))
+	assert.NoError(t, err)
+
+	lockoutSrv := user.NewLockoutService(user.NewInMemoryLoginAttemptsRepository(), user.NewInMemorySecurityEventRepository(), &user.LockoutPolicy{
+		AccountThreshold: 3,
+		IPThreshold:      100,
+		BaseDelay:        time.Minute,
+		MaxDelay:         time.Hour,
+		ResetAfter:       time.Hour,
+	})
+	auditSrv := audit.NewService(audit.NewInMemoryRepository())
+
+	r := gin.New()
+	r.Use(api.ErrorMiddleware())
+	r.POST("/login", api.LoginHandler(userSrv, lockoutSrv, auditSrv))
+	r.POST("/login/2fa", api.VerifySecondFactorHandler(userSrv, lockoutSrv, auditSrv))
+
+	w := send(r, http.MethodPost, "/login", "application/json", `{"username": "alice", "password": "wrong"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// the right password alone does not forget the failures
+	w = send(r, http.MethodPost, "/login", "application/json", `{"username": "alice", "password": "password"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var challenge user.Challenge
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &challenge))
+	assert.NotEmpty(t, challenge.Token)
+
+	// wrong codes are failures of the challenged account
+	for i := 0; i < 2; i++ {
+		w = send(r, http.MethodPost, "/login/2fa", "application/json", `{"challenge": "`+challenge.Token+`", "code": "000000"}`)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+	w = send(r, http.MethodPost, "/login", "application/json", `{"username": "alice", "password": "password"}`)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	w = send(r, http.MethodPost, "/login/2fa", "application/json", `{"challenge": "`+challenge.Token+`", "code": "000000"}`)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	page, err := auditSrv.FindEvents(ctx, &audit.Query{Action: audit.ActionLoginFailure, TargetID: alice.ID.Hex()})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), page.Total)
+}
+
+func TestLoginFailuresLookAlike(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	userSrv := newUserService()
+	_, err := userSrv.Store(ctx, "alice", "password")
+	assert.NoError(t, err)
+	lockoutSrv := user.NewLockoutService(user.NewInMemoryLoginAttemptsRepository(), user.NewInMemorySecurityEventRepository(), &user.LockoutPolicy{
+		AccountThreshold: 10,
+		IPThreshold:      10,
+		BaseDelay:        time.Minute,
+		MaxDelay:         time.Hour,
+		ResetAfter:       time.Hour,
+	})
+
+	r := gin.New()
+	r.Use(api.ErrorMiddleware())
+	r.POST("/login", api.LoginHandler(userSrv, lockoutSrv, audit.NewService(audit.NewInMemoryRepository())))
+
+	// the response does not tell whether the user exists
+	unknown := send(r, http.MethodPost, "/login", "application/json", `{"username": "mallory", "password": "password"}`)
+	wrong := send(r, http.MethodPost, "/login", "application/json", `{"username": "alice", "password": "wrong"}`)
+	assert.Equal(t, http.StatusUnauthorized, unknown.Code)
+	assert.Equal(t, unknown.Code, wrong.Code)
+	assert.Equal(t, unknown.Body.String(), wrong.Body.String())
+}
+
+func TestLogoutWithAccessToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	userSrv := newUserService()
+	alice, err := userSrv.Store(ctx, "alice", "password")
+	assert.NoError(t, err)
+	_, token, err := userSrv.CreateAccessToken(ctx, alice, "script", 0)
+	assert.NoError(t, err)
+
+	r := gin.New()
+	r.Use(api.ErrorMiddleware(), api.AuthenticateMiddleware(userSrv))
+	r.POST("/logout", api.LogoutHandler(userSrv, audit.NewService(audit.NewInMemoryRepository())))
+
+	req := httptest.NewRequest(http.MethodPost, "/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// the token is still valid
+	_, err = userSrv.Authenticate(ctx, token)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/audit"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/oidc"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/gin-gonic/gin"
+)
+
+// OIDCLoginHandler is a http handler redirecting the user to the identity provider
+func OIDCLoginHandler(srv oidc.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authURL, err := srv.AuthorizationURL(c.Request.Context())
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.Redirect(http.StatusFound, authURL)
+	}
+}
+
+// OIDCCallbackHandler is a http handler completing the login once the identity provider redirected back.
+// It responds like the login handler, and audits the logins the same way
+func OIDCCallbackHandler(srv oidc.Service, auditSrv audit.Service) gin.HandlerFunc {
+	type response struct {
+		User *user.View `json:"user,omitempty"`
+		*user.Tokens
+		*user.Challenge
+	}
+
+	return func(c *gin.Context) {
+		if providerErr := c.Query("error"); providerErr != "" {
+			abortWithError(c, http.StatusUnauthorized, fmt.Errorf("the identity provider refused the login: %s", providerErr))
+			return
+		}
+
+		state, code := c.Query("state"), c.Query("code")
+		if state == "" || code == "" {
+			abortWithError(c, http.StatusBadRequest, errors.New("the state and code query params are required"))
+			return
+		}
+
+		loggedIn, tokens, challenge, err := srv.Callback(c.Request.Context(), state, code)
+		if err != nil {
+			recordAudit(c, auditSrv, audit.ActionLoginFailure, auditActor(c, nil), "", err.Error())
+			abortWithError(c, http.StatusUnauthorized, err)
+			return
+		}
+
+		if challenge != nil {
+			c.JSON(http.StatusOK, &response{
+				Challenge: challenge,
+			})
+			return
+		}
+
+		recordAudit(c, auditSrv, audit.ActionLogin, auditActor(c, loggedIn), loggedIn.ID.Hex(), "identity provider")
+
+		c.JSON(http.StatusOK, &response{
+			User:   UserView(c, loggedIn),
+			Tokens: tokens,
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package api
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"github.com/gin-gonic/gin"
+)
+
+// openAPIDocument is the OpenAPI 3 document describing every route registered by SetupRoutes
+//
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// docsPage is a standalone page rendering the OpenAPI document, so that browsing it needs nothing but the server
+//
+//go:embed docs.html
+var docsPage []byte
+
+// Spec is the part of the OpenAPI document the routes are checked and the requests validated against
+type Spec struct {
+	Paths      map[string]*PathItem `json:"paths"`
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+}
+
+// PathItem holds the operations of a path
+type PathItem struct {
+	Get    *Operation `json:"get"`
+	Post   *Operation `json:"post"`
+	Put    *Operation `json:"put"`
+	Patch  *Operation `json:"patch"`
+	Delete *Operation `json:"delete"`
+}
+
+// Operation is a method on a path
+type Operation struct {
+	Summary     string       `json:"summary"`
+	RequestBody *RequestBody `json:"requestBody"`
+}
+
+// RequestBody describes the bodies accepted by an operation, by media type
+type RequestBody struct {
+	Required bool `json:"required"`
+	Content  map[string]*struct {
+		Schema *Schema `json:"schema"`
+	} `json:"content"`
+}
+
+// Schema is the subset of the OpenAPI schemas the validation supports
+type Schema struct {
+	Ref        string             `json:"$ref"`
+	Type       string             `json:"type"`
+	Format     string             `json:"format"`
+	Nullable   bool               `json:"nullable"`
+	Enum       []interface{}      `json:"enum"`
+	Required   []string           `json:"required"`
+	Properties map[string]*Schema `json:"properties"`
+	Items      *Schema            `json:"items"`
+	MinLength  *int               `json:"minLength"`
+	MaxLength  *int               `json:"maxLength"`
+	Pattern    string             `json:"pattern"`
+	MinItems   *int               `json:"minItems"`
+	MaxItems   *int               `json:"maxItems"`
+	Minimum    *float64           `json:"minimum"`
+}
+
+// schemaError is returned when a request body does not match the schema of its operation
+type schemaError struct {
+	params []*InvalidParam
+}
+
+func (e *schemaError) Error() string {
+	reasons := make([]string, 0, len(e.params))
+	for _, p := range e.params {
+		reasons = append(reasons, p.Name+" "+p.Reason)
+	}
+
+	return "the request body does not match its schema: " + strings.Join(reasons, ", ")
+}
+
+// Is makes the schema errors validation errors
+func (e *schemaError) Is(target error) bool {
+	return target == common.ErrValidation
+}
+
+// LoadSpec parses the embedded OpenAPI document
+func LoadSpec() (*Spec, error) {
+	spec := &Spec{}
+	if err := json.Unmarshal(openAPIDocument, spec); err != nil {
+		return nil, fmt.Errorf("could not parse the OpenAPI document: %w", err)
+	}
+
+	return spec, nil
+}
+
+// Operation returns the operation of the route given by its method and gin path, like /api/v1/lists/:id, or nil if the document has none
+func (s *Spec) Operation(method string, route string) *Operation {
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+
+	item, ok := s.Paths[strings.Join(segments, "/")]
+	if !ok {
+		return nil
+	}
+
+	switch method {
+	case http.MethodGet:
+		return item.Get
+	case http.MethodPost:
+		return item.Post
+	case http.MethodPut:
+		return item.Put
+	case http.MethodPatch:
+		return item.Patch
+	case http.MethodDelete:
+		return item.Delete
+	default:
+		return nil
+	}
+}
+
+// OpenAPIHandler is a http handler serving the OpenAPI document
+func OpenAPIHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", openAPIDocument)
+	}
+}
+
+// DocsHandler is a http handler serving a page to browse the OpenAPI document
+func DocsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+	}
+}
+
+// errBodyTooLarge is returned when a request body is larger than the validation accepts
+var errBodyTooLarge = common.NewError(common.ErrValidation, "the request body is too large")
+
+// blankPattern is the pattern of the names, which must not be blank
+const blankPattern = `\S`
+
+// ValidationMiddleware rejects the JSON bodies that do not match the schema of their operation in the OpenAPI document.
+// It runs before the authentication, which is fine as the document is public, and reads at most maxBodySize bytes.
+// The routes missing from the document and the other media types, like the multipart uploads, are left to the handlers
+func ValidationMiddleware(spec *Spec, maxBodySize int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		operation := spec.Operation(c.Request.Method, c.FullPath())
+		if operation == nil || operation.RequestBody == nil {
+			return
+		}
+		media, ok := operation.RequestBody.Content["application/json"]
+		if !ok || media.Schema == nil {
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodySize))
+		if err != nil && int64(len(body)) >= maxBodySize {
+			abortWithError(c, http.StatusRequestEntityTooLarge, errBodyTooLarge)
+			return
+		}
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if len(bytes.TrimSpace(body)) == 0 {
+			if operation.RequestBody.Required {
+				abortWithError(c, http.StatusBadRequest, common.NewError(common.ErrValidation, "the request body is required"))
+			}
+			return
+		}
+
+		var document interface{}
+		if err := json.Unmarshal(body, &document); err != nil {
+			abortWithError(c, http.StatusBadRequest, common.NewError(common.ErrValidation, "the request body is not valid JSON: %v", err))
+			return
+		}
+
+		if params := spec.validate(media.Schema, document, "body"); len(params) > 0 {
+			abortWithError(c, http.StatusBadRequest, &schemaError{params: params})
+		}
+	}
+}
+
+// validate checks the value against the schema and returns the invalid params, named after their path in the body like body.prices[0].amount
+func (s *Spec) validate(schema *Schema, value interface{}, name string) []*InvalidParam {
+	schema = s.resolve(schema)
+	if schema == nil {
+		return nil
+	}
+
+	invalid := func(format string, args ...interface{}) []*InvalidParam {
+		return []*InvalidParam{{Name: name, Reason: fmt.Sprintf(format, args...)}}
+	}
+
+	if value == nil {
+		if schema.Nullable || schema.Type == "" {
+			return nil
+		}
+		return invalid("must not be null")
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		return invalid("must be one of %v", schema.Enum)
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return invalid("must be an object")
+		}
+		var params []*InvalidParam
+		for _, required := range schema.Required {
+			if _, ok := object[required]; !ok {
+				params = append(params, &InvalidParam{Name: name + "." + required, Reason: "is required"})
+			}
+		}
+		// the properties are checked in order so that the errors are stable
+		keys := make([]string, 0, len(schema.Properties))
+		for key := range schema.Properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if field, ok := object[key]; ok {
+				params = append(params, s.validate(schema.Properties[key], field, name+"."+key)...)
+			}
+		}
+		return params
+
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return invalid("must be an array")
+		}
+		if schema.MinItems != nil && len(array) < *schema.MinItems {
+			return invalid("must have at least %d items", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(array) > *schema.MaxItems {
+			return invalid("must have at most %d items", *schema.MaxItems)
+		}
+		var params []*InvalidParam
+		for i, element := range array {
+			params = append(params, s.validate(schema.Items, element, fmt.Sprintf("%s[%d]", name, i))...)
+		}
+		return params
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return invalid("must be a string")
+		}
+		if schema.MinLength != nil && len([]rune(str)) < *schema.MinLength {
+			if *schema.MinLength == 1 {
+				return invalid("must not be empty")
+			}
+			return invalid("must be at least %d characters long", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && len([]rune(str)) > *schema.MaxLength {
+			return invalid("must be at most %d characters long", *schema.MaxLength)
+		}
+		if schema.Pattern != "" {
+			if matched, err := regexp.MatchString(schema.Pattern, str); err != nil || !matched {
+				if schema.Pattern == blankPattern {
+					return invalid("must not be blank")
+				}
+				return invalid("must match %s", schema.Pattern)
+			}
+		}
+		return nil
+
+	case "integer", "number":
+		number, ok := value.(float64)
+		if !ok {
+			return invalid("must be a number")
+		}
+		if schema.Type == "integer" && number != math.Trunc(number) {
+			return invalid("must be an integer")
+		}
+		if schema.Minimum != nil && number < *schema.Minimum {
+			return invalid("must be at least %v", *schema.Minimum)
+		}
+		return nil
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return invalid("must be a boolean")
+		}
+		return nil
+
+	default:
+		return nil
+	}
+}
+
+// resolve follows the reference of the schema to the components, if it has one
+func (s *Spec) resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = s.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+
+	return schema
+}
+
+// inEnum returns true if the value is one of the values of the enum
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if allowed == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// schemaParams returns the invalid params of a schema error, if the error is one
+func schemaParams(err error) []*InvalidParam {
+	var schemaErr *schemaError
+	if !errors.As(err, &schemaErr) {
+		return nil
+	}
+
+	return schemaErr.params
+}
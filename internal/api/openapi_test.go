@@ -0,0 +1,110 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/api"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/attachment"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/oidc"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// oidcStub enables the OpenID Connect routes, its methods are never called
+type oidcStub struct {
+	oidc.Service
+}
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	spec, err := api.LoadSpec()
+	assert.NoError(t, err)
+
+	// the handlers are only built, not served, so the services are not needed
+	r := api.SetupRoutes(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &oidcStub{}, nil, nil, nil, &attachment.Limits{}, 1<<20)
+
+	registered := map[string]bool{}
+	for _, route := range r.Routes() {
+		registered[route.Method+" "+route.Path] = true
+		assert.NotNil(t, spec.Operation(route.Method, route.Path), "%s %s is missing from openapi.json", route.Method, route.Path)
+	}
+
+	// and the document describes no route that does not exist
+	for path, item := range spec.Paths {
+		route := strings.NewReplacer("{", ":", "}", "").Replace(path)
+		for method, operation := range map[string]*api.Operation{
+			http.MethodGet:    item.Get,
+			http.MethodPost:   item.Post,
+			http.MethodPut:    item.Put,
+			http.MethodPatch:  item.Patch,
+			http.MethodDelete: item.Delete,
+		} {
+			if operation != nil {
+				assert.True(t, registered[method+" "+route], "%s %s is documented but not registered", method, path)
+			}
+		}
+	}
+}
+
+// validationRouter serves routes answering ok behind the request validation
+func validationRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	spec, err := api.LoadSpec()
+	assert.NoError(t, err)
+
+	r := gin.New()
+	r.Use(api.ErrorMiddleware())
+	r.Use(api.ValidationMiddleware(spec, 1024))
+	ok := func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	}
+	r.POST("/api/v1/lists", ok)
+	r.POST("/api/v1/lists/:id", ok)
+	r.POST("/api/v1/stores/:id/prices", ok)
+	r.POST("/api/v1/logout", ok)
+
+	return r
+}
+
+func TestValidationMiddleware(t *testing.T) {
+	r := validationRouter(t)
+	post := func(path string, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return w
+	}
+
+	// empty list and item names are rejected
+	problem := problemOf(t, r, httptest.NewRequest(http.MethodPost, "/api/v1/lists", strings.NewReader(`{"name": ""}`)))
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, []*api.InvalidParam{{Name: "body.name", Reason: "must not be empty"}}, problem.InvalidParams)
+	problem = problemOf(t, r, httptest.NewRequest(http.MethodPost, "/api/v1/lists", strings.NewReader(`{"name": " \t "}`)))
+	assert.Equal(t, []*api.InvalidParam{{Name: "body.name", Reason: "must not be blank"}}, problem.InvalidParams)
+
+	problem = problemOf(t, r, httptest.NewRequest(http.MethodPost, "/api/v1/lists/abc", strings.NewReader(`{"quantity": "2"}`)))
+	assert.Equal(t, []*api.InvalidParam{{Name: "body.name", Reason: "is required"}}, problem.InvalidParams)
+
+	assert.Equal(t, http.StatusOK, post("/api/v1/lists", `{"name": "groceries"}`).Code)
+	assert.Equal(t, http.StatusOK, post("/api/v1/lists/abc", `{"name": "milk", "quantity": "2"}`).Code)
+
+	// the nested fields are named after their path
+	problem = problemOf(t, r, httptest.NewRequest(http.MethodPost, "/api/v1/stores/abc/prices", strings.NewReader(`{"source": "trip", "prices": [{"product": "milk", "amount": 1.5}]}`)))
+	assert.Equal(t, []*api.InvalidParam{{Name: "body.prices[0].amount", Reason: "must be an integer"}}, problem.InvalidParams)
+	problem = problemOf(t, r, httptest.NewRequest(http.MethodPost, "/api/v1/stores/abc/prices", strings.NewReader(`{"source": "guess", "prices": []}`)))
+	assert.Len(t, problem.InvalidParams, 2)
+
+	problem = problemOf(t, r, httptest.NewRequest(http.MethodPost, "/api/v1/lists", strings.NewReader(`{"name":`)))
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	problem = problemOf(t, r, httptest.NewRequest(http.MethodPost, "/api/v1/lists", nil))
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+
+	// the routes without a body are not validated
+	assert.Equal(t, http.StatusOK, post("/api/v1/logout", "").Code)
+
+	// the bodies are read up to a limit
+	problem = problemOf(t, r, httptest.NewRequest(http.MethodPost, "/api/v1/lists", strings.NewReader(`{"name": "`+strings.Repeat("a", 2048)+`"}`)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, problem.Status)
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"github.com/NicolasDutronc/shoppinglist-be/pkg/jsonpatch"
+	"github.com/gin-gonic/gin"
+)
+
+// acceptPatch is the value of the Accept-Patch header advertising the patch formats of the resources
+var acceptPatch = strings.Join([]string{jsonpatch.MergePatchType, jsonpatch.PatchType}, ", ")
+
+var (
+	// errUnsupportedPatch is returned when a patch is sent in neither of the supported formats
+	errUnsupportedPatch = errors.New("the patch must be a JSON Merge Patch (" + jsonpatch.MergePatchType + ") or a JSON Patch (" + jsonpatch.PatchType + ")")
+	// errPreconditionFailed is returned when the If-Match header of a request matches none of the entity tag of the resource
+	errPreconditionFailed = errors.New("the resource was changed, get it again before patching it")
+)
+
+// etagOf returns the strong entity tag of the JSON representation of a resource
+func etagOf(resource interface{}) (string, error) {
+	document, err := json.Marshal(resource)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(document)
+
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// writeETag sets the ETag header of the response to the entity tag of the resource, and aborts the request if it cannot be computed
+func writeETag(c *gin.Context, resource interface{}) bool {
+	etag, err := etagOf(resource)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return false
+	}
+	c.Header("ETag", etag)
+
+	return true
+}
+
+// checkIfMatch aborts the request with a precondition failed status if it has an If-Match header matching none of the entity tag of the resource, as in RFC 7232.
+// Weak entity tags never match. The resource is then not modified and false is returned
+func checkIfMatch(c *gin.Context, resource interface{}) bool {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		return true
+	}
+
+	etag, err := etagOf(resource)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return false
+	}
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	c.Header("ETag", etag)
+	abortWithError(c, http.StatusPreconditionFailed, errPreconditionFailed)
+	return false
+}
+
+// applyPatch applies the patch of the request body to the JSON representation of the resource and decodes the result into patched.
+// The patch may only change the writable members of the resource. Otherwise, or if the patch cannot be applied, the request is aborted and false is returned.
+// As in RFC 5789, a failed JSON Patch test is a conflict and a patch that cannot apply to the resource is unprocessable
+func applyPatch(c *gin.Context, resource interface{}, patched interface{}, writable ...string) bool {
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if mediaType != jsonpatch.MergePatchType && mediaType != jsonpatch.PatchType {
+		c.Header("Accept-Patch", acceptPatch)
+		abortWithError(c, http.StatusUnsupportedMediaType, errUnsupportedPatch)
+		return false
+	}
+
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, err)
+		return false
+	}
+
+	document, err := json.Marshal(resource)
+	if err != nil {
+		abortWithError(c, http.StatusInternalServerError, err)
+		return false
+	}
+
+	var result []byte
+	if mediaType == jsonpatch.MergePatchType {
+		result, err = jsonpatch.MergePatch(document, patch)
+	} else {
+		result, err = jsonpatch.Apply(document, patch)
+	}
+	switch {
+	case errors.Is(err, jsonpatch.ErrInvalidPatch):
+		abortWithError(c, http.StatusBadRequest, common.NewError(common.ErrValidation, "%v", err))
+		return false
+	case errors.Is(err, jsonpatch.ErrTestFailed):
+		abortWithError(c, http.StatusConflict, common.NewError(common.ErrConflict, "%v", err))
+		return false
+	case err != nil:
+		abortWithError(c, http.StatusUnprocessableEntity, common.NewError(common.ErrValidation, "%v", err))
+		return false
+	}
+
+	if err := checkWritable(document, result, writable); err != nil {
+		abortWithError(c, http.StatusUnprocessableEntity, err)
+		return false
+	}
+
+	if err := json.Unmarshal(result, patched); err != nil {
+		abortWithError(c, http.StatusUnprocessableEntity, common.NewError(common.ErrValidation, "the patched resource is invalid: %v", err))
+		return false
+	}
+
+	return true
+}
+
+// checkWritable returns a validation error if the patched document is not an object or differs from the original one outside of the writable members
+func checkWritable(original []byte, patched []byte, writable []string) error {
+	var before, after map[string]interface{}
+	if err := json.Unmarshal(original, &before); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(patched, &after); err != nil {
+		return common.NewError(common.ErrValidation, "the patched resource must be an object")
+	}
+
+	members := map[string]bool{}
+	for member := range before {
+		members[member] = true
+	}
+	for member := range after {
+		members[member] = true
+	}
+	for _, member := range writable {
+		delete(members, member)
+	}
+
+	changed := []string{}
+	for member := range members {
+		if !reflect.DeepEqual(before[member], after[member]) {
+			changed = append(changed, member)
+		}
+	}
+	if len(changed) > 0 {
+		sort.Strings(changed)
+		return common.NewError(common.ErrValidation, "only %s can be patched, not %s", strings.Join(writable, " and "), strings.Join(changed, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/gin-gonic/gin"
+)
+
+// errNotAllowed is returned when the current user does not satisfy the policy of a route
+var errNotAllowed = common.NewError(common.ErrForbidden, "the current user is not allowed to do this")
+
+// Policy decides whether the current user is allowed to go through a route
+type Policy func(c *gin.Context, currentUser *user.User) bool
+
+// Self is a policy allowing users to act on themselves. The route param holds the id of the targeted user.
+// Users authenticated with a personal access token only go through their scopes
+func Self(param string) Policy {
+	return func(c *gin.Context, currentUser *user.User) bool {
+		return currentUser.Scopes == nil && currentUser.ID.Hex() == c.Param(param)
+	}
+}
+
+// SelfByName is a policy allowing users to act on themselves. The route param holds the name of the targeted user.
+// Users authenticated with a personal access token only go through their scopes
+func SelfByName(param string) Policy {
+	return func(c *gin.Context, currentUser *user.User) bool {
+		return currentUser.Scopes == nil && currentUser.Name == c.Param(param)
+	}
+}
+
+// Unscoped is a policy rejecting the users authenticated with a personal access token
+func Unscoped() Policy {
+	return func(c *gin.Context, currentUser *user.User) bool {
+		return currentUser.Scopes == nil
+	}
+}
+
+// Permission is a policy requiring a permission. Both the action and the resource can reference route params, like list-:id
+func Permission(action string, resourceID string) Policy {
+	return func(c *gin.Context, currentUser *user.User) bool {
+		return currentUser.Can(resolveParams(c, action), resolveParams(c, resourceID)) == nil
+	}
+}
+
+// HoldsGrants is a policy requiring the current user to hold what the request grants: the permissions listed in the permissions field of the body
+// and the permissions of the roles listed in its roles field. The body is left for the handler, and a body that cannot be decoded is refused
+func HoldsGrants(roles user.RoleFinderByName) Policy {
+	type grants struct {
+		Permissions []*user.Permission `json:"permissions"`
+		Roles       []string           `json:"roles"`
+	}
+
+	return func(c *gin.Context, currentUser *user.User) bool {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return false
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var req grants
+		if err := json.Unmarshal(body, &req); err != nil {
+			return false
+		}
+
+		permissions := req.Permissions
+		for _, name := range req.Roles {
+			role, err := roles.FindRoleByName(c.Request.Context(), name)
+			if errors.Is(err, common.ErrNotFound) {
+				// unknown roles grant nothing, the handler refuses them
+				continue
+			}
+			if err != nil {
+				return false
+			}
+			permissions = append(permissions, role.Permissions...)
+		}
+
+		for _, p := range permissions {
+			if currentUser.Can(p.Action, p.ResourceID) != nil {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// AnyOf is a policy satisfied as soon as one of the policies is
+func AnyOf(policies ...Policy) Policy {
+	return func(c *gin.Context, currentUser *user.User) bool {
+		for _, policy := range policies {
+			if policy(c, currentUser) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// AllOf is a policy satisfied when all the policies are
+func AllOf(policies ...Policy) Policy {
+	return func(c *gin.Context, currentUser *user.User) bool {
+		for _, policy := range policies {
+			if !policy(c, currentUser) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// Authorize is a middleware aborting the request with a forbidden status when the current user does not satisfy the policy
+func Authorize(policy Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		currentUser, err := GetCurrentUser(c)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		if !policy(c, currentUser) {
+			abortWithError(c, http.StatusForbidden, errNotAllowed)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// resolveParams replaces the route params referenced in the value, like :id, by their value in the request
+func resolveParams(c *gin.Context, value string) string {
+	for _, param := range c.Params {
+		value = strings.Replace(value, fmt.Sprintf(":%s", param.Key), param.Value, 1)
+	}
+
+	return value
+}
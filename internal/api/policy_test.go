@@ -0,0 +1,156 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/api"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// policyRouter serves the route behind the policy for the given current user
+func policyRouter(currentUser *user.User, route string, policy api.Policy) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("currentUser", currentUser)
+	})
+	r.PUT(route, api.Authorize(policy), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	return r
+}
+
+func serve(r *gin.Engine, path string) int {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, path, nil))
+
+	return w.Code
+}
+
+func TestSelfServicePolicy(t *testing.T) {
+	alice := user.NewUser("alice", "")
+	bob := user.NewUser("bob", "")
+	admin := user.NewUser("admin", "", &user.Permission{ResourceID: "users", Action: "write"})
+
+	policy := api.AnyOf(api.Self("id"), api.Permission("write", "users"))
+
+	// alice can rename their own user but not bob
+	r := policyRouter(alice, "/users/:id/name", policy)
+	assert.Equal(t, http.StatusOK, serve(r, "/users/"+alice.ID.Hex()+"/name"))
+	assert.Equal(t, http.StatusForbidden, serve(r, "/users/"+bob.ID.Hex()+"/name"))
+
+	// the admin can rename anyone
+	r = policyRouter(admin, "/users/:id/name", policy)
+	assert.Equal(t, http.StatusOK, serve(r, "/users/"+bob.ID.Hex()+"/name"))
+}
+
+func TestAdminPolicy(t *testing.T) {
+	alice := user.NewUser("alice", "", &user.Permission{ResourceID: "users", Action: "write"})
+	admin := user.NewUser("admin", "", &user.Permission{ResourceID: "*", Action: "*"})
+
+	// managing users does not allow to grant permissions, even to oneself
+	r := policyRouter(alice, "/users/:id/permissions/add", api.Permission("write", "permissions"))
+	assert.Equal(t, http.StatusForbidden, serve(r, "/users/"+alice.ID.Hex()+"/permissions/add"))
+
+	r = policyRouter(admin, "/users/:id/permissions/add", api.Permission("write", "permissions"))
+	assert.Equal(t, http.StatusOK, serve(r, "/users/"+alice.ID.Hex()+"/permissions/add"))
+}
+
+func TestPermissionPolicyResolvesParams(t *testing.T) {
+	alice := user.NewUser("alice", "", &user.Permission{ResourceID: "list-1", Action: "write"})
+
+	// the params are resolved on each request, not only on the first one
+	r := policyRouter(alice, "/lists/:id", api.Permission("write", "list-:id"))
+	assert.Equal(t, http.StatusOK, serve(r, "/lists/1"))
+	assert.Equal(t, http.StatusForbidden, serve(r, "/lists/2"))
+	assert.Equal(t, http.StatusOK, serve(r, "/lists/1"))
+}
+
+func TestAllOfPolicy(t *testing.T) {
+	alice := user.NewUser("alice", "", &user.Permission{ResourceID: "users", Action: "write"})
+	bob := user.NewUser("bob", "")
+
+	r := policyRouter(alice, "/users/:id", api.AllOf(api.Self("id"), api.Permission("write", "users")))
+	assert.Equal(t, http.StatusOK, serve(r, "/users/"+alice.ID.Hex()))
+	assert.Equal(t, http.StatusForbidden, serve(r, "/users/"+bob.ID.Hex()))
+}
+
+func TestStoresPolicy(t *testing.T) {
+	roles := map[string]*user.Role{}
+	for _, role := range user.DefaultRoles() {
+		roles[role.Name] = role
+	}
+	viewer := user.NewUser("viewer", "")
+	viewer.RolePermissions = roles["viewer"].Permissions
+	editor := user.NewUser("editor", "")
+	editor.RolePermissions = roles["editor"].Permissions
+
+	// reading the lists does not allow to change the shared stores
+	r := policyRouter(viewer, "/stores/:id", api.Permission("write", "stores"))
+	assert.Equal(t, http.StatusForbidden, serve(r, "/stores/1"))
+
+	r = policyRouter(editor, "/stores/:id", api.Permission("write", "stores"))
+	assert.Equal(t, http.StatusOK, serve(r, "/stores/1"))
+}
+
+func TestInvitesPolicy(t *testing.T) {
+	editor := user.NewUser("editor", "")
+	for _, role := range user.DefaultRoles() {
+		if role.Name == "editor" {
+			editor.RolePermissions = role.Permissions
+		}
+	}
+
+	// a user without permissions cannot open the registration to others
+	r := policyRouter(user.NewUser("newcomer", ""), "/invites", api.Permission("write", "invites"))
+	assert.Equal(t, http.StatusForbidden, serve(r, "/invites"))
+
+	r = policyRouter(editor, "/invites", api.Permission("write", "invites"))
+	assert.Equal(t, http.StatusOK, serve(r, "/invites"))
+}
+
+func TestGrantsPolicy(t *testing.T) {
+	ctx := context.Background()
+	userSrv := newUserService()
+	assert.NoError(t, userSrv.EnsureRoles(ctx, user.DefaultRoles()...))
+	granter := user.NewUser("granter", "", &user.Permission{ResourceID: "permissions", Action: "write"}, &user.Permission{ResourceID: "list-*", Action: "read"})
+
+	policy := api.AllOf(api.Permission("write", "permissions"), api.HoldsGrants(userSrv))
+	r := policyRouter(granter, "/users/:id/permissions/add", policy)
+	grant := func(body string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/users/"+granter.ID.Hex()+"/permissions/add", strings.NewReader(body)))
+
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, grant(`{"permissions": [{"ResourceID": "list-abc", "Action": "read"}]}`))
+	assert.Equal(t, http.StatusOK, grant(`{"roles": ["viewer", "unknown"]}`))
+	assert.Equal(t, http.StatusForbidden, grant(`{"permissions": [{"ResourceID": "*", "Action": "*"}]}`))
+	assert.Equal(t, http.StatusForbidden, grant(`{"roles": ["editor"]}`))
+	assert.Equal(t, http.StatusForbidden, grant(`not json`))
+}
+
+func TestAvatarPolicy(t *testing.T) {
+	alice := user.NewUser("alice", "")
+	script := user.NewUser("alice", "")
+	script.Scopes = []*user.Permission{{ResourceID: "list-*", Action: "read"}}
+	directory := user.NewUser("alice", "")
+	directory.Scopes = []*user.Permission{{ResourceID: "users", Action: "read"}}
+	directory.Permissions = directory.Scopes
+
+	policy := api.AnyOf(api.Unscoped(), api.Permission("read", "users"))
+	path := "/users/id/" + user.NewUser("bob", "").ID.Hex() + "/avatar"
+
+	// avatars are public to the users, tokens need the scope to read the users
+	assert.Equal(t, http.StatusOK, serve(policyRouter(alice, "/users/id/:id/avatar", policy), path))
+	assert.Equal(t, http.StatusForbidden, serve(policyRouter(script, "/users/id/:id/avatar", policy), path))
+	assert.Equal(t, http.StatusOK, serve(policyRouter(directory, "/users/id/:id/avatar", policy), path))
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/NicolasDutronc/shoppinglist-be/pkg/hub"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PresenceHandler returns the members currently viewing the list identified by the id passed in params
+func PresenceHandler(presence *hub.Presence) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"members": presence.Members(hub.TopicFromString(c.Param("id"))),
+		})
+	}
+}
+
+// TopicAuthorizer lets users follow the topics of the lists they can read, along with the announcements of the new lists and filters and the filter topics.
+// Users connected with a personal access token only follow the lists in its scopes. The internal topics of the hub cannot be followed
+func TopicAuthorizer(srv user.Service) hub.Authorizer {
+	return func(ctx context.Context, identity *hub.Identity, topic hub.Topic) error {
+		if identity == nil {
+			return errNotAllowed
+		}
+
+		name := string(topic)
+		if name == "lists" || strings.HasPrefix(name, "filter-") {
+			return nil
+		}
+
+		// the topic of a list is its id
+		if _, err := primitive.ObjectIDFromHex(name); err != nil {
+			return errNotAllowed
+		}
+
+		// the permissions are reloaded to follow the revocations, the scopes of the token the user connected with still restrict them
+		u, err := srv.FindWithRoles(ctx, identity.ID)
+		if err != nil {
+			return err
+		}
+		scoped := *u
+		if principal, ok := identity.Principal.(*user.User); ok {
+			scoped.Scopes = principal.Scopes
+		}
+
+		return scoped.Can("read", "list-"+name)
+	}
+}
@@ -0,0 +1,47 @@
+package api_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/api"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/NicolasDutronc/shoppinglist-be/pkg/hub"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestTopicAuthorizerKeepsTheTokenScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	listA := primitive.NewObjectID().Hex()
+	listB := primitive.NewObjectID().Hex()
+
+	userSrv := newUserService()
+	alice, err := userSrv.Store(ctx, "alice", "password", &user.Permission{Action: "read", ResourceID: "list-" + listA}, &user.Permission{Action: "read", ResourceID: "list-" + listB})
+	assert.NoError(t, err)
+
+	identityOf := func(u *user.User) *hub.Identity {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set("currentUser", u)
+		identity, err := api.GetCurrentIdentity(c)
+		assert.NoError(t, err)
+		return identity
+	}
+	authorize := api.TopicAuthorizer(userSrv)
+
+	// with a session, alice follows every list they can read
+	session := identityOf(alice)
+	assert.NoError(t, authorize(ctx, session, hub.Topic(listA)))
+	assert.NoError(t, authorize(ctx, session, hub.Topic(listB)))
+
+	// with a token scoped to list A, list B is out of reach
+	scoped := *alice
+	scoped.Scopes = []*user.Permission{{Action: "read", ResourceID: "list-" + listA}}
+	token := identityOf(&scoped)
+	assert.NoError(t, authorize(ctx, token, hub.Topic(listA)))
+	assert.Error(t, authorize(ctx, token, hub.Topic(listB)))
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/attachment"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/gin-gonic/gin"
+)
+
+// avatarTypes are the image types accepted as avatars when the attachment limits allow any type
+var avatarTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// FindMeHandler is a http handler returning the current user along with its preferences
+func FindMeHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		currentUser, err := GetCurrentUser(c)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"user": UserView(c, currentUser),
+		})
+	}
+}
+
+// UpdateMyProfileHandler is a http handler replacing the profile of the current user. The default list must be readable by the user
+func UpdateMyProfileHandler(srv user.ProfileService, listSrv list.FinderByID) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var profile user.Profile
+		if err := c.ShouldBindJSON(&profile); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		currentUser, err := GetCurrentUser(c)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		if profile.DefaultList != "" {
+			if err := currentUser.Can("read", "list-"+profile.DefaultList); err != nil {
+				abortWithError(c, http.StatusForbidden, err)
+				return
+			}
+			if _, err := listSrv.FindListByID(c.Request.Context(), profile.DefaultList); err != nil {
+				abortWithError(c, http.StatusBadRequest, fmt.Errorf("unknown default list : %w", err))
+				return
+			}
+		}
+
+		n, err := srv.UpdateProfile(c.Request.Context(), currentUser.ID.Hex(), &profile)
+		if errors.Is(err, user.ErrInvalidProfile) {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"number_of_updated": n,
+			"profile":           &profile,
+		})
+	}
+}
+
+// UpdateMyAvatarHandler is a http handler replacing the avatar of the current user with the uploaded image.
+// The request is a multipart form with the file, which must be an image within the attachment limits
+func UpdateMyAvatarHandler(srv user.ProfileService, limits *attachment.Limits) gin.HandlerFunc {
+	avatarLimits := &attachment.Limits{
+		MaxSize:      limits.MaxSize,
+		AllowedTypes: avatarTypes,
+	}
+	if len(limits.AllowedTypes) > 0 {
+		avatarLimits.AllowedTypes = []string{}
+		for _, allowed := range limits.AllowedTypes {
+			if strings.HasPrefix(allowed, "image/") {
+				avatarLimits.AllowedTypes = append(avatarLimits.AllowedTypes, allowed)
+			}
+		}
+	}
+
+	return func(c *gin.Context) {
+		if avatarLimits.MaxSize > 0 {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, avatarLimits.MaxSize+multipartOverhead)
+		}
+
+		currentUser, err := GetCurrentUser(c)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		file, header, contentType, ok := openUpload(c, avatarLimits)
+		if !ok {
+			return
+		}
+		defer file.Close()
+
+		stored, err := srv.UpdateAvatar(c.Request.Context(), currentUser.ID.Hex(), header.Filename, contentType, file)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"avatar": stored,
+		})
+	}
+}
+
+// RemoveMyAvatarHandler is a http handler removing the avatar of the current user
+func RemoveMyAvatarHandler(srv user.ProfileService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		currentUser, err := GetCurrentUser(c)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		n, err := srv.RemoveAvatar(c.Request.Context(), currentUser.ID.Hex())
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"number_of_deleted": n,
+		})
+	}
+}
+
+// DownloadAvatarHandler is a http handler sending the avatar of a user. Avatars are public to the authenticated users
+func DownloadAvatarHandler(srv user.ProfileService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stored, content, err := srv.OpenAvatar(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+		defer content.Close()
+
+		c.DataFromReader(http.StatusOK, stored.Size, stored.ContentType, content, map[string]string{
+			"Content-Disposition":    mime.FormatMediaType("inline", map[string]string{"filename": stored.Filename}),
+			"X-Content-Type-Options": "nosniff",
+			"Cache-Control":          "private, max-age=3600",
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/audit"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/gin-gonic/gin"
+)
+
+// FindAllRolesHandler is a http handler listing the roles
+func FindAllRolesHandler(srv user.RoleService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roles, err := srv.FindAllRoles(c.Request.Context())
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"roles": roles,
+		})
+	}
+}
+
+// FindRoleByNameHandler is a http handler for the FindRoleByName service
+func FindRoleByNameHandler(srv user.RoleService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, err := srv.FindRoleByName(c.Request.Context(), c.Param("name"))
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"role": role,
+		})
+	}
+}
+
+// StoreRoleHandler is a http handler for the StoreRole service
+func StoreRoleHandler(srv user.RoleService, auditSrv audit.Service) gin.HandlerFunc {
+	type request struct {
+		Name        string             `json:"name" binding:"required"`
+		Permissions []*user.Permission `json:"permissions"`
+	}
+
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		role, err := srv.StoreRole(c.Request.Context(), req.Name, req.Permissions...)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		recordAudit(c, auditSrv, audit.ActionRoleCreated, auditActor(c, nil), role.Name, describePermissions(req.Permissions))
+
+		c.JSON(http.StatusCreated, gin.H{
+			"role": role,
+		})
+	}
+}
+
+// UpdateRolePermissionsHandler is a http handler replacing the permissions of a role
+func UpdateRolePermissionsHandler(srv user.RoleService, auditSrv audit.Service) gin.HandlerFunc {
+	type request struct {
+		Permissions []*user.Permission `json:"permissions"`
+	}
+
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		roleName := c.Param("name")
+
+		n, err := srv.UpdateRolePermissions(c.Request.Context(), roleName, req.Permissions...)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		recordAudit(c, auditSrv, audit.ActionRolePermissionsChanged, auditActor(c, nil), roleName, describePermissions(req.Permissions))
+
+		c.JSON(http.StatusOK, gin.H{
+			"number_of_updated": n,
+		})
+	}
+}
+
+// DeleteRoleHandler is a http handler for the DeleteRole service
+func DeleteRoleHandler(srv user.RoleService, auditSrv audit.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roleName := c.Param("name")
+
+		n, err := srv.DeleteRole(c.Request.Context(), roleName)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		recordAudit(c, auditSrv, audit.ActionRoleDeleted, auditActor(c, nil), roleName, "")
+
+		c.JSON(http.StatusOK, gin.H{
+			"number_of_deleted": n,
+		})
+	}
+}
+
+// AddRolesHandler is a http handler for the AddRoles service
+func AddRolesHandler(srv user.Service, auditSrv audit.Service) gin.HandlerFunc {
+	type request struct {
+		Roles []string `json:"roles"`
+	}
+
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		currentUser, err := GetCurrentUser(c)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		userID := c.Param("id")
+
+		n, err := srv.AddRoles(c.Request.Context(), currentUser, userID, req.Roles...)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		recordAudit(c, auditSrv, audit.ActionRolesGranted, auditActor(c, currentUser), userID, strings.Join(req.Roles, ", "))
+
+		c.JSON(http.StatusOK, gin.H{
+			"number_of_updated": n,
+		})
+	}
+}
+
+// RemoveRolesHandler is a http handler for the RemoveRoles service
+func RemoveRolesHandler(srv user.Service, auditSrv audit.Service) gin.HandlerFunc {
+	type request struct {
+		Roles []string `json:"roles"`
+	}
+
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		userID := c.Param("id")
+
+		n, err := srv.RemoveRoles(c.Request.Context(), userID, req.Roles...)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		recordAudit(c, auditSrv, audit.ActionRolesRevoked, auditActor(c, nil), userID, strings.Join(req.Roles, ", "))
+
+		c.JSON(http.StatusOK, gin.H{
+			"number_of_updated": n,
+		})
+	}
+}
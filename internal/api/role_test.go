@@ -0,0 +1,94 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/api"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/audit"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleChangesAreAudited(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	userSrv := newUserService()
+	admin, err := userSrv.Store(ctx, "admin", "password", &user.Permission{ResourceID: "list-*", Action: "write"})
+	assert.NoError(t, err)
+	bob, err := userSrv.Store(ctx, "bob", "password")
+	assert.NoError(t, err)
+	auditSrv := audit.NewService(audit.NewInMemoryRepository())
+
+	r := gin.New()
+	r.Use(api.ErrorMiddleware(), asUser(userSrv, admin.ID.Hex()))
+	r.POST("/roles", api.StoreRoleHandler(userSrv, auditSrv))
+	r.PUT("/roles/:name", api.UpdateRolePermissionsHandler(userSrv, auditSrv))
+	r.DELETE("/roles/:name", api.DeleteRoleHandler(userSrv, auditSrv))
+	r.PUT("/users/:id/roles/add", api.AddRolesHandler(userSrv, auditSrv))
+	r.PUT("/users/:id/roles/remove", api.RemoveRolesHandler(userSrv, auditSrv))
+
+	assert.Equal(t, http.StatusCreated, send(r, http.MethodPost, "/roles", "application/json", `{"name": "shopper", "permissions": [{"ResourceID": "list-*", "Action": "read"}]}`).Code)
+	assert.Equal(t, http.StatusOK, send(r, http.MethodPut, "/roles/shopper", "application/json", `{"permissions": [{"ResourceID": "list-*", "Action": "write"}]}`).Code)
+	assert.Equal(t, http.StatusOK, send(r, http.MethodPut, "/users/"+bob.ID.Hex()+"/roles/add", "application/json", `{"roles": ["shopper"]}`).Code)
+	assert.Equal(t, http.StatusOK, send(r, http.MethodPut, "/users/"+bob.ID.Hex()+"/roles/remove", "application/json", `{"roles": ["shopper"]}`).Code)
+	assert.Equal(t, http.StatusOK, send(r, http.MethodDelete, "/roles/shopper", "", "").Code)
+
+	page, err := auditSrv.FindEvents(ctx, &audit.Query{ActorID: admin.ID.Hex()})
+	assert.NoError(t, err)
+	recorded := map[string]*audit.Event{}
+	for _, event := range page.Events {
+		recorded[event.Action] = event
+	}
+	assert.Len(t, recorded, 5)
+
+	assert.Equal(t, "shopper", recorded[audit.ActionRoleCreated].TargetID)
+	assert.Equal(t, "write on list-*", recorded[audit.ActionRolePermissionsChanged].Details)
+	assert.Equal(t, "shopper", recorded[audit.ActionRoleDeleted].TargetID)
+	assert.Equal(t, bob.ID.Hex(), recorded[audit.ActionRolesGranted].TargetID)
+	assert.Equal(t, "shopper", recorded[audit.ActionRolesGranted].Details)
+	assert.Equal(t, bob.ID.Hex(), recorded[audit.ActionRolesRevoked].TargetID)
+}
+
+func TestGrantsCannotEscalate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	userSrv := newUserService()
+	assert.NoError(t, userSrv.EnsureRoles(ctx, user.DefaultRoles()...))
+	granter, err := userSrv.Store(ctx, "granter", "password",
+		&user.Permission{ResourceID: "permissions", Action: "write"},
+		&user.Permission{ResourceID: "roles", Action: "write"},
+		&user.Permission{ResourceID: "list-*", Action: "read"},
+	)
+	assert.NoError(t, err)
+	auditSrv := audit.NewService(audit.NewInMemoryRepository())
+
+	r := gin.New()
+	r.Use(api.ErrorMiddleware(), asUser(userSrv, granter.ID.Hex()))
+	r.PUT("/users/:id/permissions/add", api.AddPermissionsHandler(userSrv, auditSrv))
+	r.PUT("/users/:id/roles/add", api.AddRolesHandler(userSrv, auditSrv))
+	path := "/users/" + granter.ID.Hex()
+
+	// what the granter cannot do, they cannot grant, not even to themselves
+	assert.Equal(t, http.StatusForbidden, send(r, http.MethodPut, path+"/permissions/add", "application/json", `{"permissions": [{"ResourceID": "*", "Action": "*"}]}`).Code)
+	assert.Equal(t, http.StatusForbidden, send(r, http.MethodPut, path+"/permissions/add", "application/json", `{"permissions": [{"ResourceID": "list-*", "Action": "write"}]}`).Code)
+	assert.Equal(t, http.StatusForbidden, send(r, http.MethodPut, path+"/roles/add", "application/json", `{"roles": ["admin"]}`).Code)
+	assert.Equal(t, http.StatusBadRequest, send(r, http.MethodPut, path+"/roles/add", "application/json", `{"roles": ["nobody"]}`).Code)
+
+	// what they hold, they can share
+	assert.Equal(t, http.StatusOK, send(r, http.MethodPut, path+"/permissions/add", "application/json", `{"permissions": [{"ResourceID": "list-abc", "Action": "read"}]}`).Code)
+	assert.Equal(t, http.StatusOK, send(r, http.MethodPut, path+"/roles/add", "application/json", `{"roles": ["viewer"]}`).Code)
+
+	escalated, err := userSrv.FindWithRoles(ctx, granter.ID.Hex())
+	assert.NoError(t, err)
+	assert.Error(t, escalated.Can("write", "users"))
+	assert.Error(t, escalated.Can("write", "list-abc"))
+
+	page, err := auditSrv.FindEvents(ctx, &audit.Query{TargetID: granter.ID.Hex()})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), page.Total)
+}
@@ -3,30 +3,115 @@ package api
 import (
 	"time"
 
+	"github.com/NicolasDutronc/shoppinglist-be/internal/account"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/attachment"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/audit"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/catalog"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/invite"
 	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/oidc"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/store"
 	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
 	"github.com/NicolasDutronc/shoppinglist-be/pkg/hub"
+	"github.com/NicolasDutronc/shoppinglist-be/pkg/keyring"
 	"github.com/gin-gonic/gin"
 )
 
-// SetupRoutes registers the routes to the router
-func SetupRoutes(userSrv user.Service, listSrv list.Service, h hub.Hub) *gin.Engine {
+// SetupRoutes registers the routes to the router. The OpenID Connect login is only registered when oidcSrv is not nil.
+// Every route must be described in openapi.json, which the request bodies are validated against
+func SetupRoutes(userSrv user.Service, resetSrv user.ResetService, lockoutSrv user.LockoutService, profileSrv user.ProfileService, accountSrv account.Service, auditSrv audit.Service, listSrv list.Service, catalogSrv catalog.Service, storeSrv store.Service, inviteSrv invite.Service, oidcSrv oidc.Service, signer keyring.Signer, h hub.Hub, presence *hub.Presence, attachmentLimits *attachment.Limits, maxBodySize int64) *gin.Engine {
+	// the document is embedded and checked by the tests, it cannot fail to load in a released build
+	spec, err := LoadSpec()
+	if err != nil {
+		panic(err)
+	}
+
 	r := gin.Default()
+	r.HandleMethodNotAllowed = true
+	// gin trusts the forwarding headers of any address, which would let clients pick the address the lockout and the audit see.
+	// No proxy is trusted unless the mains configure some
+	if err := r.SetTrustedProxies(nil); err != nil {
+		panic(err)
+	}
+	r.Use(ErrorMiddleware())
+	r.Use(ValidationMiddleware(spec, maxBodySize))
+	r.NoRoute(NoRouteHandler())
+	r.NoMethod(NoMethodHandler())
 
-	r.POST("/api/v1/login", LoginHandler(userSrv))
+	r.GET("/.well-known/jwks.json", JWKSHandler(signer))
+	r.GET("/api/v1/openapi.json", OpenAPIHandler())
+	r.GET("/api/v1/docs", DocsHandler())
+	r.POST("/api/v1/login", LoginHandler(userSrv, lockoutSrv, auditSrv))
+	r.POST("/api/v1/login/verify", VerifySecondFactorHandler(userSrv, lockoutSrv, auditSrv))
+	if oidcSrv != nil {
+		r.GET("/api/v1/login/oidc", OIDCLoginHandler(oidcSrv))
+		r.GET("/api/v1/login/oidc/callback", OIDCCallbackHandler(oidcSrv, auditSrv))
+	}
+	r.POST("/api/v1/token/refresh", RefreshTokenHandler(userSrv))
+	r.POST("/api/v1/register", RegisterHandler(inviteSrv, auditSrv))
+	r.POST("/api/v1/password/forgot", ForgotPasswordHandler(resetSrv))
+	r.POST("/api/v1/password/reset", ResetPasswordHandler(resetSrv, auditSrv))
 
 	restricted := r.Group("/api/v1")
 	restricted.Use(AuthenticateMiddleware(userSrv))
 
+	restricted.POST("/logout", LogoutHandler(userSrv, auditSrv))
+	restricted.POST("/logout/all", LogoutEverywhereHandler(userSrv, auditSrv))
+
+	// users manage their own profile, personal access tokens only read it
+	me := restricted.Group("/me")
+	me.GET("", FindMeHandler())
+	me.PUT("", Authorize(Unscoped()), UpdateMyProfileHandler(profileSrv, listSrv))
+	me.PUT("/avatar", Authorize(Unscoped()), UpdateMyAvatarHandler(profileSrv, attachmentLimits))
+	me.DELETE("/avatar", Authorize(Unscoped()), RemoveMyAvatarHandler(profileSrv))
+
+	// users can read and rename themselves or change their own password, managing the others requires permissions on users
 	users := restricted.Group("/users")
-	users.GET("/id/:id", FindUserByIDHandler(userSrv))
-	users.GET("/name/:name", FindUserByNameHandler(userSrv))
-	users.POST("", StoreUserHandler(userSrv))
-	users.PUT("/:id/name", UpdateUserNameHandler(userSrv))
-	users.PUT("/:id/password", UpdateUserPasswordHandler(userSrv))
-	users.DELETE("/:id", DeleteUserHandler(userSrv))
-	users.PUT("/:id/permissions/add", AddPermissionsHandler(userSrv))
-	users.PUT("/:id/permissions/remove", RemovePermissionsHandler(userSrv))
+	users.GET("", AuthorizationMiddleware("read", "users"), SearchUsersHandler(userSrv))
+	users.POST("/bulk/disable", AuthorizationMiddleware("write", "users"), DisableUsersHandler(userSrv))
+	users.POST("/bulk/enable", AuthorizationMiddleware("write", "users"), EnableUsersHandler(userSrv))
+	users.POST("/bulk/password-reset", AuthorizationMiddleware("write", "users"), ForcePasswordResetHandler(resetSrv))
+	users.GET("/id/:id", Authorize(AnyOf(Self("id"), Permission("read", "users"))), FindUserByIDHandler(userSrv))
+	users.GET("/id/:id/avatar", Authorize(AnyOf(Unscoped(), Permission("read", "users"))), DownloadAvatarHandler(profileSrv))
+	users.GET("/name/:name", Authorize(AnyOf(SelfByName("name"), Permission("read", "users"))), FindUserByNameHandler(userSrv))
+	users.POST("", AuthorizationMiddleware("write", "users"), StoreUserHandler(userSrv))
+	users.PUT("/:id/name", Authorize(AnyOf(Self("id"), Permission("write", "users"))), UpdateUserNameHandler(userSrv))
+	users.PUT("/:id/password", Authorize(AnyOf(Self("id"), Permission("write", "users"))), UpdateUserPasswordHandler(userSrv, auditSrv))
+	users.PUT("/:id/email", Authorize(AnyOf(Self("id"), Permission("write", "users"))), UpdateUserEmailHandler(userSrv))
+	users.POST("/:id/totp", Authorize(Self("id")), EnrollTOTPHandler(userSrv))
+	users.PUT("/:id/totp", Authorize(Self("id")), ConfirmTOTPHandler(userSrv))
+	users.DELETE("/:id/totp", Authorize(Self("id")), DisableTOTPHandler(userSrv))
+	users.DELETE("/:id/lockout", AuthorizationMiddleware("write", "users"), UnlockUserHandler(userSrv, lockoutSrv))
+	users.GET("/:id/export", Authorize(AnyOf(Self("id"), Permission("read", "users"))), ExportUserHandler(accountSrv))
+	users.DELETE("/:id", AuthorizationMiddleware("write", "users"), DeleteUserHandler(accountSrv, auditSrv))
+	users.PUT("/:id/permissions/add", Authorize(AllOf(Permission("write", "permissions"), HoldsGrants(userSrv))), AddPermissionsHandler(userSrv, auditSrv))
+	users.PUT("/:id/permissions/remove", AuthorizationMiddleware("write", "permissions"), RemovePermissionsHandler(userSrv, auditSrv))
+	users.PUT("/:id/roles/add", Authorize(AllOf(Permission("write", "roles"), HoldsGrants(userSrv))), AddRolesHandler(userSrv, auditSrv))
+	users.PUT("/:id/roles/remove", AuthorizationMiddleware("write", "roles"), RemoveRolesHandler(userSrv, auditSrv))
+
+	// personal access tokens let scripts act on behalf of their owner, they cannot manage tokens themselves
+	tokens := restricted.Group("/tokens")
+	tokens.Use(Authorize(Unscoped()))
+	tokens.GET("", FindMyAccessTokensHandler(userSrv))
+	tokens.POST("", CreateAccessTokenHandler(userSrv))
+	tokens.DELETE("/:id", RevokeAccessTokenHandler(userSrv, auditSrv))
+
+	// inviting requires a permission, and invites only grant the permissions their creator holds
+	invites := restricted.Group("/invites")
+	invites.GET("", FindMyInvitesHandler(inviteSrv))
+	invites.POST("", AuthorizationMiddleware("write", "invites"), CreateInviteHandler(inviteSrv))
+	invites.DELETE("/:id", DeleteInviteHandler(inviteSrv))
+
+	roles := restricted.Group("/roles")
+	roles.GET("", AuthorizationMiddleware("read", "roles"), FindAllRolesHandler(userSrv))
+	roles.POST("", AuthorizationMiddleware("write", "roles"), StoreRoleHandler(userSrv, auditSrv))
+	roles.GET("/:name", AuthorizationMiddleware("read", "roles"), FindRoleByNameHandler(userSrv))
+	roles.PUT("/:name", AuthorizationMiddleware("write", "roles"), UpdateRolePermissionsHandler(userSrv, auditSrv))
+	roles.DELETE("/:name", AuthorizationMiddleware("write", "roles"), DeleteRoleHandler(userSrv, auditSrv))
+
+	restricted.GET("/security/events", AuthorizationMiddleware("read", "security"), FindSecurityEventsHandler(lockoutSrv))
+	restricted.GET("/security/audit", AuthorizationMiddleware("read", "security"), FindAuditEventsHandler(auditSrv))
+	restricted.GET("/security/audit/export", AuthorizationMiddleware("read", "security"), ExportAuditEventsHandler(auditSrv))
 
 	restricted.GET("/inventory", GetInventoryHandler(listSrv))
 
@@ -42,11 +127,50 @@ func SetupRoutes(userSrv user.Service, listSrv list.Service, h hub.Hub) *gin.Eng
 	listI.PUT("/delete", AuthorizationMiddleware("write", "list-:id"), RemoveItemHandler(listSrv))
 	listI.PUT("/clear", AuthorizationMiddleware("write", "list-:id"), RemoveAllItemsHandler(listSrv))
 	listI.DELETE("", AuthorizationMiddleware("write", "list-:id"), DeleteListHandler(listSrv))
+	listI.PUT("/tags/add", AuthorizationMiddleware("write", "list-:id"), AddTagsHandler(listSrv))
+	listI.PUT("/tags/remove", AuthorizationMiddleware("write", "list-:id"), RemoveTagsHandler(listSrv))
+	listI.POST("/scan", AuthorizationMiddleware("write", "list-:id"), ScanHandler(catalogSrv, listSrv))
+	listI.GET("/presence", AuthorizationMiddleware("read", "list-:id"), PresenceHandler(presence))
+	listI.GET("/plan", AuthorizationMiddleware("read", "list-:id"), PlanListHandler(listSrv, storeSrv))
+	listI.POST("/attachments", AuthorizationMiddleware("write", "list-:id"), UploadAttachmentHandler(listSrv, attachmentLimits))
+	listI.GET("/attachments/:attachmentId", AuthorizationMiddleware("read", "list-:id"), DownloadAttachmentHandler(listSrv))
+	listI.DELETE("/attachments/:attachmentId", AuthorizationMiddleware("write", "list-:id"), DeleteAttachmentHandler(listSrv))
+
+	restricted.GET("/products/:barcode", FindProductHandler(catalogSrv))
+
+	// the stores and their prices are shared by everyone, changing them requires a permission
+	stores := restricted.Group("/stores")
+	stores.GET("", FindAllStoresHandler(storeSrv))
+	stores.POST("", AuthorizationMiddleware("write", "stores"), CreateStoreHandler(storeSrv))
+	stores.GET("/:id", FindStoreByIDHandler(storeSrv))
+	stores.DELETE("/:id", AuthorizationMiddleware("write", "stores"), DeleteStoreHandler(storeSrv))
+	stores.POST("/:id/prices", AuthorizationMiddleware("write", "stores"), RecordPricesHandler(storeSrv))
+
+	restricted.GET("/prices", FindPriceHistoryHandler(storeSrv))
+
+	filters := restricted.Group("/filters")
+	filters.GET("", FindAllFiltersHandler(listSrv))
+	filters.POST("", StoreFilterHandler(listSrv, userSrv, auditSrv))
+	filters.GET("/:id", EvaluateFilterHandler(listSrv))
+	filters.DELETE("/:id", AuthorizationMiddleware("write", "filter-:id"), DeleteFilterHandler(listSrv))
+
+	// the v2 api exposes the items as resources and accepts partial updates, side by side with the v1 api
+	v2 := r.Group("/api/v2")
+	v2.Use(AuthenticateMiddleware(userSrv))
+
+	listV2 := v2.Group("/lists/:id")
+	listV2.GET("", AuthorizationMiddleware("read", "list-:id"), FindListResourceHandler(listSrv))
+	listV2.PATCH("", AuthorizationMiddleware("write", "list-:id"), PatchListHandler(listSrv))
+	listV2.GET("/items", AuthorizationMiddleware("read", "list-:id"), FindItemsHandler(listSrv))
+	listV2.POST("/items", AuthorizationMiddleware("write", "list-:id"), CreateItemHandler(listSrv))
+	listV2.GET("/items/:itemId", AuthorizationMiddleware("read", "list-:id"), FindItemHandler(listSrv))
+	listV2.PATCH("/items/:itemId", AuthorizationMiddleware("write", "list-:id"), PatchItemHandler(listSrv))
+	listV2.DELETE("/items/:itemId", AuthorizationMiddleware("write", "list-:id"), DeleteItemHandler(listSrv))
 
 	hubGroup := restricted.Group("/hub")
-	hubGroup.GET("/connect", hub.WebsocketHandler(h, time.Hour, 1024, time.Hour))
-	hubGroup.POST("/subscribe", hub.SubscriptionHandler(h))
-	hubGroup.POST("/unsubscribe", hub.UnsubscriptionHandler(h))
+	hubGroup.GET("/connect", hub.WebsocketHandler(h, GetCurrentIdentity, TopicAuthorizer(userSrv), time.Hour, 1024, time.Hour))
+	hubGroup.POST("/subscribe", hub.SubscriptionHandler(h, GetCurrentIdentity, TopicAuthorizer(userSrv)))
+	hubGroup.POST("/unsubscribe", hub.UnsubscriptionHandler(h, GetCurrentIdentity))
 
 	return r
 }
@@ -0,0 +1,36 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/api"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/attachment"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForwardingHeadersAreOnlyTrustedFromProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := api.SetupRoutes(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &attachment.Limits{}, 1<<20)
+	r.GET("/ip", func(c *gin.Context) {
+		c.String(http.StatusOK, c.ClientIP())
+	})
+
+	clientIP := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", "192.0.2.7")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		return w.Body.String()
+	}
+
+	// by default, a client cannot choose its address
+	assert.Equal(t, "10.0.0.1", clientIP())
+
+	assert.NoError(t, r.SetTrustedProxies([]string{"10.0.0.0/8"}))
+	assert.Equal(t, "192.0.2.7", clientIP())
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// FindAllStoresHandler returns all stores
+func FindAllStoresHandler(srv store.Finder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stores, err := srv.FindAllStores(c.Request.Context())
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"stores": stores,
+		})
+	}
+}
+
+// FindStoreByIDHandler returns the store identified by the id passed in params
+func FindStoreByIDHandler(srv store.FinderByID) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s, err := srv.FindStoreByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"store": s,
+		})
+	}
+}
+
+// CreateStoreHandler creates a new store and returns it
+func CreateStoreHandler(srv store.Creator) gin.HandlerFunc {
+	type request struct {
+		Name    string `json:"name" binding:"required"`
+		Address string `json:"address"`
+	}
+
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		s, err := srv.CreateStore(c.Request.Context(), req.Name, req.Address)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"store": s,
+		})
+	}
+}
+
+// DeleteStoreHandler deletes the store identified by the id passed in params
+func DeleteStoreHandler(srv store.Deleter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		n, err := srv.DeleteStore(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"number_of_deleted": n,
+		})
+	}
+}
+
+// RecordPricesHandler records prices in the store identified by the id passed in params.
+// Prices come either from a shopping trip or are entered manually
+func RecordPricesHandler(srv store.Service) gin.HandlerFunc {
+	type request struct {
+		Source store.PriceSource   `json:"source"`
+		Prices []*store.PriceEntry `json:"prices" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		if req.Source == "" {
+			req.Source = store.SourceManual
+		}
+
+		n, err := srv.RecordPrices(c.Request.Context(), c.Param("id"), req.Source, req.Prices...)
+		if err != nil {
+			if errors.Is(err, store.ErrInvalidPrice) {
+				abortWithError(c, http.StatusBadRequest, err)
+				return
+			}
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"number_of_recorded": n,
+		})
+	}
+}
+
+// FindPriceHistoryHandler returns the prices recorded for the product passed in the query
+func FindPriceHistoryHandler(srv store.PriceHistoryFinder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		product := c.Query("product")
+		if product == "" {
+			abortWithError(c, http.StatusBadRequest, errors.New("the product query parameter is required"))
+			return
+		}
+
+		prices, err := srv.FindPriceHistory(c.Request.Context(), product)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"prices": prices,
+		})
+	}
+}
+
+// PlanListHandler tells where to buy the items of the list identified by the id passed in params.
+// The mode query parameter is either split, the default, or single
+func PlanListHandler(listSrv list.FinderByID, storeSrv store.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		l, err := listSrv.FindListByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		mode := store.PlanMode(c.DefaultQuery("mode", string(store.ModeSplit)))
+		plan, err := storeSrv.PlanList(c.Request.Context(), l, mode)
+		if err != nil {
+			if errors.Is(err, store.ErrUnknownMode) {
+				abortWithError(c, http.StatusBadRequest, err)
+				return
+			}
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"plan": plan,
+		})
+	}
+}
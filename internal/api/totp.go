@@ -0,0 +1,85 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/gin-gonic/gin"
+)
+
+// EnrollTOTPHandler is a http handler generating a TOTP secret for the user
+func EnrollTOTPHandler(srv user.TwoFactorService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		enrollment, err := srv.EnrollTOTP(c.Request.Context(), c.Param("id"))
+		if errors.Is(err, user.ErrTOTPAlreadyEnabled) {
+			abortWithError(c, http.StatusConflict, err)
+			return
+		}
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, enrollment)
+	}
+}
+
+// ConfirmTOTPHandler is a http handler enabling two-factor authentication with a first code. It returns the recovery codes
+func ConfirmTOTPHandler(srv user.TwoFactorService) gin.HandlerFunc {
+	type request struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		codes, err := srv.ConfirmTOTP(c.Request.Context(), c.Param("id"), req.Code)
+		switch {
+		case errors.Is(err, user.ErrInvalidSecondFactor), errors.Is(err, user.ErrTOTPNotEnrolled):
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		case errors.Is(err, user.ErrTOTPAlreadyEnabled):
+			abortWithError(c, http.StatusConflict, err)
+			return
+		case err != nil:
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"recovery_codes": codes,
+		})
+	}
+}
+
+// DisableTOTPHandler is a http handler removing two-factor authentication after checking a code
+func DisableTOTPHandler(srv user.TwoFactorService) gin.HandlerFunc {
+	type request struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		err := srv.DisableTOTP(c.Request.Context(), c.Param("id"), req.Code)
+		if errors.Is(err, user.ErrInvalidSecondFactor) || errors.Is(err, user.ErrTOTPNotEnrolled) {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/NicolasDutronc/shoppinglist-be/internal/audit"
 	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
 	"github.com/gin-gonic/gin"
 )
@@ -16,11 +17,11 @@ func FindUserByIDHandler(srv user.Service) gin.HandlerFunc {
 		id := c.Param("id")
 		user, err := srv.FindByID(c.Request.Context(), id)
 		if err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, user)
+		c.JSON(http.StatusOK, UserView(c, user))
 	}
 }
 
@@ -30,11 +31,11 @@ func FindUserByNameHandler(srv user.Service) gin.HandlerFunc {
 		name := c.Param("name")
 		user, err := srv.FindByName(c.Request.Context(), name)
 		if err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, user)
+		c.JSON(http.StatusOK, UserView(c, user))
 	}
 }
 
@@ -49,17 +50,17 @@ func StoreUserHandler(srv user.Service) gin.HandlerFunc {
 		var req request
 
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.AbortWithError(http.StatusBadRequest, err)
+			abortWithError(c, http.StatusBadRequest, err)
 			return
 		}
 
 		user, err := srv.Store(c.Request.Context(), req.Name, req.Password)
 		if err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
-		c.JSON(http.StatusCreated, user)
+		c.JSON(http.StatusCreated, UserView(c, user))
 	}
 }
 
@@ -72,7 +73,7 @@ func UpdateUserNameHandler(srv user.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req request
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.AbortWithError(http.StatusBadRequest, err)
+			abortWithError(c, http.StatusBadRequest, err)
 			return
 		}
 
@@ -80,7 +81,7 @@ func UpdateUserNameHandler(srv user.Service) gin.HandlerFunc {
 
 		n, err := srv.UpdateName(c.Request.Context(), userID, req.NewName)
 		if err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
@@ -91,7 +92,7 @@ func UpdateUserNameHandler(srv user.Service) gin.HandlerFunc {
 }
 
 // UpdateUserPasswordHandler is a http handler for the UpdatePassword service
-func UpdateUserPasswordHandler(srv user.Service) gin.HandlerFunc {
+func UpdateUserPasswordHandler(srv user.Service, auditSrv audit.Service) gin.HandlerFunc {
 	type request struct {
 		CurrentPassword string `json:"currentPassword"`
 		NewPassword     string `json:"newPassword"`
@@ -100,7 +101,7 @@ func UpdateUserPasswordHandler(srv user.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req request
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.AbortWithError(http.StatusBadRequest, err)
+			abortWithError(c, http.StatusBadRequest, err)
 			return
 		}
 
@@ -108,35 +109,101 @@ func UpdateUserPasswordHandler(srv user.Service) gin.HandlerFunc {
 
 		n, err := srv.UpdatePassword(c.Request.Context(), userID, req.CurrentPassword, req.NewPassword)
 		if err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
+		recordAudit(c, auditSrv, audit.ActionPasswordChanged, auditActor(c, nil), userID, "")
+
 		c.JSON(http.StatusCreated, gin.H{
 			"number_of_updated": n,
 		})
 	}
 }
 
-// DeleteUserHandler is a http handler for the Delete service
-func DeleteUserHandler(srv user.Service) gin.HandlerFunc {
+// UpdateUserEmailHandler is a http handler for the UpdateEmail service
+func UpdateUserEmailHandler(srv user.Service) gin.HandlerFunc {
+	type request struct {
+		NewEmail string `json:"newEmail" binding:"required,email"`
+	}
+
 	return func(c *gin.Context) {
-		id := c.Param("id")
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
 
-		n, err := srv.Delete(c.Request.Context(), id)
+		n, err := srv.UpdateEmail(c.Request.Context(), c.Param("id"), req.NewEmail)
 		if err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{
-			"number_of_deleted": n,
+		c.JSON(http.StatusCreated, gin.H{
+			"number_of_updated": n,
 		})
 	}
 }
 
+// ForgotPasswordHandler is a http handler sending a password reset token to a user.
+// It accepts the request whether the user exists or not so that callers cannot find out which users exist,
+// and only refuses the addresses asking too many times
+func ForgotPasswordHandler(srv user.ResetService) gin.HandlerFunc {
+	type request struct {
+		Username string `json:"username" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := srv.RequestPasswordReset(c.Request.Context(), req.Username, c.ClientIP()); err != nil {
+			if !abortIfLockedOut(c, err) {
+				abortWithError(c, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		c.Status(http.StatusAccepted)
+	}
+}
+
+// ResetPasswordHandler is a http handler setting a new password from a reset token
+func ResetPasswordHandler(srv user.ResetService, auditSrv audit.Service) gin.HandlerFunc {
+	type request struct {
+		Token       string `json:"token" binding:"required"`
+		NewPassword string `json:"newPassword" binding:"required"`
+	}
+
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		userID, err := srv.ResetPassword(c.Request.Context(), req.Token, req.NewPassword)
+		if errors.Is(err, user.ErrInvalidResetToken) {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		recordAudit(c, auditSrv, audit.ActionPasswordReset, auditActor(c, nil), userID, "")
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
 // AddPermissionsHandler is a http handler for the AddPermissions service
-func AddPermissionsHandler(srv user.Service) gin.HandlerFunc {
+func AddPermissionsHandler(srv user.Service, auditSrv audit.Service) gin.HandlerFunc {
 	type request struct {
 		Permissions []*user.Permission `json:"permissions"`
 	}
@@ -144,18 +211,26 @@ func AddPermissionsHandler(srv user.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req request
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.AbortWithError(http.StatusBadRequest, err)
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		currentUser, err := GetCurrentUser(c)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
 		userID := c.Param("id")
 
-		n, err := srv.AddPermissions(c.Request.Context(), userID, req.Permissions...)
+		n, err := srv.AddPermissions(c.Request.Context(), currentUser, userID, req.Permissions...)
 		if err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
+		recordAudit(c, auditSrv, audit.ActionPermissionsGranted, auditActor(c, currentUser), userID, describePermissions(req.Permissions))
+
 		c.JSON(http.StatusOK, gin.H{
 			"number_of_updated": n,
 		})
@@ -163,7 +238,7 @@ func AddPermissionsHandler(srv user.Service) gin.HandlerFunc {
 }
 
 // RemovePermissionsHandler is a http handler for the RemovePermissions service
-func RemovePermissionsHandler(srv user.Service) gin.HandlerFunc {
+func RemovePermissionsHandler(srv user.Service, auditSrv audit.Service) gin.HandlerFunc {
 	type request struct {
 		Permissions []*user.Permission `json:"permissions"`
 	}
@@ -171,7 +246,7 @@ func RemovePermissionsHandler(srv user.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req request
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.AbortWithError(http.StatusBadRequest, err)
+			abortWithError(c, http.StatusBadRequest, err)
 			return
 		}
 
@@ -179,108 +254,265 @@ func RemovePermissionsHandler(srv user.Service) gin.HandlerFunc {
 
 		n, err := srv.RemovePermissions(c.Request.Context(), userID, req.Permissions...)
 		if err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
+		recordAudit(c, auditSrv, audit.ActionPermissionsRevoked, auditActor(c, nil), userID, describePermissions(req.Permissions))
+
 		c.JSON(http.StatusOK, gin.H{
 			"number_of_updated": n,
 		})
 	}
 }
 
-// LoginHandler is a http handler for the login service
-func LoginHandler(srv user.Service) gin.HandlerFunc {
+// LoginHandler is a http handler for the login service.
+// Users with two-factor authentication only get a challenge to complete with VerifySecondFactorHandler
+// Failed logins are counted per account and per address, and locked out accounts and addresses are rejected.
+// Both the failed and the completed logins are audited
+func LoginHandler(srv user.LoginService, lockoutSrv user.LockoutService, auditSrv audit.Service) gin.HandlerFunc {
 	type request struct {
 		Username string `json:"username"`
 		Password string `json:"password"`
 	}
 
 	type response struct {
-		User  *user.User `json:"user"`
-		Token string     `json:"token"`
+		User *user.View `json:"user,omitempty"`
+		*user.Tokens
+		*user.Challenge
 	}
 
 	return func(c *gin.Context) {
 		var req request
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.AbortWithError(http.StatusBadRequest, err)
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := lockoutSrv.CheckLogin(c.Request.Context(), req.Username, c.ClientIP()); err != nil {
+			if !abortIfLockedOut(c, err) {
+				abortWithError(c, http.StatusInternalServerError, err)
+			}
 			return
 		}
 
-		user, token, err := srv.Login(c.Request.Context(), req.Username, req.Password)
+		u, tokens, challenge, err := srv.Login(c.Request.Context(), req.Username, req.Password)
 		if err != nil {
-			c.AbortWithError(http.StatusUnauthorized, err)
+			recordAudit(c, auditSrv, audit.ActionLoginFailure, anonymousActor(c, req.Username), "", err.Error())
+		}
+		if errors.Is(err, user.ErrUserDisabled) || errors.Is(err, user.ErrPasswordResetRequired) {
+			// the password was right, this is not a guess
+			abortWithError(c, http.StatusForbidden, err)
 			return
 		}
+		if err != nil {
+			if recordErr := lockoutSrv.RecordLoginFailure(c.Request.Context(), req.Username, c.ClientIP()); recordErr != nil {
+				abortWithError(c, http.StatusInternalServerError, recordErr)
+				return
+			}
+			abortWithError(c, http.StatusUnauthorized, err)
+			return
+		}
+
+		// with two-factor authentication, the login only succeeds once the challenge is completed
+		if challenge != nil {
+			c.JSON(http.StatusOK, &response{
+				Challenge: challenge,
+			})
+			return
+		}
+
+		if err := lockoutSrv.RecordLoginSuccess(c.Request.Context(), req.Username, c.ClientIP()); err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		recordAudit(c, auditSrv, audit.ActionLogin, auditActor(c, u), u.ID.Hex(), "password")
 
 		c.JSON(http.StatusOK, &response{
-			User:  user,
-			Token: token,
+			User:   UserView(c, u),
+			Tokens: tokens,
 		})
 	}
 }
 
-// AuthenticateMiddleware is a http middleware for the authenticate service
-func AuthenticateMiddleware(srv user.Service) gin.HandlerFunc {
+// VerifySecondFactorHandler is a http handler completing a login challenge with a TOTP code or a recovery code.
+// Wrong codes count as failed logins of the challenged account and of the address
+func VerifySecondFactorHandler(srv user.LoginService, lockoutSrv user.LockoutService, auditSrv audit.Service) gin.HandlerFunc {
+	type request struct {
+		Challenge string `json:"challenge" binding:"required"`
+		Code      string `json:"code" binding:"required"`
+	}
+
+	type response struct {
+		User *user.View `json:"user"`
+		*user.Tokens
+	}
+
 	return func(c *gin.Context) {
-		var token string
-		authHeader := c.GetHeader("authorization")
-		if authHeader == "" {
-			// check if it passed as a query parameter
-			token = c.Query("authorization_token")
-			if token == "" {
-				c.AbortWithError(http.StatusUnauthorized, errors.New("authorization header was not set"))
-				return
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		// an invalid challenge has no account, it only counts against the address
+		userName, userID := "", ""
+		challenged, err := srv.ChallengedUser(c.Request.Context(), req.Challenge)
+		if err == nil {
+			userName, userID = challenged.Name, challenged.ID.Hex()
+		}
+
+		if err := lockoutSrv.CheckLogin(c.Request.Context(), userName, c.ClientIP()); err != nil {
+			if !abortIfLockedOut(c, err) {
+				abortWithError(c, http.StatusInternalServerError, err)
 			}
-		} else {
-			headerParts := strings.Split(authHeader, " ")
-			if !(len(headerParts) == 2 && headerParts[0] == "Bearer" && len(headerParts[1]) != 0) {
-				c.AbortWithError(http.StatusUnauthorized, errors.New("authorization header was not set correctly"))
+			return
+		}
+
+		u, tokens, err := srv.VerifySecondFactor(c.Request.Context(), req.Challenge, req.Code)
+		if err != nil {
+			recordAudit(c, auditSrv, audit.ActionLoginFailure, anonymousActor(c, userName), userID, err.Error())
+			if recordErr := lockoutSrv.RecordLoginFailure(c.Request.Context(), userName, c.ClientIP()); recordErr != nil {
+				abortWithError(c, http.StatusInternalServerError, recordErr)
 				return
 			}
+			abortWithError(c, http.StatusUnauthorized, err)
+			return
+		}
 
-			token = headerParts[1]
+		if err := lockoutSrv.RecordLoginSuccess(c.Request.Context(), u.Name, c.ClientIP()); err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
 		}
 
-		user, err := srv.Authenticate(c.Request.Context(), token)
-		if err != nil {
-			c.AbortWithError(http.StatusUnauthorized, err)
+		recordAudit(c, auditSrv, audit.ActionLogin, auditActor(c, u), u.ID.Hex(), "second factor")
+
+		c.JSON(http.StatusOK, &response{
+			User:   UserView(c, u),
+			Tokens: tokens,
+		})
+	}
+}
+
+// RefreshTokenHandler exchanges a refresh token for new tokens
+func RefreshTokenHandler(srv user.TokenService) gin.HandlerFunc {
+	type request struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	type response struct {
+		User *user.View `json:"user"`
+		*user.Tokens
+	}
+
+	return func(c *gin.Context) {
+		var req request
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
 			return
 		}
 
-		c.Set("currentUser", user)
+		user, tokens, err := srv.Refresh(c.Request.Context(), req.RefreshToken)
+		if err != nil {
+			abortWithError(c, http.StatusUnauthorized, err)
+			return
+		}
 
-		c.Next()
+		c.JSON(http.StatusOK, &response{
+			User:   UserView(c, user),
+			Tokens: tokens,
+		})
 	}
 }
 
-// AuthorizationMiddleware is an authorization middleware to add in the handler chain of each handler with the correct permission configuration
-func AuthorizationMiddleware(action string, resourceID string) gin.HandlerFunc {
+// LogoutHandler revokes the session of the current access token
+func LogoutHandler(srv user.TokenService, auditSrv audit.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// rebuild action and resourceID based on dynamic params
-		for _, param := range c.Params {
-			if strings.Contains(resourceID, fmt.Sprintf(":%s", param.Key)) {
-				resourceID = strings.Replace(resourceID, fmt.Sprintf(":%s", param.Key), param.Value, 1)
-			}
-			if strings.Contains(action, fmt.Sprintf(":%s", param.Key)) {
-				action = strings.Replace(action, fmt.Sprintf(":%s", param.Key), param.Value, 1)
-			}
+		token, err := extractToken(c)
+		if err != nil {
+			abortWithError(c, http.StatusUnauthorized, err)
+			return
 		}
 
-		// retrieve the current user
+		if err := srv.Logout(c.Request.Context(), token); err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		actor := auditActor(c, nil)
+		recordAudit(c, auditSrv, audit.ActionSessionRevoked, actor, actor.UserID, "")
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// LogoutEverywhereHandler revokes all the sessions of the current user
+func LogoutEverywhereHandler(srv user.TokenService, auditSrv audit.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
 		currentUser, err := GetCurrentUser(c)
 		if err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
-		// check if the user has the permission
-		if err := currentUser.Can(action, resourceID); err != nil {
-			c.AbortWithStatus(http.StatusForbidden)
+		n, err := srv.LogoutEverywhere(c.Request.Context(), currentUser.ID.Hex())
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
 			return
 		}
 
+		recordAudit(c, auditSrv, audit.ActionSessionsRevoked, auditActor(c, currentUser), currentUser.ID.Hex(), fmt.Sprintf("%d sessions", n))
+
+		c.JSON(http.StatusOK, gin.H{
+			"number_of_revoked": n,
+		})
+	}
+}
+
+// AuthenticateMiddleware is a http middleware for the authenticate service
+func AuthenticateMiddleware(srv user.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, err := extractToken(c)
+		if err != nil {
+			abortWithError(c, http.StatusUnauthorized, err)
+			return
+		}
+
+		user, err := srv.Authenticate(c.Request.Context(), token)
+		if err != nil {
+			abortWithError(c, http.StatusUnauthorized, err)
+			return
+		}
+
+		c.Set("currentUser", user)
+
 		c.Next()
 	}
 }
+
+// extractToken returns the access token passed in the authorization header or in the authorization_token query parameter
+func extractToken(c *gin.Context) (string, error) {
+	authHeader := c.GetHeader("authorization")
+	if authHeader == "" {
+		// check if it passed as a query parameter
+		token := c.Query("authorization_token")
+		if token == "" {
+			return "", errors.New("authorization header was not set")
+		}
+
+		return token, nil
+	}
+
+	headerParts := strings.Split(authHeader, " ")
+	if !(len(headerParts) == 2 && headerParts[0] == "Bearer" && len(headerParts[1]) != 0) {
+		return "", errors.New("authorization header was not set correctly")
+	}
+
+	return headerParts[1], nil
+}
+
+// AuthorizationMiddleware is an authorization middleware to add in the handler chain of each handler with the correct permission configuration
+func AuthorizationMiddleware(action string, resourceID string) gin.HandlerFunc {
+	return Authorize(Permission(action, resourceID))
+}
@@ -0,0 +1,120 @@
+package attachment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DiskStorage is an attachment storage based on the local filesystem.
+// Each attachment is stored as two files: its content and a JSON description
+type DiskStorage struct {
+	Directory string
+}
+
+// NewDiskStorage inits a new disk storage in the given directory which is created if needed
+func NewDiskStorage(directory string) (Storage, error) {
+	if err := os.MkdirAll(directory, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &DiskStorage{
+		Directory: directory,
+	}, nil
+}
+
+func (s *DiskStorage) contentPath(attachmentID string) string {
+	return filepath.Join(s.Directory, attachmentID)
+}
+
+func (s *DiskStorage) descriptionPath(attachmentID string) string {
+	return filepath.Join(s.Directory, attachmentID+".json")
+}
+
+// Save writes the content and its description in the directory
+func (s *DiskStorage) Save(ctx context.Context, filename string, contentType string, content io.Reader) (*Attachment, error) {
+	attachment := &Attachment{
+		ID:          primitive.NewObjectID().Hex(),
+		Filename:    filename,
+		ContentType: contentType,
+		CreatedAt:   time.Now(),
+	}
+
+	file, err := os.OpenFile(s.contentPath(attachment.ID), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := io.Copy(file, content)
+	closingErr := file.Close()
+	if err == nil {
+		err = closingErr
+	}
+	if err != nil {
+		os.Remove(s.contentPath(attachment.ID))
+		return nil, err
+	}
+	attachment.Size = size
+
+	description, err := json.Marshal(attachment)
+	if err != nil {
+		os.Remove(s.contentPath(attachment.ID))
+		return nil, err
+	}
+
+	if err := os.WriteFile(s.descriptionPath(attachment.ID), description, 0o640); err != nil {
+		os.Remove(s.contentPath(attachment.ID))
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// Open returns the attachment description along with a reader on its content
+func (s *DiskStorage) Open(ctx context.Context, attachmentID string) (*Attachment, io.ReadCloser, error) {
+	if !primitive.IsValidObjectID(attachmentID) {
+		return nil, nil, common.NewError(common.ErrValidation, "%s is not a valid attachment id", attachmentID)
+	}
+
+	description, err := os.ReadFile(s.descriptionPath(attachmentID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var attachment Attachment
+	if err := json.Unmarshal(description, &attachment); err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(s.contentPath(attachmentID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &attachment, file, nil
+}
+
+// Delete removes the content and the description of the attachments. Attachments that do not exist are ignored
+func (s *DiskStorage) Delete(ctx context.Context, attachmentIDs ...string) error {
+	for _, attachmentID := range attachmentIDs {
+		if !primitive.IsValidObjectID(attachmentID) {
+			return common.NewError(common.ErrValidation, "%s is not a valid attachment id", attachmentID)
+		}
+
+		for _, path := range []string{s.contentPath(attachmentID), s.descriptionPath(attachmentID)} {
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
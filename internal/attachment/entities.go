@@ -0,0 +1,51 @@
+package attachment
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+)
+
+var (
+	// ErrTooLarge is returned when an attachment exceeds the maximum size
+	ErrTooLarge = common.NewError(common.ErrValidation, "attachment is too large")
+
+	// ErrTypeNotAllowed is returned when the content type of an attachment is not allowed
+	ErrTypeNotAllowed = common.NewError(common.ErrValidation, "attachment type is not allowed")
+)
+
+// Attachment describes a file attached to an item
+type Attachment struct {
+	ID          string    `bson:"id" json:"id"`
+	Filename    string    `bson:"filename" json:"filename"`
+	ContentType string    `bson:"content_type" json:"content_type"`
+	Size        int64     `bson:"size" json:"size"`
+	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
+}
+
+// Limits defines the constraints an attachment must satisfy before being stored
+type Limits struct {
+	MaxSize      int64
+	AllowedTypes []string
+}
+
+// Check returns an error if the size or the content type is not within the limits.
+// A limit set to its zero value is not checked
+func (l *Limits) Check(size int64, contentType string) error {
+	if l.MaxSize > 0 && size > l.MaxSize {
+		return fmt.Errorf("%w : %d bytes exceeds %d bytes", ErrTooLarge, size, l.MaxSize)
+	}
+
+	if len(l.AllowedTypes) == 0 {
+		return nil
+	}
+
+	for _, allowed := range l.AllowedTypes {
+		if allowed == contentType {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w : %s", ErrTypeNotAllowed, contentType)
+}
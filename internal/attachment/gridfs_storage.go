@@ -0,0 +1,120 @@
+package attachment
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GridFSStorage is an attachment storage based on mongodb GridFS
+type GridFSStorage struct {
+	Bucket *gridfs.Bucket
+}
+
+// NewGridFSStorage inits a new GridFS storage using the given bucket name
+func NewGridFSStorage(db *mongo.Database, bucketName string) (Storage, error) {
+	bucket, err := gridfs.NewBucket(db, options.GridFSBucket().SetName(bucketName))
+	if err != nil {
+		return nil, err
+	}
+
+	return &GridFSStorage{
+		Bucket: bucket,
+	}, nil
+}
+
+type gridFSMetadata struct {
+	ContentType string `bson:"content_type"`
+}
+
+// Save uploads the content into the bucket
+func (s *GridFSStorage) Save(ctx context.Context, filename string, contentType string, content io.Reader) (*Attachment, error) {
+	id := primitive.NewObjectID()
+
+	stream, err := s.Bucket.OpenUploadStreamWithID(id, filename, options.GridFSUpload().SetMetadata(&gridFSMetadata{
+		ContentType: contentType,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		stream.SetWriteDeadline(deadline)
+	}
+
+	size, err := io.Copy(stream, content)
+	if err != nil {
+		stream.Abort()
+		return nil, err
+	}
+
+	if err := stream.Close(); err != nil {
+		return nil, err
+	}
+
+	return &Attachment{
+		ID:          id.Hex(),
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// Open returns the attachment description along with a reader on its content
+func (s *GridFSStorage) Open(ctx context.Context, attachmentID string) (*Attachment, io.ReadCloser, error) {
+	objectID, err := common.ObjectIDFromHex(attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stream, err := s.Bucket.OpenDownloadStream(objectID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		stream.SetReadDeadline(deadline)
+	}
+
+	file := stream.GetFile()
+	var metadata gridFSMetadata
+	if file.Metadata != nil {
+		if err := bson.Unmarshal(file.Metadata, &metadata); err != nil {
+			stream.Close()
+			return nil, nil, err
+		}
+	}
+
+	return &Attachment{
+		ID:          attachmentID,
+		Filename:    file.Name,
+		ContentType: metadata.ContentType,
+		Size:        file.Length,
+		CreatedAt:   file.UploadDate,
+	}, stream, nil
+}
+
+// Delete removes the files and their chunks from the bucket. Files that do not exist are ignored
+func (s *GridFSStorage) Delete(ctx context.Context, attachmentIDs ...string) error {
+	for _, attachmentID := range attachmentIDs {
+		objectID, err := common.ObjectIDFromHex(attachmentID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.Bucket.Delete(objectID); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
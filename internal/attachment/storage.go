@@ -0,0 +1,28 @@
+package attachment
+
+import (
+	"context"
+	"io"
+)
+
+// Saver is a single method interface for storing the content of an attachment
+type Saver interface {
+	Save(ctx context.Context, filename string, contentType string, content io.Reader) (*Attachment, error)
+}
+
+// Opener is a single method interface for reading an attachment. The caller must close the returned reader
+type Opener interface {
+	Open(ctx context.Context, attachmentID string) (*Attachment, io.ReadCloser, error)
+}
+
+// Deleter is a single method interface for deleting attachments
+type Deleter interface {
+	Delete(ctx context.Context, attachmentIDs ...string) error
+}
+
+// Storage is a wrapper around all the single method interfaces defining an attachment backend
+type Storage interface {
+	Saver
+	Opener
+	Deleter
+}
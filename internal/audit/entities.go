@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// ActionLogin is recorded when a user logs in, with a password, a second factor or an identity provider
+	ActionLogin = "login"
+	// ActionLoginFailure is recorded when a login is refused
+	ActionLoginFailure = "login_failure"
+	// ActionPermissionsGranted is recorded when permissions are added to a user
+	ActionPermissionsGranted = "permissions_granted"
+	// ActionPermissionsRevoked is recorded when permissions are removed from a user
+	ActionPermissionsRevoked = "permissions_revoked"
+	// ActionRolesGranted is recorded when roles are given to a user
+	ActionRolesGranted = "roles_granted"
+	// ActionRolesRevoked is recorded when roles are taken from a user
+	ActionRolesRevoked = "roles_revoked"
+	// ActionRoleCreated is recorded when a role is created, the target is the name of the role
+	ActionRoleCreated = "role_created"
+	// ActionRolePermissionsChanged is recorded when the permissions of a role are replaced, the target is the name of the role
+	ActionRolePermissionsChanged = "role_permissions_changed"
+	// ActionRoleDeleted is recorded when a role is deleted, the target is the name of the role
+	ActionRoleDeleted = "role_deleted"
+	// ActionPasswordChanged is recorded when a user changes a password knowing the current one
+	ActionPasswordChanged = "password_changed"
+	// ActionPasswordReset is recorded when a password is set from a reset token
+	ActionPasswordReset = "password_reset"
+	// ActionUserDeleted is recorded when a user is deleted along with their data
+	ActionUserDeleted = "user_deleted"
+	// ActionSessionRevoked is recorded when a user logs out
+	ActionSessionRevoked = "session_revoked"
+	// ActionSessionsRevoked is recorded when a user logs out everywhere
+	ActionSessionsRevoked = "sessions_revoked"
+	// ActionAccessTokenRevoked is recorded when a personal access token is revoked
+	ActionAccessTokenRevoked = "access_token_revoked"
+)
+
+const (
+	// defaultPageSize is the number of events per page when the query does not set it
+	defaultPageSize = 50
+	// maxPageSize is the maximum number of events per page
+	maxPageSize = 500
+)
+
+// Actor is who did the action and from where. The user is unknown when the action was refused before authenticating them, like a failed login
+type Actor struct {
+	UserID    string `bson:"user_id,omitempty" json:"user_id,omitempty"`
+	Name      string `bson:"name,omitempty" json:"name,omitempty"`
+	IP        string `bson:"ip" json:"ip"`
+	UserAgent string `bson:"user_agent" json:"user_agent"`
+}
+
+// Event records a security relevant action. Events are never updated nor deleted
+type Event struct {
+	common.BaseModel `bson:",inline"`
+	Action           string `bson:"action" json:"action"`
+	Actor            *Actor `bson:"actor" json:"actor"`
+	// TargetID is the id of the user or the token, or the name of the role, the action was done on
+	TargetID string `bson:"target_id,omitempty" json:"target_id,omitempty"`
+	Details  string `bson:"details,omitempty" json:"details,omitempty"`
+}
+
+// NewEvent is an Event constructor
+func NewEvent(action string, actor *Actor, targetID string, details string) *Event {
+	return &Event{
+		BaseModel: common.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+		Action:   action,
+		Actor:    actor,
+		TargetID: targetID,
+		Details:  details,
+	}
+}
+
+// Query selects events. The zero value selects every event, and each set field narrows the selection
+type Query struct {
+	Action string
+	// ActorID selects the events done by the user
+	ActorID string
+	// TargetID selects the events done on the user, the token or the role
+	TargetID string
+	// Since and Until bound the creation time of the events, both inclusive. Zero values do not bound it
+	Since time.Time
+	Until time.Time
+	// Page starts at 1, and PageSize is bounded by maxPageSize. They are ignored by exports
+	Page     int64
+	PageSize int64
+}
+
+// normalize bounds the page and the page size
+func (q *Query) normalize() {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 {
+		q.PageSize = defaultPageSize
+	}
+	if q.PageSize > maxPageSize {
+		q.PageSize = maxPageSize
+	}
+}
+
+// skip returns the number of events before the page
+func (q *Query) skip() int64 {
+	return (q.Page - 1) * q.PageSize
+}
+
+// matches returns true if the event is selected by the query
+func (q *Query) matches(e *Event) bool {
+	if q.Action != "" && e.Action != q.Action {
+		return false
+	}
+
+	if q.ActorID != "" && (e.Actor == nil || e.Actor.UserID != q.ActorID) {
+		return false
+	}
+
+	if q.TargetID != "" && e.TargetID != q.TargetID {
+		return false
+	}
+
+	if !q.Since.IsZero() && e.CreatedAt.Before(q.Since) {
+		return false
+	}
+
+	return q.Until.IsZero() || !e.CreatedAt.After(q.Until)
+}
+
+// Page is a page of the events selected by a query, most recent first
+type Page struct {
+	Events   []*Event `json:"events"`
+	Total    int64    `json:"total"`
+	Page     int64    `json:"page"`
+	PageSize int64    `json:"page_size"`
+}
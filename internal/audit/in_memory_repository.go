@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"context"
+	"sync"
+)
+
+// InMemoryRepository is an in-memory audit trail
+type InMemoryRepository struct {
+	mu     sync.Mutex
+	events []*Event
+}
+
+// NewInMemoryRepository is a constructor of InMemoryRepository
+func NewInMemoryRepository() Repository {
+	return &InMemoryRepository{
+		events: []*Event{},
+	}
+}
+
+// RecordEvent appends the event
+func (r *InMemoryRepository) RecordEvent(ctx context.Context, event *Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+
+	return nil
+}
+
+// FindEvents retrieves a page of the selected events, most recent first, and counts them all
+func (r *InMemoryRepository) FindEvents(ctx context.Context, query *Query) ([]*Event, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := []*Event{}
+	total := int64(0)
+	for i := len(r.events) - 1; i >= 0; i-- {
+		if !query.matches(r.events[i]) {
+			continue
+		}
+		if total >= query.skip() && int64(len(events)) < query.PageSize {
+			events = append(events, r.events[i])
+		}
+		total++
+	}
+
+	return events, total, nil
+}
+
+// StreamEvents calls fn on the selected events, oldest first
+func (r *InMemoryRepository) StreamEvents(ctx context.Context, query *Query, fn func(*Event) error) error {
+	r.mu.Lock()
+	selected := []*Event{}
+	for _, event := range r.events {
+		if query.matches(event) {
+			selected = append(selected, event)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, event := range selected {
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package audit
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDBRepository contains all the methods to interact with the audit events collection
+type MongoDBRepository struct {
+	EventsCollection *mongo.Collection
+}
+
+// NewMongoDBRepository is a constructor for MongoDBRepository
+func NewMongoDBRepository(coll *mongo.Collection) Repository {
+	return &MongoDBRepository{
+		EventsCollection: coll,
+	}
+}
+
+// RecordEvent inserts the event
+func (r *MongoDBRepository) RecordEvent(ctx context.Context, event *Event) error {
+	_, err := r.EventsCollection.InsertOne(ctx, event)
+
+	return err
+}
+
+// FindEvents retrieves a page of the selected events, most recent first, and counts them all
+func (r *MongoDBRepository) FindEvents(ctx context.Context, query *Query) ([]*Event, int64, error) {
+	filter := eventsFilter(query)
+
+	total, err := r.EventsCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	events := []*Event{}
+	cursor, err := r.EventsCollection.Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).SetSkip(query.skip()).SetLimit(query.PageSize),
+	)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, -1, err
+	}
+
+	return events, total, nil
+}
+
+// StreamEvents calls fn on the selected events, oldest first, decoding them one at a time
+func (r *MongoDBRepository) StreamEvents(ctx context.Context, query *Query, fn func(*Event) error) error {
+	cursor, err := r.EventsCollection.Find(
+		ctx,
+		eventsFilter(query),
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var event Event
+		if err := cursor.Decode(&event); err != nil {
+			return err
+		}
+		if err := fn(&event); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// eventsFilter translates the query to a mongodb filter
+func eventsFilter(query *Query) bson.M {
+	filter := bson.M{}
+	if query.Action != "" {
+		filter["action"] = query.Action
+	}
+	if query.ActorID != "" {
+		filter["actor.user_id"] = query.ActorID
+	}
+	if query.TargetID != "" {
+		filter["target_id"] = query.TargetID
+	}
+
+	createdAt := bson.M{}
+	if !query.Since.IsZero() {
+		createdAt["$gte"] = query.Since
+	}
+	if !query.Until.IsZero() {
+		createdAt["$lte"] = query.Until
+	}
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+
+	return filter
+}
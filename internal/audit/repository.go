@@ -0,0 +1,27 @@
+package audit
+
+import "context"
+
+// Recorder is a single method interface for appending an event to the audit trail
+type Recorder interface {
+	RecordEvent(ctx context.Context, event *Event) error
+}
+
+// Finder is a single method interface for listing a page of the events selected by a query, most recent first, along with their total number
+type Finder interface {
+	FindEvents(ctx context.Context, query *Query) ([]*Event, int64, error)
+}
+
+// Streamer is a single method interface for going through all the events selected by a query, oldest first, without loading them at once.
+// It stops at the first error returned by fn
+type Streamer interface {
+	StreamEvents(ctx context.Context, query *Query, fn func(*Event) error) error
+}
+
+// Repository is a wrapper around all the single method interfaces defining the audit trail storage.
+// The trail is append-only, so there is no way to update or delete an event
+type Repository interface {
+	Recorder
+	Finder
+	Streamer
+}
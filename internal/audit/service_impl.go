@@ -0,0 +1,51 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// ServiceImpl is the concrete implementation of the audit service interface
+type ServiceImpl struct {
+	repo Repository
+}
+
+// NewService inits a new audit service
+func NewService(repo Repository) Service {
+	return &ServiceImpl{
+		repo: repo,
+	}
+}
+
+// Record directly calls the repository
+func (s *ServiceImpl) Record(ctx context.Context, event *Event) error {
+	return s.repo.RecordEvent(ctx, event)
+}
+
+// FindEvents returns a page of the events selected by the query, most recent first
+func (s *ServiceImpl) FindEvents(ctx context.Context, query *Query) (*Page, error) {
+	query.normalize()
+
+	events, total, err := s.repo.FindEvents(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Page{
+		Events:   events,
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}, nil
+}
+
+// Export writes the selected events as JSON Lines, one event per line, oldest first.
+// The events are streamed, so a failure can happen after some lines were written
+func (s *ServiceImpl) Export(ctx context.Context, query *Query, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+
+	return s.repo.StreamEvents(ctx, query, func(event *Event) error {
+		return encoder.Encode(event)
+	})
+}
@@ -0,0 +1,14 @@
+package audit
+
+import (
+	"context"
+	"io"
+)
+
+// Service is the interface of the audit trail
+type Service interface {
+	Record(ctx context.Context, event *Event) error
+	FindEvents(ctx context.Context, query *Query) (*Page, error)
+	// Export writes the selected events as JSON Lines, oldest first
+	Export(ctx context.Context, query *Query, w io.Writer) error
+}
@@ -0,0 +1,81 @@
+package audit_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindEvents(t *testing.T) {
+	ctx := context.Background()
+	srv := audit.NewService(audit.NewInMemoryRepository())
+
+	admin := &audit.Actor{UserID: "admin", Name: "admin", IP: "10.0.0.1", UserAgent: "curl"}
+	assert.NoError(t, srv.Record(ctx, audit.NewEvent(audit.ActionLogin, admin, "admin", "password")))
+	assert.NoError(t, srv.Record(ctx, audit.NewEvent(audit.ActionPermissionsGranted, admin, "alice", "write on users")))
+	assert.NoError(t, srv.Record(ctx, audit.NewEvent(audit.ActionPermissionsRevoked, admin, "bob", "read on list-1")))
+	assert.NoError(t, srv.Record(ctx, audit.NewEvent(audit.ActionLoginFailure, &audit.Actor{Name: "mallory", IP: "10.0.0.2"}, "", "wrong password")))
+
+	// the most recent events come first
+	page, err := srv.FindEvents(ctx, &audit.Query{PageSize: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), page.Total)
+	assert.Len(t, page.Events, 3)
+	assert.Equal(t, audit.ActionLoginFailure, page.Events[0].Action)
+	page, err = srv.FindEvents(ctx, &audit.Query{Page: 2, PageSize: 3})
+	assert.NoError(t, err)
+	assert.Len(t, page.Events, 1)
+	assert.Equal(t, audit.ActionLogin, page.Events[0].Action)
+
+	page, err = srv.FindEvents(ctx, &audit.Query{ActorID: "admin"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), page.Total)
+	assert.Equal(t, int64(1), page.Page)
+	assert.Equal(t, int64(50), page.PageSize)
+
+	page, err = srv.FindEvents(ctx, &audit.Query{Action: audit.ActionPermissionsGranted, TargetID: "alice"})
+	assert.NoError(t, err)
+	assert.Len(t, page.Events, 1)
+	assert.Equal(t, "write on users", page.Events[0].Details)
+
+	page, err = srv.FindEvents(ctx, &audit.Query{Since: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Events)
+	page, err = srv.FindEvents(ctx, &audit.Query{Until: time.Now().Add(-time.Hour)})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Events)
+}
+
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+	srv := audit.NewService(audit.NewInMemoryRepository())
+
+	actor := &audit.Actor{UserID: "alice", Name: "alice", IP: "10.0.0.1", UserAgent: "firefox"}
+	assert.NoError(t, srv.Record(ctx, audit.NewEvent(audit.ActionLogin, actor, "alice", "password")))
+	assert.NoError(t, srv.Record(ctx, audit.NewEvent(audit.ActionAccessTokenRevoked, actor, "token", "")))
+	assert.NoError(t, srv.Record(ctx, audit.NewEvent(audit.ActionSessionsRevoked, actor, "alice", "2 sessions")))
+
+	var buffer bytes.Buffer
+	assert.NoError(t, srv.Export(ctx, &audit.Query{ActorID: "alice"}, &buffer))
+
+	// one event per line, oldest first, regardless of the pagination
+	actions := []string{}
+	scanner := bufio.NewScanner(&buffer)
+	for scanner.Scan() {
+		var event audit.Event
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		assert.Equal(t, "firefox", event.Actor.UserAgent)
+		actions = append(actions, event.Action)
+	}
+	assert.Equal(t, []string{audit.ActionLogin, audit.ActionAccessTokenRevoked, audit.ActionSessionsRevoked}, actions)
+
+	buffer.Reset()
+	assert.NoError(t, srv.Export(ctx, &audit.Query{Action: audit.ActionUserDeleted}, &buffer))
+	assert.Zero(t, buffer.Len())
+}
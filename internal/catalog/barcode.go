@@ -0,0 +1,53 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+)
+
+// ErrInvalidBarcode is returned when a code is neither a valid EAN-13 nor a valid UPC-A
+var ErrInvalidBarcode = common.NewError(common.ErrValidation, "invalid barcode")
+
+// NormalizeBarcode validates an EAN-13 or UPC-A code and returns it as an EAN-13.
+// A UPC-A is an EAN-13 starting with a zero so both share the same checksum.
+// Spaces and dashes are ignored
+func NormalizeBarcode(code string) (string, error) {
+	code = strings.NewReplacer(" ", "", "-", "").Replace(code)
+
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("%w : %s contains non digit characters", ErrInvalidBarcode, code)
+		}
+	}
+
+	switch len(code) {
+	case 12:
+		code = "0" + code
+	case 13:
+	default:
+		return "", fmt.Errorf("%w : %s must have 12 (UPC-A) or 13 (EAN-13) digits", ErrInvalidBarcode, code)
+	}
+
+	if checkDigit(code[:12]) != code[12] {
+		return "", fmt.Errorf("%w : wrong check digit for %s", ErrInvalidBarcode, code)
+	}
+
+	return code, nil
+}
+
+// checkDigit computes the EAN-13 check digit of the first 12 digits.
+// Digits are weighted 1 and 3 alternately starting from the left
+func checkDigit(digits string) byte {
+	sum := 0
+	for i, r := range digits {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(r-'0') * weight
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
@@ -0,0 +1,82 @@
+package catalog_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/catalog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeBarcode(t *testing.T) {
+	cases := []struct {
+		code     string
+		expected string
+		valid    bool
+	}{
+		{code: "3017620422003", expected: "3017620422003", valid: true},
+		{code: "5449000000996", expected: "5449000000996", valid: true},
+		{code: "036000291452", expected: "0036000291452", valid: true},
+		{code: "0 36000 29145 2", expected: "0036000291452", valid: true},
+		{code: "3017620422004", valid: false},
+		{code: "036000291453", valid: false},
+		{code: "30176204220", valid: false},
+		{code: "301762042200A", valid: false},
+		{code: "", valid: false},
+	}
+
+	for _, c := range cases {
+		normalized, err := catalog.NormalizeBarcode(c.code)
+		if !c.valid {
+			assert.ErrorIs(t, err, catalog.ErrInvalidBarcode, c.code)
+			continue
+		}
+
+		assert.NoError(t, err, c.code)
+		assert.Equal(t, c.expected, normalized)
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	ctx := context.Background()
+	repo := catalog.NewInMemoryRepository()
+
+	file := strings.Join([]string{
+		"barcode,name,quantity,brand",
+		"3017620422003,Nutella,400g,Ferrero",
+		"036000291452,Tissues,1,Kleenex",
+		"3017620422004,Wrong checksum,1,",
+		"5449000000996,,33cl,Coca-Cola",
+	}, "\n")
+
+	report, err := catalog.Import(ctx, repo, strings.NewReader(file), catalog.FormatCSV)
+	assert.NoError(t, err)
+	assert.Equal(t, &catalog.ImportReport{Read: 4, Imported: 2, Skipped: 2}, report)
+
+	product, err := repo.FindByBarcode(ctx, "0036000291452")
+	assert.NoError(t, err)
+	assert.Equal(t, "Tissues", product.Name)
+	assert.Equal(t, "Kleenex", product.Brand)
+}
+
+func TestImportOpenFoodFacts(t *testing.T) {
+	ctx := context.Background()
+	repo := catalog.NewInMemoryRepository()
+
+	file := strings.Join([]string{
+		"code\turl\tproduct_name\tquantity\tbrands",
+		"3017620422003\thttps://example.org\tNutella\t400 g\tFerrero",
+	}, "\n")
+
+	report, err := catalog.Import(ctx, repo, strings.NewReader(file), catalog.FormatOpenFoodFacts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), report.Imported)
+
+	product, err := repo.FindByBarcode(ctx, "3017620422003")
+	assert.NoError(t, err)
+	assert.Equal(t, "400 g", product.DefaultQuantity)
+
+	_, err = catalog.Import(ctx, repo, strings.NewReader("name\nNutella"), catalog.FormatCSV)
+	assert.Error(t, err)
+}
@@ -0,0 +1,32 @@
+package catalog
+
+import (
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Product is a product of the catalog identified by its barcode
+type Product struct {
+	common.BaseModel `bson:",inline"`
+	Barcode          string `bson:"barcode" json:"barcode"`
+	Name             string `bson:"name" json:"name"`
+	Brand            string `bson:"brand" json:"brand"`
+	DefaultQuantity  string `bson:"default_quantity" json:"default_quantity"`
+}
+
+// NewProduct is a Product constructor
+func NewProduct(barcode string, name string, brand string, defaultQuantity string) *Product {
+	return &Product{
+		BaseModel: common.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+		Barcode:         barcode,
+		Name:            name,
+		Brand:           brand,
+		DefaultQuantity: defaultQuantity,
+	}
+}
@@ -0,0 +1,221 @@
+package catalog
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+)
+
+// Format is the format of a catalog file
+type Format string
+
+const (
+	// FormatCSV is a comma separated file with a header containing at least the barcode and name columns.
+	// The quantity and brand columns are optional
+	FormatCSV Format = "csv"
+
+	// FormatOpenFoodFacts is the tab separated export of Open Food Facts
+	FormatOpenFoodFacts Format = "off"
+
+	// FormatOpenFoodFactsJSONL is the JSON Lines dump of Open Food Facts
+	FormatOpenFoodFactsJSONL Format = "off-jsonl"
+)
+
+// importBatchSize is the number of products upserted at once
+const importBatchSize = 1000
+
+// ImportReport sums up an import
+type ImportReport struct {
+	Read     int64 `json:"read"`
+	Imported int64 `json:"imported"`
+	Skipped  int64 `json:"skipped"`
+}
+
+// columns maps the fields of a product to the column names of a format
+type columns struct {
+	barcode  string
+	name     string
+	quantity string
+	brand    string
+}
+
+var csvColumns = map[Format]columns{
+	FormatCSV: {
+		barcode:  "barcode",
+		name:     "name",
+		quantity: "quantity",
+		brand:    "brand",
+	},
+	FormatOpenFoodFacts: {
+		barcode:  "code",
+		name:     "product_name",
+		quantity: "quantity",
+		brand:    "brands",
+	},
+}
+
+// productReader returns the next product of a file. It returns io.EOF when the file is exhausted
+type productReader func() (*Product, error)
+
+// Import reads the products of a catalog file and upserts them by batches.
+// Products with an invalid barcode or without name are skipped
+func Import(ctx context.Context, repo Upserter, r io.Reader, format Format) (*ImportReport, error) {
+	var next productReader
+	var err error
+	switch format {
+	case FormatCSV, FormatOpenFoodFacts:
+		next, err = newCSVReader(r, format)
+	case FormatOpenFoodFactsJSONL:
+		next = newJSONLReader(r)
+	default:
+		err = common.NewError(common.ErrValidation, "unknown catalog format %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{}
+	batch := make([]*Product, 0, importBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if _, err := repo.Upsert(ctx, batch...); err != nil {
+			return err
+		}
+		report.Imported += int64(len(batch))
+		batch = batch[:0]
+
+		return nil
+	}
+
+	for {
+		product, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return report, err
+		}
+		report.Read++
+
+		barcode, err := NormalizeBarcode(product.Barcode)
+		if err != nil || product.Name == "" {
+			report.Skipped++
+			continue
+		}
+		product.Barcode = barcode
+
+		batch = append(batch, product)
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	return report, flush()
+}
+
+func newCSVReader(r io.Reader, format Format) (productReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+	if format == FormatOpenFoodFacts {
+		reader.Comma = '\t'
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the header : %w", err)
+	}
+
+	indexes := make(map[string]int, len(header))
+	for i, column := range header {
+		indexes[strings.TrimSpace(column)] = i
+	}
+
+	cols := csvColumns[format]
+	for _, required := range []string{cols.barcode, cols.name} {
+		if _, exists := indexes[required]; !exists {
+			return nil, common.NewError(common.ErrValidation, "the %s column is missing", required)
+		}
+	}
+
+	field := func(record []string, column string) string {
+		i, exists := indexes[column]
+		if !exists || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	return func() (*Product, error) {
+		record, err := reader.Read()
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			// a malformed row is turned into a product that will be skipped
+			return &Product{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return NewProduct(
+			field(record, cols.barcode),
+			field(record, cols.name),
+			field(record, cols.brand),
+			field(record, cols.quantity),
+		), nil
+	}, nil
+}
+
+func newJSONLReader(r io.Reader) productReader {
+	type offProduct struct {
+		Code        string `json:"code"`
+		ProductName string `json:"product_name"`
+		Quantity    string `json:"quantity"`
+		Brands      string `json:"brands"`
+	}
+
+	scanner := bufio.NewScanner(r)
+	// products of the dump can be much larger than the default token size
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	return func() (*Product, error) {
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+
+			var p offProduct
+			if err := json.Unmarshal([]byte(line), &p); err != nil {
+				// a malformed line is turned into a product that will be skipped
+				return &Product{}, nil
+			}
+
+			return NewProduct(
+				strings.TrimSpace(p.Code),
+				strings.TrimSpace(p.ProductName),
+				strings.TrimSpace(p.Brands),
+				strings.TrimSpace(p.Quantity),
+			), nil
+		}
+
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+
+		return nil, io.EOF
+	}
+}
@@ -0,0 +1,47 @@
+package catalog
+
+import (
+	"context"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+)
+
+// InMemoryRepository is an in-memory catalog repository
+type InMemoryRepository struct {
+	products map[string]*Product
+}
+
+// NewInMemoryRepository inits a new in-memory catalog repository
+func NewInMemoryRepository() Repository {
+	return &InMemoryRepository{
+		products: make(map[string]*Product),
+	}
+}
+
+// FindByBarcode returns the product with the given barcode
+func (r *InMemoryRepository) FindByBarcode(ctx context.Context, barcode string) (*Product, error) {
+	product, exists := r.products[barcode]
+	if !exists {
+		return nil, common.NewError(common.ErrNotFound, "there is no product with barcode %v", barcode)
+	}
+
+	return product, nil
+}
+
+// Upsert inserts the products. A product whose barcode is already known keeps its id and creation date
+func (r *InMemoryRepository) Upsert(ctx context.Context, products ...*Product) (int64, error) {
+	for _, product := range products {
+		if existing, exists := r.products[product.Barcode]; exists {
+			existing.Name = product.Name
+			existing.Brand = product.Brand
+			existing.DefaultQuantity = product.DefaultQuantity
+			existing.UpdatedAt = time.Now()
+			continue
+		}
+
+		r.products[product.Barcode] = product
+	}
+
+	return int64(len(products)), nil
+}
@@ -0,0 +1,66 @@
+package catalog
+
+import (
+	"context"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDBRepository is a catalog repository based on mongodb
+type MongoDBRepository struct {
+	ProductsCollection *mongo.Collection
+}
+
+// NewMongoDBRepository inits a new mongodb catalog repository
+func NewMongoDBRepository(collection *mongo.Collection) Repository {
+	return &MongoDBRepository{
+		ProductsCollection: collection,
+	}
+}
+
+// FindByBarcode returns the product with the given barcode
+func (r *MongoDBRepository) FindByBarcode(ctx context.Context, barcode string) (*Product, error) {
+	var product Product
+	if err := r.ProductsCollection.FindOne(ctx, bson.M{"barcode": barcode}).Decode(&product); err != nil {
+		return nil, common.MongoError(err, "product with barcode %v", barcode)
+	}
+
+	return &product, nil
+}
+
+// Upsert inserts the products in a single bulk write. A product whose barcode is already known keeps its id and creation date
+func (r *MongoDBRepository) Upsert(ctx context.Context, products ...*Product) (int64, error) {
+	if len(products) == 0 {
+		return 0, nil
+	}
+
+	models := make([]mongo.WriteModel, len(products))
+	for i, product := range products {
+		models[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"barcode": product.Barcode}).
+			SetUpdate(bson.D{
+				{Key: "$set", Value: bson.D{
+					{Key: "name", Value: product.Name},
+					{Key: "brand", Value: product.Brand},
+					{Key: "default_quantity", Value: product.DefaultQuantity},
+					{Key: "updated_at", Value: time.Now()},
+				}},
+				{Key: "$setOnInsert", Value: bson.D{
+					{Key: "_id", Value: product.ID},
+					{Key: "created_at", Value: product.CreatedAt},
+				}},
+			}).
+			SetUpsert(true)
+	}
+
+	result, err := r.ProductsCollection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return -1, err
+	}
+
+	return result.UpsertedCount + result.ModifiedCount, nil
+}
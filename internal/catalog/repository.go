@@ -0,0 +1,19 @@
+package catalog
+
+import "context"
+
+// FinderByBarcode is a single method interface for finding a product by its barcode
+type FinderByBarcode interface {
+	FindByBarcode(ctx context.Context, barcode string) (*Product, error)
+}
+
+// Upserter is a single method interface for inserting products or replacing the ones with the same barcode
+type Upserter interface {
+	Upsert(ctx context.Context, products ...*Product) (int64, error)
+}
+
+// Repository defines all possible actions on the catalog database
+type Repository interface {
+	FinderByBarcode
+	Upserter
+}
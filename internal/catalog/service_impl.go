@@ -0,0 +1,33 @@
+package catalog
+
+import (
+	"context"
+	"io"
+)
+
+// ServiceImpl is the concrete implementation of the catalog service interface
+type ServiceImpl struct {
+	repo Repository
+}
+
+// NewService inits a new catalog service
+func NewService(repo Repository) Service {
+	return &ServiceImpl{
+		repo: repo,
+	}
+}
+
+// Lookup validates the code and returns the product it identifies
+func (s *ServiceImpl) Lookup(ctx context.Context, code string) (*Product, error) {
+	barcode, err := NormalizeBarcode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindByBarcode(ctx, barcode)
+}
+
+// Import reads a catalog file and upserts its products
+func (s *ServiceImpl) Import(ctx context.Context, r io.Reader, format Format) (*ImportReport, error) {
+	return Import(ctx, s.repo, r, format)
+}
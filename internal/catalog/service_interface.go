@@ -0,0 +1,13 @@
+package catalog
+
+import (
+	"context"
+	"io"
+)
+
+// Service defines the catalog service
+type Service interface {
+	Lookup(ctx context.Context, code string) (*Product, error)
+
+	Import(ctx context.Context, r io.Reader, format Format) (*ImportReport, error)
+}
@@ -0,0 +1,62 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The kinds of the domain errors. The errors of the repositories and the services wrap one of them, if any,
+// so that errors.Is tells what went wrong without knowing the storage, like errors.Is(err, ErrNotFound)
+var (
+	// ErrNotFound is the kind of the errors about a missing resource
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is the kind of the errors about a resource clashing with an existing one or in the wrong state
+	ErrConflict = errors.New("conflict")
+	// ErrValidation is the kind of the errors about an invalid input
+	ErrValidation = errors.New("invalid input")
+	// ErrForbidden is the kind of the errors about an action the user is not allowed to do
+	ErrForbidden = errors.New("forbidden")
+)
+
+// kinds are the kinds of the domain errors
+var kinds = []error{ErrNotFound, ErrConflict, ErrValidation, ErrForbidden}
+
+// Error is a domain error of a kind. Its message does not mention the kind, and it still unwraps to the error it wraps, if any
+type Error struct {
+	kind error
+	err  error
+}
+
+// NewError returns an error of the kind. The format and args are those of fmt.Errorf, so %w wraps the cause of the error
+func NewError(kind error, format string, args ...interface{}) error {
+	return &Error{
+		kind: kind,
+		err:  fmt.Errorf(format, args...),
+	}
+}
+
+// Error returns the error message
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+// Is makes errors.Is match the kind of the error
+func (e *Error) Is(target error) bool {
+	return target == e.kind
+}
+
+// Unwrap returns the cause of the error, if it was given
+func (e *Error) Unwrap() error {
+	return errors.Unwrap(e.err)
+}
+
+// KindOf returns the kind of a domain error, or nil if the error has none
+func KindOf(err error) error {
+	for _, kind := range kinds {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+
+	return nil
+}
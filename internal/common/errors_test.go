@@ -0,0 +1,36 @@
+package common_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestErrorKinds(t *testing.T) {
+	cause := errors.New("cause")
+	err := common.NewError(common.ErrConflict, "role %s already exists: %w", "admin", cause)
+	assert.Equal(t, "role admin already exists: cause", err.Error())
+	assert.ErrorIs(t, err, common.ErrConflict)
+	assert.ErrorIs(t, err, cause)
+	assert.NotErrorIs(t, err, common.ErrNotFound)
+
+	// the kind survives the wrapping, and sentinels of a kind stay distinct
+	sentinel := common.NewError(common.ErrValidation, "invalid price")
+	wrapped := fmt.Errorf("%w for product %q", sentinel, "milk")
+	assert.ErrorIs(t, wrapped, sentinel)
+	assert.Equal(t, common.ErrValidation, common.KindOf(wrapped))
+	assert.Nil(t, common.KindOf(cause))
+
+	_, err = common.ObjectIDFromHex("not an id")
+	assert.ErrorIs(t, err, common.ErrValidation)
+
+	err = common.MongoError(mongo.ErrNoDocuments, "list %v", "abc")
+	assert.ErrorIs(t, err, common.ErrNotFound)
+	assert.Equal(t, "list abc not found", err.Error())
+	assert.Equal(t, cause, common.MongoError(cause, "list %v", "abc"))
+	assert.NoError(t, common.MongoError(nil, "list %v", "abc"))
+}
@@ -0,0 +1,35 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ObjectIDFromHex parses an id coming from a client. An invalid id is a validation error
+func ObjectIDFromHex(id string) (primitive.ObjectID, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, NewError(ErrValidation, "invalid id %q", id)
+	}
+
+	return objectID, nil
+}
+
+// MongoError gives a kind to the mongodb errors that have one: a missing document is not found and a duplicate key is a conflict.
+// The format and args describe the resource, like "list %v", id, and make the whole message so that the driver details do not reach the clients.
+// Other errors are returned as is
+func MongoError(err error, format string, args ...interface{}) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return NewError(ErrNotFound, "%s not found", fmt.Sprintf(format, args...))
+	case mongo.IsDuplicateKeyError(err):
+		return NewError(ErrConflict, "%s already exists", fmt.Sprintf(format, args...))
+	default:
+		return err
+	}
+}
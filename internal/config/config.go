@@ -32,24 +32,104 @@ func (e *ErrKeyNotFound) Error() string {
 type Config struct {
 	key       string
 	KeyConfig struct {
+		Algorithm     string        `mapstructure:"algorithm"`
 		Size          int           `mapstructure:"size"`
 		ValidDuration time.Duration `mapstructure:"validation_duration"`
+		GracePeriod   time.Duration `mapstructure:"grace_period"`
 	} `mapstructure:"key"`
+	Tokens struct {
+		AccessDuration  time.Duration `mapstructure:"access_duration"`
+		RefreshDuration time.Duration `mapstructure:"refresh_duration"`
+	} `mapstructure:"tokens"`
+	Passwords struct {
+		Memory      uint32 `mapstructure:"memory"`
+		Iterations  uint32 `mapstructure:"iterations"`
+		Parallelism uint8  `mapstructure:"parallelism"`
+		SaltLength  uint32 `mapstructure:"salt_length"`
+		KeyLength   uint32 `mapstructure:"key_length"`
+	} `mapstructure:"passwords"`
 	Server struct {
 		Hostname  string `mapstructure:"hostname"`
 		Port      string `mapstructure:"port"`
 		ServerCRT string `mapstructure:"crt"`
 		ServerKey string `mapstructure:"key"`
+		// TrustedProxies are the addresses or CIDR ranges whose forwarding headers give the address of the client
+		TrustedProxies []string `mapstructure:"trusted_proxies"`
+		// MaxBodySize is the size in bytes of the largest JSON body read by the validation
+		MaxBodySize int64 `mapstructure:"max_body_size"`
 	} `mapstructure:"server"`
 	Database struct {
-		Username        string `mapstructure:"username"`
-		Password        string `mapstructure:"password"`
-		Hostname        string `mapstructure:"hostname"`
-		Port            string `mapstructure:"port"`
-		Name            string `mapstructure:"db"`
-		ListsCollection string `mapstructure:"lists_collection"`
-		UsersCollection string `mapstructure:"users_collection"`
+		Username                     string `mapstructure:"username"`
+		Password                     string `mapstructure:"password"`
+		Hostname                     string `mapstructure:"hostname"`
+		Port                         string `mapstructure:"port"`
+		Name                         string `mapstructure:"db"`
+		ListsCollection              string `mapstructure:"lists_collection"`
+		UsersCollection              string `mapstructure:"users_collection"`
+		FiltersCollection            string `mapstructure:"filters_collection"`
+		ProductsCollection           string `mapstructure:"products_collection"`
+		StoresCollection             string `mapstructure:"stores_collection"`
+		PricesCollection             string `mapstructure:"prices_collection"`
+		SessionsCollection           string `mapstructure:"sessions_collection"`
+		RolesCollection              string `mapstructure:"roles_collection"`
+		InvitesCollection            string `mapstructure:"invites_collection"`
+		ResetTokensCollection        string `mapstructure:"reset_tokens_collection"`
+		LoginAttemptsCollection      string `mapstructure:"login_attempts_collection"`
+		SecurityEventsCollection     string `mapstructure:"security_events_collection"`
+		AccessTokensCollection       string `mapstructure:"access_tokens_collection"`
+		OIDCIdentitiesCollection     string `mapstructure:"oidc_identities_collection"`
+		OIDCAuthorizationsCollection string `mapstructure:"oidc_authorizations_collection"`
+		AuditEventsCollection        string `mapstructure:"audit_events_collection"`
+		SigningKeysCollection        string `mapstructure:"signing_keys_collection"`
 	} `mapstructure:"database"`
+	Attachments struct {
+		Backend      string   `mapstructure:"backend"`
+		Directory    string   `mapstructure:"directory"`
+		Bucket       string   `mapstructure:"bucket"`
+		MaxSize      int64    `mapstructure:"max_size"`
+		AllowedTypes []string `mapstructure:"allowed_types"`
+	} `mapstructure:"attachments"`
+	Lockout struct {
+		AccountThreshold int64         `mapstructure:"account_threshold"`
+		IPThreshold      int64         `mapstructure:"ip_threshold"`
+		BaseDelay        time.Duration `mapstructure:"base_delay"`
+		MaxDelay         time.Duration `mapstructure:"max_delay"`
+		ResetAfter       time.Duration `mapstructure:"reset_after"`
+	} `mapstructure:"lockout"`
+	Registration struct {
+		Enabled        bool `mapstructure:"enabled"`
+		MaxOpenInvites int  `mapstructure:"max_open_invites"`
+	} `mapstructure:"registration"`
+	OIDC struct {
+		Enabled       bool     `mapstructure:"enabled"`
+		Issuer        string   `mapstructure:"issuer"`
+		ClientID      string   `mapstructure:"client_id"`
+		ClientSecret  string   `mapstructure:"client_secret"`
+		RedirectURL   string   `mapstructure:"redirect_url"`
+		Scopes        []string `mapstructure:"scopes"`
+		AutoProvision bool     `mapstructure:"auto_provision"`
+	} `mapstructure:"oidc"`
+	PasswordReset struct {
+		Validity         time.Duration `mapstructure:"validity"`
+		URL              string        `mapstructure:"url"`
+		AccountThreshold int64         `mapstructure:"account_threshold"`
+		IPThreshold      int64         `mapstructure:"ip_threshold"`
+		Window           time.Duration `mapstructure:"window"`
+	} `mapstructure:"password_reset"`
+	Notifier struct {
+		Backend string `mapstructure:"backend"`
+		File    string `mapstructure:"file"`
+		SMTP    struct {
+			Host     string `mapstructure:"host"`
+			Port     string `mapstructure:"port"`
+			Username string `mapstructure:"username"`
+			Password string `mapstructure:"password"`
+			From     string `mapstructure:"from"`
+		} `mapstructure:"smtp"`
+	} `mapstructure:"notifier"`
+	Items struct {
+		Normalization []string `mapstructure:"normalization"`
+	} `mapstructure:"items"`
 }
 
 // NewConfig reads the given configuration file and the environment and returns a newly created Config
@@ -106,7 +186,7 @@ func (c *Config) Store(key string) error {
 
 // IsStored checks if the key is not empty. It satisfies the key storage interface
 func (c *Config) IsStored() (bool, error) {
-	return c.key == "", nil
+	return c.key != "", nil
 }
 
 // Get returns the app key. It satisfies the key consumer interface
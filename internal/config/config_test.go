@@ -0,0 +1,27 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyStorage(t *testing.T) {
+	c := &config.Config{}
+
+	// before the first rotation there is no key to sign with, rather than an empty one
+	stored, err := c.IsStored()
+	assert.NoError(t, err)
+	assert.False(t, stored)
+	_, err = c.Get()
+	assert.Error(t, err)
+
+	assert.NoError(t, c.Store("superSecretKey"))
+	stored, err = c.IsStored()
+	assert.NoError(t, err)
+	assert.True(t, stored)
+	key, err := c.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "superSecretKey", key)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/NicolasDutronc/shoppinglist-be/pkg/mongomigrate"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"golang.org/x/crypto/ssh/terminal"
@@ -182,6 +183,526 @@ func GetMigrations() []*mongomigrate.Migration {
 				return db.Collection("lists").Drop(ctx)
 			},
 		},
+		collectionMigration(5, "filter_collection", bson.A{"find", "update", "insert", "remove"}, "filters"),
+		attachmentMigration(6, "attachment_bucket"),
+		collectionMigration(7, "product_collection", bson.A{"find", "update", "insert", "remove"}, "products"),
+		{
+			ID:   8,
+			Name: "product_barcode_index",
+			Migrate: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("products").Indexes().CreateOne(
+					ctx,
+					mongo.IndexModel{
+						Keys: bson.M{
+							"barcode": 1,
+						},
+						Options: options.Index().SetUnique(true).SetName("unique barcode"),
+					},
+				)
+
+				return err
+			},
+			Rollback: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("products").Indexes().DropOne(ctx, "unique barcode")
+
+				return err
+			},
+		},
+		collectionMigration(9, "store_collections", bson.A{"find", "update", "insert", "remove"}, "stores", "prices"),
+		{
+			ID:   10,
+			Name: "price_product_index",
+			Migrate: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("prices").Indexes().CreateOne(
+					ctx,
+					mongo.IndexModel{
+						Keys: bson.D{
+							{Key: "product", Value: 1},
+							{Key: "store_id", Value: 1},
+							{Key: "recorded_at", Value: -1},
+						},
+						Options: options.Index().SetName("product prices"),
+					},
+				)
+
+				return err
+			},
+			Rollback: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("prices").Indexes().DropOne(ctx, "product prices")
+
+				return err
+			},
+		},
+		collectionMigration(11, "session_collection", bson.A{"find", "update", "insert", "remove"}, "sessions"),
+		{
+			ID:   12,
+			Name: "session_expiration_index",
+			Migrate: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("sessions").Indexes().CreateMany(
+					ctx,
+					[]mongo.IndexModel{
+						{
+							Keys:    bson.M{"user_id": 1},
+							Options: options.Index().SetName("user sessions"),
+						},
+						{
+							Keys:    bson.M{"expires_at": 1},
+							Options: options.Index().SetExpireAfterSeconds(0).SetName("session expiration"),
+						},
+					},
+				)
+
+				return err
+			},
+			Rollback: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("sessions").Indexes().DropAll(ctx)
+
+				return err
+			},
+		},
+		collectionMigration(13, "role_collection", bson.A{"find", "update", "insert", "remove"}, "roles"),
+		{
+			ID:   14,
+			Name: "role_name_index",
+			Migrate: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("roles").Indexes().CreateOne(
+					ctx,
+					mongo.IndexModel{
+						Keys: bson.M{
+							"name": 1,
+						},
+						Options: options.Index().SetUnique(true).SetName("unique role name"),
+					},
+				)
+
+				return err
+			},
+			Rollback: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("roles").Indexes().DropOne(ctx, "unique role name")
+
+				return err
+			},
+		},
+		collectionMigration(15, "invite_collection", bson.A{"find", "update", "insert", "remove"}, "invites"),
+		{
+			ID:   16,
+			Name: "invite_indexes",
+			Migrate: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("invites").Indexes().CreateMany(
+					ctx,
+					[]mongo.IndexModel{
+						{
+							Keys:    bson.M{"code_hash": 1},
+							Options: options.Index().SetUnique(true).SetName("unique invite code"),
+						},
+						{
+							Keys:    bson.M{"created_by": 1},
+							Options: options.Index().SetName("invite creator"),
+						},
+						{
+							Keys:    bson.M{"expires_at": 1},
+							Options: options.Index().SetExpireAfterSeconds(0).SetName("invite expiration"),
+						},
+					},
+				)
+
+				return err
+			},
+			Rollback: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("invites").Indexes().DropAll(ctx)
+
+				return err
+			},
+		},
+		collectionMigration(17, "reset_token_collection", bson.A{"find", "update", "insert", "remove"}, "reset_tokens"),
+		{
+			ID:   18,
+			Name: "reset_token_indexes",
+			Migrate: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("reset_tokens").Indexes().CreateMany(
+					ctx,
+					[]mongo.IndexModel{
+						{
+							Keys:    bson.M{"token_hash": 1},
+							Options: options.Index().SetUnique(true).SetName("unique reset token"),
+						},
+						{
+							Keys:    bson.M{"expires_at": 1},
+							Options: options.Index().SetExpireAfterSeconds(0).SetName("reset token expiration"),
+						},
+					},
+				)
+
+				return err
+			},
+			Rollback: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("reset_tokens").Indexes().DropAll(ctx)
+
+				return err
+			},
+		},
+		collectionMigration(19, "lockout_collections", bson.A{"find", "update", "insert", "remove"}, "login_attempts", "security_events"),
+		{
+			ID:   20,
+			Name: "lockout_indexes",
+			Migrate: func(ctx context.Context, db *mongo.Database) error {
+				if _, err := db.Collection("login_attempts").Indexes().CreateMany(
+					ctx,
+					[]mongo.IndexModel{
+						{
+							Keys:    bson.M{"key": 1},
+							Options: options.Index().SetUnique(true).SetName("unique login attempts key"),
+						},
+						{
+							Keys:    bson.M{"expires_at": 1},
+							Options: options.Index().SetExpireAfterSeconds(0).SetName("login attempts expiration"),
+						},
+					},
+				); err != nil {
+					return err
+				}
+
+				_, err := db.Collection("security_events").Indexes().CreateOne(
+					ctx,
+					mongo.IndexModel{
+						Keys:    bson.M{"created_at": -1},
+						Options: options.Index().SetName("latest security events"),
+					},
+				)
+
+				return err
+			},
+			Rollback: func(ctx context.Context, db *mongo.Database) error {
+				if _, err := db.Collection("login_attempts").Indexes().DropAll(ctx); err != nil {
+					return err
+				}
+
+				_, err := db.Collection("security_events").Indexes().DropOne(ctx, "latest security events")
+
+				return err
+			},
+		},
+		collectionMigration(21, "access_tokens_collection", bson.A{"find", "update", "insert", "remove"}, "access_tokens"),
+		{
+			ID:   22,
+			Name: "access_tokens_indexes",
+			Migrate: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("access_tokens").Indexes().CreateMany(
+					ctx,
+					[]mongo.IndexModel{
+						{
+							Keys:    bson.M{"token_hash": 1},
+							Options: options.Index().SetUnique(true).SetName("unique access token hash"),
+						},
+						{
+							Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+							Options: options.Index().SetName("access tokens by user"),
+						},
+					},
+				)
+
+				return err
+			},
+			Rollback: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("access_tokens").Indexes().DropAll(ctx)
+
+				return err
+			},
+		},
+		collectionMigration(23, "oidc_collections", bson.A{"find", "update", "insert", "remove"}, "oidc_identities", "oidc_authorizations"),
+		{
+			ID:   24,
+			Name: "oidc_indexes",
+			Migrate: func(ctx context.Context, db *mongo.Database) error {
+				if _, err := db.Collection("oidc_identities").Indexes().CreateOne(
+					ctx,
+					mongo.IndexModel{
+						Keys:    bson.D{{Key: "issuer", Value: 1}, {Key: "subject", Value: 1}},
+						Options: options.Index().SetUnique(true).SetName("unique identity subject"),
+					},
+				); err != nil {
+					return err
+				}
+
+				_, err := db.Collection("oidc_authorizations").Indexes().CreateMany(
+					ctx,
+					[]mongo.IndexModel{
+						{
+							Keys:    bson.M{"state_hash": 1},
+							Options: options.Index().SetUnique(true).SetName("unique authorization state"),
+						},
+						{
+							Keys:    bson.M{"expires_at": 1},
+							Options: options.Index().SetExpireAfterSeconds(0).SetName("authorizations expiration"),
+						},
+					},
+				)
+
+				return err
+			},
+			Rollback: func(ctx context.Context, db *mongo.Database) error {
+				if _, err := db.Collection("oidc_identities").Indexes().DropAll(ctx); err != nil {
+					return err
+				}
+
+				_, err := db.Collection("oidc_authorizations").Indexes().DropAll(ctx)
+
+				return err
+			},
+		},
+		{
+			ID:   25,
+			Name: "list_owner_index",
+			Migrate: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("lists").Indexes().CreateOne(
+					ctx,
+					mongo.IndexModel{
+						Keys:    bson.M{"owner_id": 1},
+						Options: options.Index().SetName("lists by owner"),
+					},
+				)
+
+				return err
+			},
+			Rollback: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("lists").Indexes().DropOne(ctx, "lists by owner")
+
+				return err
+			},
+		},
+		{
+			ID:   26,
+			Name: "oidc_identities_user_index",
+			Migrate: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("oidc_identities").Indexes().CreateOne(
+					ctx,
+					mongo.IndexModel{
+						Keys:    bson.M{"user_id": 1},
+						Options: options.Index().SetName("identities by user"),
+					},
+				)
+
+				return err
+			},
+			Rollback: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("oidc_identities").Indexes().DropOne(ctx, "identities by user")
+
+				return err
+			},
+		},
+		// the audit trail is append-only, so the backend can neither update nor remove events
+		collectionMigration(27, "audit_events_collection", bson.A{"find", "insert"}, "audit_events"),
+		{
+			ID:   28,
+			Name: "audit_events_indexes",
+			Migrate: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("audit_events").Indexes().CreateMany(
+					ctx,
+					[]mongo.IndexModel{
+						{
+							Keys:    bson.D{{Key: "created_at", Value: -1}},
+							Options: options.Index().SetName("latest audit events"),
+						},
+						{
+							Keys:    bson.D{{Key: "actor.user_id", Value: 1}, {Key: "created_at", Value: -1}},
+							Options: options.Index().SetName("audit events by actor"),
+						},
+						{
+							Keys:    bson.D{{Key: "target_id", Value: 1}, {Key: "created_at", Value: -1}},
+							Options: options.Index().SetName("audit events by target"),
+						},
+					},
+				)
+
+				return err
+			},
+			Rollback: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("audit_events").Indexes().DropAll(ctx)
+
+				return err
+			},
+		},
+		rolePermissionMigration(30, "editor_stores_permission", "editor", "stores", "write"),
+		rolePermissionMigration(31, "editor_invites_permission", "editor", "invites", "write"),
+		// the keys signing the tokens are shared by the instances and survive the restarts
+		collectionMigration(32, "signing_keys_collection", bson.A{"find", "update", "insert", "remove"}, "signing_keys"),
+	}
+
+}
+
+// rolePermissionMigration returns a migration that adds a permission to a role, for the routes that started to require it
+func rolePermissionMigration(id uint64, name string, role string, resourceID string, action string) *mongomigrate.Migration {
+	permission := bson.D{{Key: "resource_id", Value: resourceID}, {Key: "action", Value: action}}
+
+	return &mongomigrate.Migration{
+		ID:   id,
+		Name: name,
+		Migrate: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("roles").UpdateOne(
+				ctx,
+				bson.M{"name": role},
+				bson.D{{Key: "$addToSet", Value: bson.D{{Key: "permissions", Value: permission}}}},
+			)
+
+			return err
+		},
+		Rollback: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("roles").UpdateOne(
+				ctx,
+				bson.M{"name": role},
+				bson.D{{Key: "$pull", Value: bson.D{{Key: "permissions", Value: permission}}}},
+			)
+
+			return err
+		},
+	}
+}
+
+// collectionMigration returns a migration that creates collections and grants the backend role the given actions on them
+func collectionMigration(id uint64, name string, actions bson.A, collections ...string) *mongomigrate.Migration {
+	privileges := bson.A{}
+	for _, collection := range collections {
+		privileges = append(privileges, bson.D{
+			{
+				Key: "resource",
+				Value: bson.D{
+					{
+						Key:   "db",
+						Value: "shoplist",
+					},
+					{
+						Key:   "collection",
+						Value: collection,
+					},
+				}},
+			{
+				Key:   "actions",
+				Value: actions,
+			},
+		})
+	}
+
+	return &mongomigrate.Migration{
+		ID:   id,
+		Name: name,
+		Migrate: func(ctx context.Context, db *mongo.Database) error {
+			for _, collection := range collections {
+				if err := db.RunCommand(
+					ctx,
+					bson.D{
+						{
+							Key:   "create",
+							Value: collection,
+						},
+					},
+				).Err(); err != nil {
+					return err
+				}
+			}
+
+			return db.RunCommand(
+				ctx,
+				bson.D{
+					{
+						Key:   "grantPrivilegesToRole",
+						Value: "backend_role",
+					},
+					{
+						Key:   "privileges",
+						Value: privileges,
+					},
+				},
+			).Err()
+		},
+		Rollback: func(ctx context.Context, db *mongo.Database) error {
+			if err := db.RunCommand(
+				ctx,
+				bson.D{
+					{
+						Key:   "revokePrivilegesFromRole",
+						Value: "backend_role",
+					},
+					{
+						Key:   "privileges",
+						Value: privileges,
+					},
+				},
+			).Err(); err != nil {
+				return err
+			}
+
+			for _, collection := range collections {
+				if err := db.Collection(collection).Drop(ctx); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+// attachmentMigration returns a migration that creates the attachment bucket and gives an id to the existing items, so that the items holding attachments can be addressed by id
+func attachmentMigration(id uint64, name string) *mongomigrate.Migration {
+	migration := collectionMigration(id, name, bson.A{"find", "update", "insert", "remove", "createIndex", "listIndexes"}, "attachments.files", "attachments.chunks")
+	createBucket, dropBucket := migration.Migrate, migration.Rollback
+
+	migration.Migrate = func(ctx context.Context, db *mongo.Database) error {
+		if err := createBucket(ctx, db); err != nil {
+			return err
+		}
+
+		return backfillItemIDs(ctx, db)
+	}
+	migration.Rollback = func(ctx context.Context, db *mongo.Database) error {
+		if _, err := db.Collection("lists").UpdateMany(
+			ctx,
+			bson.M{},
+			bson.D{{Key: "$unset", Value: bson.D{{Key: "items.$[].id", Value: ""}}}},
+		); err != nil {
+			return err
+		}
+
+		return dropBucket(ctx, db)
+	}
+
+	return migration
+}
+
+// backfillItemIDs gives an id to the items stored before the items had one
+func backfillItemIDs(ctx context.Context, db *mongo.Database) error {
+	cursor, err := db.Collection("lists").Find(
+		ctx,
+		bson.M{"items": bson.M{"$elemMatch": bson.M{"id": bson.M{"$exists": false}}}},
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var list struct {
+			ID    primitive.ObjectID `bson:"_id"`
+			Items []bson.M           `bson:"items"`
+		}
+		if err := cursor.Decode(&list); err != nil {
+			return err
+		}
+
+		for _, item := range list.Items {
+			if _, ok := item["id"]; !ok {
+				item["id"] = primitive.NewObjectID().Hex()
+			}
+		}
+
+		if _, err := db.Collection("lists").UpdateOne(
+			ctx,
+			bson.M{"_id": list.ID},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "items", Value: list.Items}}}},
+		); err != nil {
+			return err
+		}
 	}
 
+	return cursor.Err()
 }
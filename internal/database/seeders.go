@@ -14,12 +14,11 @@ import (
 	"github.com/NicolasDutronc/shoppinglist-be/pkg/mongomigrate"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"golang.org/x/crypto/bcrypt"
 	"golang.org/x/crypto/ssh/terminal"
 )
 
-// GetSeeders returns the seeders of the project
-func GetSeeders() []*mongomigrate.Seeder {
+// GetSeeders returns the seeders of the project. The passwords of the seeded users are hashed by hasher
+func GetSeeders(hasher user.PasswordHasher) []*mongomigrate.Seeder {
 	return []*mongomigrate.Seeder{
 		{
 			Name: "admin_user",
@@ -45,12 +44,12 @@ func GetSeeders() []*mongomigrate.Seeder {
 					return err
 				}
 
-				encryptedPassword, err := bcrypt.GenerateFromPassword([]byte(pwd), bcrypt.DefaultCost)
+				encryptedPassword, err := hasher.Hash(string(pwd))
 				if err != nil {
 					return err
 				}
 
-				admin.Password = string(encryptedPassword)
+				admin.Password = encryptedPassword
 				if _, err := db.Collection("users").InsertOne(ctx, admin); err != nil {
 					return err
 				}
@@ -83,11 +82,11 @@ func GetSeeders() []*mongomigrate.Seeder {
 				}
 
 				for _, user := range users {
-					encryptedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+					encryptedPassword, err := hasher.Hash(user.Password)
 					if err != nil {
 						return err
 					}
-					user.Password = string(encryptedPassword)
+					user.Password = encryptedPassword
 					if _, err := db.Collection("users").InsertOne(ctx, user); err != nil {
 						return err
 					}
@@ -109,11 +108,13 @@ func GetSeeders() []*mongomigrate.Seeder {
 						Name: "Bonnes choses",
 						Items: []*list.Item{
 							{
+								ID:       primitive.NewObjectID().Hex(),
 								Name:     "chocolat",
 								Quantity: "500g",
 								Done:     false,
 							},
 							{
+								ID:       primitive.NewObjectID().Hex(),
 								Name:     "baguettes",
 								Quantity: "12",
 								Done:     true,
@@ -129,11 +130,13 @@ func GetSeeders() []*mongomigrate.Seeder {
 						Name: "Le reste...",
 						Items: []*list.Item{
 							{
+								ID:       primitive.NewObjectID().Hex(),
 								Name:     "légumes",
 								Quantity: "500g",
 								Done:     false,
 							},
 							{
+								ID:       primitive.NewObjectID().Hex(),
 								Name:     "salade",
 								Quantity: "1",
 								Done:     true,
@@ -184,12 +187,12 @@ func GetSeeders() []*mongomigrate.Seeder {
 					return err
 				}
 
-				encryptedPassword, err := bcrypt.GenerateFromPassword([]byte(pwd), bcrypt.DefaultCost)
+				encryptedPassword, err := hasher.Hash(string(pwd))
 				if err != nil {
 					return err
 				}
 
-				newUser.Password = string(encryptedPassword)
+				newUser.Password = encryptedPassword
 				if _, err := db.Collection("users").InsertOne(ctx, newUser); err != nil {
 					return err
 				}
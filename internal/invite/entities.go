@@ -0,0 +1,69 @@
+package invite
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Invite allows people to register. Its code can be redeemed a limited number of times before it expires,
+// and every redemption grants the invite permissions to the new user
+type Invite struct {
+	common.BaseModel `bson:",inline"`
+	CodeHash         string             `bson:"code_hash" json:"-"`
+	CreatedBy        string             `bson:"created_by" json:"created_by"`
+	Permissions      []*user.Permission `bson:"permissions" json:"permissions"`
+	MaxUses          int64              `bson:"max_uses" json:"max_uses"`
+	RemainingUses    int64              `bson:"remaining_uses" json:"remaining_uses"`
+	ExpiresAt        time.Time          `bson:"expires_at" json:"expires_at"`
+}
+
+// NewInvite is an Invite constructor. It returns the invite along with its code, which is only known by the creator
+func NewInvite(createdBy string, maxUses int64, validity time.Duration, permissions ...*user.Permission) (*Invite, string, error) {
+	code, err := newCode()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	return &Invite{
+		BaseModel: common.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		CodeHash:      HashCode(code),
+		CreatedBy:     createdBy,
+		Permissions:   permissions,
+		MaxUses:       maxUses,
+		RemainingUses: maxUses,
+		ExpiresAt:     now.Add(validity),
+	}, code, nil
+}
+
+// IsRedeemable returns true if the invite has uses left and is not expired
+func (i *Invite) IsRedeemable(now time.Time) bool {
+	return i.RemainingUses > 0 && now.Before(i.ExpiresAt)
+}
+
+// newCode returns a random code that is easy to copy
+func newCode() (string, error) {
+	secret := make([]byte, 10)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret), nil
+}
+
+// HashCode returns the hash under which a code is stored
+func HashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
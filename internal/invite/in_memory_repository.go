@@ -0,0 +1,100 @@
+package invite
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+)
+
+// InMemoryRepository is an in-memory invites repository
+type InMemoryRepository struct {
+	mu      sync.Mutex
+	invites map[string]*Invite
+}
+
+// NewInMemoryRepository is a constructor of InMemoryRepository
+func NewInMemoryRepository() Repository {
+	return &InMemoryRepository{
+		invites: make(map[string]*Invite),
+	}
+}
+
+// FindInvitesByCreator retrieves the invites created by a user, most recent first
+func (r *InMemoryRepository) FindInvitesByCreator(ctx context.Context, userID string) ([]*Invite, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invites := []*Invite{}
+	for _, invite := range r.invites {
+		if invite.CreatedBy == userID {
+			invites = append(invites, invite)
+		}
+	}
+
+	sort.Slice(invites, func(i, j int) bool {
+		return invites[i].CreatedAt.After(invites[j].CreatedAt)
+	})
+
+	return invites, nil
+}
+
+// StoreInvite inserts an invite
+func (r *InMemoryRepository) StoreInvite(ctx context.Context, invite *Invite) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.invites[invite.ID.Hex()] = invite
+
+	return nil
+}
+
+// DeleteInvite removes an invite created by the user
+func (r *InMemoryRepository) DeleteInvite(ctx context.Context, userID string, inviteID string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invite, exists := r.invites[inviteID]
+	if !exists || invite.CreatedBy != userID {
+		return 0, nil
+	}
+
+	delete(r.invites, inviteID)
+
+	return 1, nil
+}
+
+// Redeem consumes one use of the redeemable invite matching the code hash
+func (r *InMemoryRepository) Redeem(ctx context.Context, codeHash string, now time.Time) (*Invite, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, invite := range r.invites {
+		if invite.CodeHash == codeHash && invite.IsRedeemable(now) {
+			invite.RemainingUses--
+			invite.UpdatedAt = now
+
+			return invite, nil
+		}
+	}
+
+	return nil, ErrInvalidCode
+}
+
+// Release gives back a use of an invite
+func (r *InMemoryRepository) Release(ctx context.Context, inviteID string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invite, exists := r.invites[inviteID]
+	if !exists {
+		return -1, common.NewError(common.ErrNotFound, "there is no invite with id %v", inviteID)
+	}
+
+	invite.RemainingUses++
+	invite.UpdatedAt = time.Now()
+
+	return 1, nil
+}
@@ -0,0 +1,109 @@
+package invite
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDBRepository contains all the methods to interact with the invites collection
+type MongoDBRepository struct {
+	InvitesCollection *mongo.Collection
+}
+
+// NewMongoDBRepository is a constructor for MongoDBRepository
+func NewMongoDBRepository(coll *mongo.Collection) Repository {
+	return &MongoDBRepository{
+		InvitesCollection: coll,
+	}
+}
+
+// FindInvitesByCreator retrieves the invites created by a user, most recent first
+func (r *MongoDBRepository) FindInvitesByCreator(ctx context.Context, userID string) ([]*Invite, error) {
+	invites := []*Invite{}
+	cursor, err := r.InvitesCollection.Find(ctx, bson.M{"created_by": userID}, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(ctx, &invites); err != nil {
+		return nil, err
+	}
+
+	return invites, nil
+}
+
+// StoreInvite inserts an invite
+func (r *MongoDBRepository) StoreInvite(ctx context.Context, invite *Invite) error {
+	_, err := r.InvitesCollection.InsertOne(ctx, invite)
+
+	return err
+}
+
+// DeleteInvite removes an invite created by the user
+func (r *MongoDBRepository) DeleteInvite(ctx context.Context, userID string, inviteID string) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(inviteID)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.InvitesCollection.DeleteOne(ctx, bson.M{"_id": objectID, "created_by": userID})
+	if err != nil {
+		return -1, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// Redeem atomically consumes one use of the redeemable invite matching the code hash
+func (r *MongoDBRepository) Redeem(ctx context.Context, codeHash string, now time.Time) (*Invite, error) {
+	var invite Invite
+	err := r.InvitesCollection.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"code_hash":      codeHash,
+			"remaining_uses": bson.M{"$gt": 0},
+			"expires_at":     bson.M{"$gt": now},
+		},
+		bson.D{
+			{Key: "$inc", Value: bson.D{{Key: "remaining_uses", Value: -1}}},
+			{Key: "$set", Value: bson.D{{Key: "updated_at", Value: now}}},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&invite)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrInvalidCode
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &invite, nil
+}
+
+// Release gives back a use of an invite
+func (r *MongoDBRepository) Release(ctx context.Context, inviteID string) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(inviteID)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.InvitesCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.D{
+			{Key: "$inc", Value: bson.D{{Key: "remaining_uses", Value: 1}}},
+			{Key: "$set", Value: bson.D{{Key: "updated_at", Value: time.Now()}}},
+		},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
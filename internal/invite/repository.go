@@ -0,0 +1,40 @@
+package invite
+
+import (
+	"context"
+	"time"
+)
+
+// FinderByCreator is a single method interface for listing the invites created by a user
+type FinderByCreator interface {
+	FindInvitesByCreator(ctx context.Context, userID string) ([]*Invite, error)
+}
+
+// Creator is a single method interface for storing an invite
+type Creator interface {
+	StoreInvite(ctx context.Context, invite *Invite) error
+}
+
+// Deleter is a single method interface for deleting an invite of a user
+type Deleter interface {
+	DeleteInvite(ctx context.Context, userID string, inviteID string) (int64, error)
+}
+
+// Redeemer is a single method interface for using an invite. It consumes one use of the redeemable invite matching the code hash
+type Redeemer interface {
+	Redeem(ctx context.Context, codeHash string, now time.Time) (*Invite, error)
+}
+
+// Releaser is a single method interface for giving back a use of an invite, when the registration failed after the redemption
+type Releaser interface {
+	Release(ctx context.Context, inviteID string) (int64, error)
+}
+
+// Repository is a wrapper around all the single method interfaces defining the invites storage
+type Repository interface {
+	FinderByCreator
+	Creator
+	Deleter
+	Redeemer
+	Releaser
+}
@@ -0,0 +1,115 @@
+package invite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+)
+
+var (
+	// ErrRegistrationDisabled is returned when registering while the registration is closed
+	ErrRegistrationDisabled = common.NewError(common.ErrForbidden, "registration is disabled")
+	// ErrInvalidCode is returned when the invite code is unknown, expired or used up
+	ErrInvalidCode = common.NewError(common.ErrValidation, "invalid invite code")
+	// ErrInvalidInvite is returned when creating an invite without uses or validity
+	ErrInvalidInvite = common.NewError(common.ErrValidation, "an invite needs at least one use and a positive validity")
+	// ErrTooManyInvites is returned when the creator already has as many redeemable invites as allowed
+	ErrTooManyInvites = common.NewError(common.ErrConflict, "too many open invites, revoke one before creating another")
+)
+
+// ServiceImpl is the concrete implementation of the invite service interface
+type ServiceImpl struct {
+	repo                Repository
+	users               Registerer
+	registrationEnabled bool
+	maxOpenInvites      int
+}
+
+// NewService inits a new invite service. When registrationEnabled is false, invites can still be managed but not redeemed.
+// A user can have at most maxOpenInvites redeemable invites at a time
+func NewService(repo Repository, users Registerer, registrationEnabled bool, maxOpenInvites int) Service {
+	return &ServiceImpl{
+		repo:                repo,
+		users:               users,
+		registrationEnabled: registrationEnabled,
+		maxOpenInvites:      maxOpenInvites,
+	}
+}
+
+// FindInvitesByCreator directly calls the repository
+func (s *ServiceImpl) FindInvitesByCreator(ctx context.Context, userID string) ([]*Invite, error) {
+	return s.repo.FindInvitesByCreator(ctx, userID)
+}
+
+// CreateInvite creates an invite granting the permissions. The creator must hold every permission it grants
+// and have less than the maximum number of redeemable invites
+func (s *ServiceImpl) CreateInvite(ctx context.Context, creator *user.User, maxUses int64, validity time.Duration, permissions ...*user.Permission) (*Invite, string, error) {
+	if maxUses < 1 || validity <= 0 {
+		return nil, "", ErrInvalidInvite
+	}
+
+	for _, permission := range permissions {
+		if err := creator.Can(permission.Action, permission.ResourceID); err != nil {
+			return nil, "", fmt.Errorf("cannot grant a permission that is not held: %w", err)
+		}
+	}
+
+	invites, err := s.repo.FindInvitesByCreator(ctx, creator.ID.Hex())
+	if err != nil {
+		return nil, "", err
+	}
+	open := 0
+	now := time.Now()
+	for _, invite := range invites {
+		if invite.IsRedeemable(now) {
+			open++
+		}
+	}
+	if open >= s.maxOpenInvites {
+		return nil, "", ErrTooManyInvites
+	}
+
+	invite, code, err := NewInvite(creator.ID.Hex(), maxUses, validity, permissions...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := s.repo.StoreInvite(ctx, invite); err != nil {
+		return nil, "", err
+	}
+
+	return invite, code, nil
+}
+
+// DeleteInvite revokes an invite of the creator
+func (s *ServiceImpl) DeleteInvite(ctx context.Context, creator *user.User, inviteID string) (int64, error) {
+	return s.repo.DeleteInvite(ctx, creator.ID.Hex(), inviteID)
+}
+
+// Register redeems the invite code and creates the user with the permissions of the invite.
+// The use is given back if the user could not be created
+func (s *ServiceImpl) Register(ctx context.Context, code string, name string, password string) (*user.User, error) {
+	if !s.registrationEnabled {
+		return nil, ErrRegistrationDisabled
+	}
+
+	invite, err := s.repo.Redeem(ctx, HashCode(strings.ToUpper(strings.TrimSpace(code))), time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	newUser, err := s.users.Store(ctx, name, password, invite.Permissions...)
+	if err != nil {
+		if _, releaseErr := s.repo.Release(ctx, invite.ID.Hex()); releaseErr != nil {
+			return nil, fmt.Errorf("%v, and the invite use could not be given back: %w", err, releaseErr)
+		}
+
+		return nil, err
+	}
+
+	return newUser, nil
+}
@@ -0,0 +1,21 @@
+package invite
+
+import (
+	"context"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+)
+
+// Registerer is the part of the user service used to create the invited users
+type Registerer interface {
+	Store(ctx context.Context, name string, password string, permissions ...*user.Permission) (*user.User, error)
+}
+
+// Service defines the invites management and the registration of invited users
+type Service interface {
+	FindInvitesByCreator(ctx context.Context, userID string) ([]*Invite, error)
+	CreateInvite(ctx context.Context, creator *user.User, maxUses int64, validity time.Duration, permissions ...*user.Permission) (*Invite, string, error)
+	DeleteInvite(ctx context.Context, creator *user.User, inviteID string) (int64, error)
+	Register(ctx context.Context, code string, name string, password string) (*user.User, error)
+}
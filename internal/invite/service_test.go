@@ -0,0 +1,113 @@
+package invite_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/invite"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingRegisterer refuses every user
+type failingRegisterer struct{}
+
+func (failingRegisterer) Store(ctx context.Context, name string, password string, permissions ...*user.Permission) (*user.User, error) {
+	return nil, errors.New("name already taken")
+}
+
+func TestRegister(t *testing.T) {
+	ctx := context.Background()
+	owner := user.NewUser("owner", "", &user.Permission{ResourceID: "list-1", Action: "write"})
+	srv := invite.NewService(invite.NewInMemoryRepository(), user.NewInMemoryRepository(), true, 10)
+
+	// the owner cannot grant permissions it does not hold
+	_, _, err := srv.CreateInvite(ctx, owner, 1, time.Hour, &user.Permission{ResourceID: "list-2", Action: "write"})
+	assert.Error(t, err)
+	_, _, err = srv.CreateInvite(ctx, owner, 0, time.Hour)
+	assert.ErrorIs(t, err, invite.ErrInvalidInvite)
+
+	created, code, err := srv.CreateInvite(ctx, owner, 2, time.Hour, &user.Permission{ResourceID: "list-1", Action: "read"})
+	assert.NoError(t, err)
+	assert.NotEqual(t, code, created.CodeHash)
+
+	alice, err := srv.Register(ctx, code, "alice", "password")
+	assert.NoError(t, err)
+	assert.NoError(t, alice.Can("read", "list-1"))
+	assert.Error(t, alice.Can("write", "list-1"))
+
+	_, err = srv.Register(ctx, code, "bob", "password")
+	assert.NoError(t, err)
+
+	// the invite is used up
+	_, err = srv.Register(ctx, code, "carol", "password")
+	assert.ErrorIs(t, err, invite.ErrInvalidCode)
+
+	invites, err := srv.FindInvitesByCreator(ctx, owner.ID.Hex())
+	assert.NoError(t, err)
+	assert.Len(t, invites, 1)
+	assert.Equal(t, int64(0), invites[0].RemainingUses)
+}
+
+func TestRegisterGivesBackTheUseOnFailure(t *testing.T) {
+	ctx := context.Background()
+	owner := user.NewUser("owner", "")
+	repo := invite.NewInMemoryRepository()
+
+	_, code, err := invite.NewService(repo, failingRegisterer{}, true, 10).CreateInvite(ctx, owner, 1, time.Hour)
+	assert.NoError(t, err)
+
+	_, err = invite.NewService(repo, failingRegisterer{}, true, 10).Register(ctx, code, "alice", "password")
+	assert.EqualError(t, err, "name already taken")
+
+	// the use was given back
+	_, err = invite.NewService(repo, user.NewInMemoryRepository(), true, 10).Register(ctx, code, "alice", "password")
+	assert.NoError(t, err)
+}
+
+func TestRegisterRejectsExpiredCodesAndDisabledRegistration(t *testing.T) {
+	ctx := context.Background()
+	owner := user.NewUser("owner", "")
+	repo := invite.NewInMemoryRepository()
+
+	expired, code, err := invite.NewInvite(owner.ID.Hex(), 1, -time.Minute)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.StoreInvite(ctx, expired))
+
+	_, err = invite.NewService(repo, user.NewInMemoryRepository(), true, 10).Register(ctx, code, "alice", "password")
+	assert.ErrorIs(t, err, invite.ErrInvalidCode)
+
+	_, err = invite.NewService(repo, user.NewInMemoryRepository(), false, 10).Register(ctx, code, "alice", "password")
+	assert.ErrorIs(t, err, invite.ErrRegistrationDisabled)
+}
+
+func TestCreateInviteLimitsOpenInvites(t *testing.T) {
+	ctx := context.Background()
+	owner := user.NewUser("owner", "")
+	repo := invite.NewInMemoryRepository()
+	srv := invite.NewService(repo, user.NewInMemoryRepository(), true, 2)
+
+	// expired invites do not count
+	expired, _, err := invite.NewInvite(owner.ID.Hex(), 1, -time.Minute)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.StoreInvite(ctx, expired))
+
+	first, _, err := srv.CreateInvite(ctx, owner, 1, time.Hour)
+	assert.NoError(t, err)
+	_, _, err = srv.CreateInvite(ctx, owner, 1, time.Hour)
+	assert.NoError(t, err)
+	_, _, err = srv.CreateInvite(ctx, owner, 1, time.Hour)
+	assert.ErrorIs(t, err, invite.ErrTooManyInvites)
+
+	// the limit is per creator
+	_, _, err = srv.CreateInvite(ctx, user.NewUser("other", ""), 1, time.Hour)
+	assert.NoError(t, err)
+
+	// revoking an invite makes room for another one
+	_, err = srv.DeleteInvite(ctx, owner, first.ID.Hex())
+	assert.NoError(t, err)
+	_, _, err = srv.CreateInvite(ctx, owner, 1, time.Hour)
+	assert.NoError(t, err)
+}
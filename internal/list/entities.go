@@ -1,17 +1,186 @@
 package list
 
-import "github.com/NicolasDutronc/shoppinglist-be/internal/common"
+import (
+	"strings"
 
-// Item is the item model containing a name and a quantity
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+)
+
+// ErrEmptyName is returned when a list or an item is given a blank name
+var ErrEmptyName = common.NewError(common.ErrValidation, "the name must not be empty")
+
+// checkName returns ErrEmptyName if the name is blank
+func checkName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return ErrEmptyName
+	}
+
+	return nil
+}
+
+// Item is the item model containing a name and a quantity. Its id is unique within its list.
+// Its key is its name normalized when it was stored, which lets the database reject the duplicates atomically
 type Item struct {
-	Name     string `bson:"name" json:"name"`
-	Quantity string `bson:"quantity" json:"quantity"`
-	Done     bool   `bson:"done" json:"done"`
+	ID          string   `bson:"id" json:"id"`
+	Key         string   `bson:"key,omitempty" json:"-"`
+	Name        string   `bson:"name" json:"name"`
+	Quantity    string   `bson:"quantity" json:"quantity"`
+	Done        bool     `bson:"done" json:"done"`
+	Attachments []string `bson:"attachments" json:"attachments"`
+}
+
+// HasAttachment returns true if the attachment given by its id is attached to the item
+func (i *Item) HasAttachment(attachmentID string) bool {
+	for _, id := range i.Attachments {
+		if id == attachmentID {
+			return true
+		}
+	}
+
+	return false
 }
 
 // Shoppinglist is a struct defining a shoplist in the collection
 type Shoppinglist struct {
 	common.BaseModel `bson:",inline"`
-	Name             string  `bson:"name" json:"name"`
-	Items            []*Item `bson:"items" json:"items"`
+	Name             string   `bson:"name" json:"name"`
+	OwnerID          string   `bson:"owner_id" json:"owner_id"`
+	Tags             []string `bson:"tags" json:"tags"`
+	Items            []*Item  `bson:"items" json:"items"`
+}
+
+// HasTag returns true if the list is tagged with the given tag
+func (l *Shoppinglist) HasTag(tag string) bool {
+	for _, t := range l.Tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sameState returns true if the lists have the same name and the same tags, in the same order
+func (l *Shoppinglist) sameState(other *Shoppinglist) bool {
+	if l.Name != other.Name || len(l.Tags) != len(other.Tags) {
+		return false
+	}
+	for i, tag := range l.Tags {
+		if other.Tags[i] != tag {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FindItem returns the item given by its id or nil if the list has none
+func (l *Shoppinglist) FindItem(itemID string) *Item {
+	for _, item := range l.Items {
+		if item.ID == itemID {
+			return item
+		}
+	}
+
+	return nil
+}
+
+// FindAttachment returns the item holding the attachment given by its id or nil if no item holds it
+func (l *Shoppinglist) FindAttachment(attachmentID string) *Item {
+	for _, item := range l.Items {
+		if item.HasAttachment(attachmentID) {
+			return item
+		}
+	}
+
+	return nil
+}
+
+// FindDuplicate returns the item whose name has the same key as the given name or nil if there is none.
+// The excepted items are skipped, like an item being renamed
+func (l *Shoppinglist) FindDuplicate(name string, normalizer *Normalizer, except ...*Item) *Item {
+	key := normalizer.Normalize(name)
+	for _, item := range l.Items {
+		if isOneOf(item, except) {
+			continue
+		}
+		if normalizer.Normalize(item.Name) == key {
+			return item
+		}
+	}
+
+	return nil
+}
+
+func isOneOf(item *Item, items []*Item) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sameState returns true if the items have the same name, quantity and done state
+func (i *Item) sameState(other *Item) bool {
+	return i.Name == other.Name && i.Quantity == other.Quantity && i.Done == other.Done
+}
+
+// listChangedError returns the error of a list changed since it was read
+func listChangedError(listID string) error {
+	return common.NewError(common.ErrConflict, "the list %v was changed in the meantime", listID)
+}
+
+// itemNotFoundError returns the error of an item missing from a list
+func itemNotFoundError(listID string, itemID string) error {
+	return common.NewError(common.ErrNotFound, "there is no item with id %v in the list %v", itemID, listID)
+}
+
+// itemChangedError returns the error of an item changed since it was read
+func itemChangedError(listID string, itemID string) error {
+	return common.NewError(common.ErrConflict, "the item %v of the list %v was changed in the meantime", itemID, listID)
+}
+
+// duplicateItemError returns the error of an item that would get the same key as an existing item
+func duplicateItemError(listID string, name string) error {
+	return common.NewError(common.ErrConflict, "the list %v already has an item named like %v", listID, name)
+}
+
+// Filter is a saved query on the items of all lists. It acts as a virtual list
+type Filter struct {
+	common.BaseModel `bson:",inline"`
+	Name             string   `bson:"name" json:"name"`
+	Tags             []string `bson:"tags" json:"tags"`
+	Done             *bool    `bson:"done,omitempty" json:"done,omitempty"`
+}
+
+// MatchesTags returns true if one of the given tags is one of the filter tags.
+// A filter without tags matches every list
+func (f *Filter) MatchesTags(tags []string) bool {
+	if len(f.Tags) == 0 {
+		return true
+	}
+
+	for _, filterTag := range f.Tags {
+		for _, tag := range tags {
+			if filterTag == tag {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// MatchesItem returns true if the item satisfies the item conditions of the filter
+func (f *Filter) MatchesItem(item *Item) bool {
+	return f.Done == nil || *f.Done == item.Done
+}
+
+// FilteredItem is an item matched by a filter along with the list it belongs to
+type FilteredItem struct {
+	ListID   string `bson:"list_id" json:"list_id"`
+	ListName string `bson:"list_name" json:"list_name"`
+	Item     *Item  `bson:"item" json:"item"`
 }
@@ -0,0 +1,74 @@
+package list
+
+import (
+	"context"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InMemoryFilterRepository is an in-memory filter repository
+type InMemoryFilterRepository struct {
+	filters map[string]*Filter
+}
+
+// NewInMemoryFilterRepository is a constructor of InMemoryFilterRepository
+func NewInMemoryFilterRepository() FilterRepository {
+	return &InMemoryFilterRepository{
+		filters: make(map[string]*Filter),
+	}
+}
+
+// FindFilterByID retrieves a filter based on its id
+func (r *InMemoryFilterRepository) FindFilterByID(ctx context.Context, filterID string) (*Filter, error) {
+	filter, exists := r.filters[filterID]
+	if !exists {
+		return nil, common.NewError(common.ErrNotFound, "there is no filter with id %v", filterID)
+	}
+
+	return filter, nil
+}
+
+// FindAllFilters retrieves all filters
+func (r *InMemoryFilterRepository) FindAllFilters(ctx context.Context) ([]*Filter, error) {
+	filters := []*Filter{}
+	for _, filter := range r.filters {
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
+}
+
+// StoreFilter inserts a new filter
+func (r *InMemoryFilterRepository) StoreFilter(ctx context.Context, name string, tags []string, done *bool) (*Filter, error) {
+	if tags == nil {
+		tags = []string{}
+	}
+
+	newFilter := &Filter{
+		BaseModel: common.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+		Name: name,
+		Tags: tags,
+		Done: done,
+	}
+
+	r.filters[newFilter.ID.Hex()] = newFilter
+
+	return newFilter, nil
+}
+
+// DeleteFilter removes a filter
+func (r *InMemoryFilterRepository) DeleteFilter(ctx context.Context, filterID string) (int64, error) {
+	if _, err := r.FindFilterByID(ctx, filterID); err != nil {
+		return -1, err
+	}
+
+	delete(r.filters, filterID)
+
+	return 1, nil
+}
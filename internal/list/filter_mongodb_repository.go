@@ -0,0 +1,92 @@
+package list
+
+import (
+	"context"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoDBFilterRepository contains all the methods to interact with the filters collection
+type MongoDBFilterRepository struct {
+	FiltersCollection *mongo.Collection
+}
+
+// NewMongoDBFilterRepository is a constructor for MongoDBFilterRepository
+func NewMongoDBFilterRepository(coll *mongo.Collection) FilterRepository {
+	return &MongoDBFilterRepository{
+		FiltersCollection: coll,
+	}
+}
+
+// FindFilterByID retrieves a filter based on its id
+func (r *MongoDBFilterRepository) FindFilterByID(ctx context.Context, id string) (*Filter, error) {
+	objectID, err := common.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter Filter
+	if err := r.FiltersCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&filter); err != nil {
+		return nil, common.MongoError(err, "filter %v", id)
+	}
+
+	return &filter, nil
+}
+
+// FindAllFilters retrieves all filters
+func (r *MongoDBFilterRepository) FindAllFilters(ctx context.Context) ([]*Filter, error) {
+	filters := []*Filter{}
+	cursor, err := r.FiltersCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(ctx, &filters); err != nil {
+		return nil, err
+	}
+
+	return filters, nil
+}
+
+// StoreFilter inserts a new filter
+func (r *MongoDBFilterRepository) StoreFilter(ctx context.Context, name string, tags []string, done *bool) (*Filter, error) {
+	if tags == nil {
+		tags = []string{}
+	}
+
+	filter := Filter{
+		BaseModel: common.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+		Name: name,
+		Tags: tags,
+		Done: done,
+	}
+
+	if _, err := r.FiltersCollection.InsertOne(ctx, filter); err != nil {
+		return nil, err
+	}
+
+	return &filter, nil
+}
+
+// DeleteFilter removes a filter
+func (r *MongoDBFilterRepository) DeleteFilter(ctx context.Context, id string) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(id)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.FiltersCollection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return -1, err
+	}
+
+	return result.DeletedCount, nil
+}
@@ -2,7 +2,6 @@ package list
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
@@ -11,13 +10,15 @@ import (
 
 // InMemoryRepository is an in-memory shoplist repository
 type InMemoryRepository struct {
-	lists map[string]*Shoppinglist
+	lists      map[string]*Shoppinglist
+	normalizer *Normalizer
 }
 
 // NewInMemoryRepository is a constructor of InMemoryRepository
-func NewInMemoryRepository() Repository {
+func NewInMemoryRepository(normalizer *Normalizer) Repository {
 	return &InMemoryRepository{
-		lists: make(map[string]*Shoppinglist),
+		lists:      make(map[string]*Shoppinglist),
+		normalizer: normalizer,
 	}
 }
 
@@ -25,7 +26,7 @@ func NewInMemoryRepository() Repository {
 func (r *InMemoryRepository) FindListByID(ctx context.Context, listID string) (*Shoppinglist, error) {
 	list, exists := r.lists[listID]
 	if !exists {
-		return nil, fmt.Errorf("there is no list with id %v", listID)
+		return nil, common.NewError(common.ErrNotFound, "there is no list with id %v", listID)
 	}
 
 	return list, nil
@@ -41,8 +42,8 @@ func (r *InMemoryRepository) FindAllLists(ctx context.Context) ([]*Shoppinglist,
 	return lists, nil
 }
 
-// StoreList inserts a new empty list
-func (r *InMemoryRepository) StoreList(ctx context.Context, listName string) (*Shoppinglist, error) {
+// StoreList inserts a new empty list owned by the user
+func (r *InMemoryRepository) StoreList(ctx context.Context, listName string, ownerID string) (*Shoppinglist, error) {
 	exists := false
 	for _, list := range r.lists {
 		if list.Name == listName {
@@ -52,7 +53,7 @@ func (r *InMemoryRepository) StoreList(ctx context.Context, listName string) (*S
 	}
 
 	if exists {
-		return nil, fmt.Errorf("A list already exists with the name %v", listName)
+		return nil, common.NewError(common.ErrConflict, "A list already exists with the name %v", listName)
 	}
 
 	newList := &Shoppinglist{
@@ -61,8 +62,10 @@ func (r *InMemoryRepository) StoreList(ctx context.Context, listName string) (*S
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		},
-		Items: []*Item{},
-		Name:  listName,
+		Items:   []*Item{},
+		Tags:    []string{},
+		Name:    listName,
+		OwnerID: ownerID,
 	}
 
 	r.lists[newList.ID.Hex()] = newList
@@ -70,6 +73,60 @@ func (r *InMemoryRepository) StoreList(ctx context.Context, listName string) (*S
 	return newList, nil
 }
 
+// FindListsByOwner retrieves the lists owned by the user
+func (r *InMemoryRepository) FindListsByOwner(ctx context.Context, ownerID string) ([]*Shoppinglist, error) {
+	lists := []*Shoppinglist{}
+	for _, list := range r.lists {
+		if list.OwnerID == ownerID {
+			lists = append(lists, list)
+		}
+	}
+
+	return lists, nil
+}
+
+// UpdateOwner sets the owner of the list
+func (r *InMemoryRepository) UpdateOwner(ctx context.Context, listID string, ownerID string) (int64, error) {
+	list, err := r.FindListByID(ctx, listID)
+	if err != nil {
+		return -1, err
+	}
+
+	list.OwnerID = ownerID
+
+	return 1, nil
+}
+
+// RenameList sets the name of the list
+func (r *InMemoryRepository) RenameList(ctx context.Context, listID string, name string) (int64, error) {
+	list, err := r.FindListByID(ctx, listID)
+	if err != nil {
+		return -1, err
+	}
+
+	list.Name = name
+	list.UpdatedAt = time.Now()
+
+	return 1, nil
+}
+
+// ReplaceList sets the name and the tags of the list, if they are still the current ones
+func (r *InMemoryRepository) ReplaceList(ctx context.Context, listID string, current *Shoppinglist, name string, tags []string) (int64, error) {
+	list, err := r.FindListByID(ctx, listID)
+	if err != nil {
+		return -1, err
+	}
+	if !list.sameState(current) {
+		return -1, listChangedError(listID)
+	}
+
+	list.Name = name
+	list.Tags = copyTags(tags)
+	list.UpdatedAt = time.Now()
+
+	return 1, nil
+}
+
 // DeleteList removes a list
 func (r *InMemoryRepository) DeleteList(ctx context.Context, listID string) (int64, error) {
 	list, err := r.FindListByID(ctx, listID)
@@ -83,22 +140,29 @@ func (r *InMemoryRepository) DeleteList(ctx context.Context, listID string) (int
 }
 
 // AddItem adds a new item to a list given by its id
-func (r *InMemoryRepository) AddItem(ctx context.Context, listID string, itemName string, itemQuantity string) (*Item, error) {
+func (r *InMemoryRepository) AddItem(ctx context.Context, listID string, itemName string, itemQuantity string) (*Item, bool, error) {
 	list, err := r.FindListByID(ctx, listID)
 	if err != nil {
-		return nil, err
+		return nil, false, err
+	}
+
+	if duplicate := list.FindDuplicate(itemName, r.normalizer); duplicate != nil {
+		return duplicate, true, nil
 	}
 
 	newitem := &Item{
-		Name:     itemName,
-		Quantity: itemQuantity,
-		Done:     false,
+		ID:          primitive.NewObjectID().Hex(),
+		Key:         r.normalizer.Normalize(itemName),
+		Name:        itemName,
+		Quantity:    itemQuantity,
+		Done:        false,
+		Attachments: []string{},
 	}
 
 	list.Items = append(list.Items, newitem)
 	list.UpdatedAt = time.Now()
 
-	return newitem, nil
+	return newitem, false, nil
 }
 
 // UpdateItem updates an item based on its name and quantity in a list given its id
@@ -108,21 +172,20 @@ func (r *InMemoryRepository) UpdateItem(ctx context.Context, listID string, item
 		return -1, err
 	}
 
-	found := false
-
-	for _, item := range list.Items {
-		if item.Name == itemName && item.Quantity == itemQuantity {
-			item.Name = itemNewName
-			item.Quantity = itemNewQuantity
-			found = true
-			break
-		}
+	items := findItems(list, itemName, itemQuantity)
+	if len(items) == 0 {
+		return -1, common.NewError(common.ErrNotFound, "Could not find any item matching the name %v and the quantity %v in the list %v", itemName, itemQuantity, listID)
 	}
+	item := items[0]
 
-	if !found {
-		return -1, fmt.Errorf("Could not find any item matching the name %v and the quantity %v in the list %v", itemName, itemQuantity, listID)
+	// a rename must not make the item a duplicate of another one
+	if list.FindDuplicate(itemNewName, r.normalizer, item) != nil {
+		return -1, duplicateItemError(listID, itemNewName)
 	}
 
+	item.Name = itemNewName
+	item.Key = r.normalizer.Normalize(itemNewName)
+	item.Quantity = itemNewQuantity
 	list.UpdatedAt = time.Now()
 
 	return 1, nil
@@ -146,7 +209,7 @@ func (r *InMemoryRepository) ToggleItem(ctx context.Context, listID string, item
 	}
 
 	if !found {
-		return -1, fmt.Errorf("Could not find any item matching the name %v and the quantity %v in the list %v", itemName, itemQuantity, listID)
+		return -1, common.NewError(common.ErrNotFound, "Could not find any item matching the name %v and the quantity %v in the list %v", itemName, itemQuantity, listID)
 	}
 
 	list.UpdatedAt = time.Now()
@@ -171,7 +234,7 @@ func (r *InMemoryRepository) RemoveItem(ctx context.Context, listID string, item
 	}
 
 	if index == -1 {
-		return -1, fmt.Errorf("Could not find any item matching the name %v and the quantity %v in the list %v", itemName, itemQuantity, listID)
+		return -1, common.NewError(common.ErrNotFound, "Could not find any item matching the name %v and the quantity %v in the list %v", itemName, itemQuantity, listID)
 	}
 
 	list.Items = append(list.Items[:index], list.Items[index+1:]...)
@@ -180,6 +243,52 @@ func (r *InMemoryRepository) RemoveItem(ctx context.Context, listID string, item
 	return 1, nil
 }
 
+// ReplaceItem replaces the name, the quantity and the done state of an item given by its id, if it is still the current one
+func (r *InMemoryRepository) ReplaceItem(ctx context.Context, listID string, current *Item, replacement *Item) (int64, error) {
+	list, err := r.FindListByID(ctx, listID)
+	if err != nil {
+		return -1, err
+	}
+
+	item := list.FindItem(current.ID)
+	if item == nil {
+		return -1, itemNotFoundError(listID, current.ID)
+	}
+	if !item.sameState(current) {
+		return -1, itemChangedError(listID, current.ID)
+	}
+	if list.FindDuplicate(replacement.Name, r.normalizer, item) != nil {
+		return -1, duplicateItemError(listID, replacement.Name)
+	}
+
+	item.Name = replacement.Name
+	item.Key = r.normalizer.Normalize(replacement.Name)
+	item.Quantity = replacement.Quantity
+	item.Done = replacement.Done
+	list.UpdatedAt = time.Now()
+
+	return 1, nil
+}
+
+// RemoveItemByID removes an item given by its id from a list
+func (r *InMemoryRepository) RemoveItemByID(ctx context.Context, listID string, itemID string) (int64, error) {
+	list, err := r.FindListByID(ctx, listID)
+	if err != nil {
+		return -1, err
+	}
+
+	for i, item := range list.Items {
+		if item.ID == itemID {
+			list.Items = append(list.Items[:i], list.Items[i+1:]...)
+			list.UpdatedAt = time.Now()
+
+			return 1, nil
+		}
+	}
+
+	return -1, itemNotFoundError(listID, itemID)
+}
+
 // RemoveAllItems removes all items from a list
 func (r *InMemoryRepository) RemoveAllItems(ctx context.Context, listID string) (int64, error) {
 	list, err := r.FindListByID(ctx, listID)
@@ -194,3 +303,120 @@ func (r *InMemoryRepository) RemoveAllItems(ctx context.Context, listID string)
 
 	return int64(n), nil
 }
+
+// AddTags adds the tags to a list given by its id. Tags that are already set are ignored
+func (r *InMemoryRepository) AddTags(ctx context.Context, listID string, tags ...string) (int64, error) {
+	list, err := r.FindListByID(ctx, listID)
+	if err != nil {
+		return -1, err
+	}
+
+	modified := false
+	for _, tag := range tags {
+		if !list.HasTag(tag) {
+			list.Tags = append(list.Tags, tag)
+			modified = true
+		}
+	}
+
+	if !modified {
+		return 0, nil
+	}
+
+	list.UpdatedAt = time.Now()
+
+	return 1, nil
+}
+
+// RemoveTags removes the tags from a list given by its id
+func (r *InMemoryRepository) RemoveTags(ctx context.Context, listID string, tags ...string) (int64, error) {
+	list, err := r.FindListByID(ctx, listID)
+	if err != nil {
+		return -1, err
+	}
+
+	toRemove := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		toRemove[tag] = true
+	}
+
+	remaining := []string{}
+	for _, tag := range list.Tags {
+		if !toRemove[tag] {
+			remaining = append(remaining, tag)
+		}
+	}
+
+	if len(remaining) == len(list.Tags) {
+		return 0, nil
+	}
+
+	list.Tags = remaining
+	list.UpdatedAt = time.Now()
+
+	return 1, nil
+}
+
+// EvaluateFilter retrieves the items of all lists matching the filter
+func (r *InMemoryRepository) EvaluateFilter(ctx context.Context, filter *Filter) ([]*FilteredItem, error) {
+	items := []*FilteredItem{}
+	for _, list := range r.lists {
+		if !filter.MatchesTags(list.Tags) {
+			continue
+		}
+
+		for _, item := range list.Items {
+			if filter.MatchesItem(item) {
+				items = append(items, &FilteredItem{
+					ListID:   list.ID.Hex(),
+					ListName: list.Name,
+					Item:     item,
+				})
+			}
+		}
+	}
+
+	return items, nil
+}
+
+// AddAttachment references an attachment from an item given by its name and quantity in a list given by its id
+func (r *InMemoryRepository) AddAttachment(ctx context.Context, listID string, itemName string, itemQuantity string, attachmentID string) (int64, error) {
+	list, err := r.FindListByID(ctx, listID)
+	if err != nil {
+		return -1, err
+	}
+
+	for _, item := range list.Items {
+		if item.Name == itemName && item.Quantity == itemQuantity {
+			item.Attachments = append(item.Attachments, attachmentID)
+			list.UpdatedAt = time.Now()
+			return 1, nil
+		}
+	}
+
+	return -1, common.NewError(common.ErrNotFound, "Could not find any item matching the name %v and the quantity %v in the list %v", itemName, itemQuantity, listID)
+}
+
+// RemoveAttachment removes the reference to an attachment from the item holding it in a list given by its id
+func (r *InMemoryRepository) RemoveAttachment(ctx context.Context, listID string, attachmentID string) (int64, error) {
+	list, err := r.FindListByID(ctx, listID)
+	if err != nil {
+		return -1, err
+	}
+
+	item := list.FindAttachment(attachmentID)
+	if item == nil {
+		return 0, nil
+	}
+
+	remaining := []string{}
+	for _, id := range item.Attachments {
+		if id != attachmentID {
+			remaining = append(remaining, id)
+		}
+	}
+	item.Attachments = remaining
+	list.UpdatedAt = time.Now()
+
+	return 1, nil
+}
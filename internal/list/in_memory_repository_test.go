@@ -0,0 +1,71 @@
+package list_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemsByID(t *testing.T) {
+	ctx := context.Background()
+	normalizer, err := list.NewNormalizer("trim", "casefold")
+	assert.NoError(t, err)
+	repo := list.NewInMemoryRepository(normalizer)
+
+	l, err := repo.StoreList(ctx, "groceries", "")
+	assert.NoError(t, err)
+	milk, _, err := repo.AddItem(ctx, l.ID.Hex(), "milk", "1L")
+	assert.NoError(t, err)
+	eggs, _, err := repo.AddItem(ctx, l.ID.Hex(), "eggs", "12")
+	assert.NoError(t, err)
+	current := *eggs
+
+	// the name, the quantity and the done state change at once
+	_, err = repo.ReplaceItem(ctx, l.ID.Hex(), &current, &list.Item{Name: "Eggs", Quantity: "6", Done: true})
+	assert.NoError(t, err)
+	assert.Equal(t, list.Item{ID: eggs.ID, Key: "eggs", Name: "Eggs", Quantity: "6", Done: true, Attachments: []string{}}, *eggs)
+
+	// the item changed since current was read
+	_, err = repo.ReplaceItem(ctx, l.ID.Hex(), &current, &list.Item{Name: "eggs", Quantity: "12"})
+	assert.ErrorIs(t, err, common.ErrConflict)
+	assert.Equal(t, "6", eggs.Quantity)
+
+	current = *eggs
+	_, err = repo.ReplaceItem(ctx, l.ID.Hex(), &current, &list.Item{Name: "Milk", Quantity: "6"})
+	assert.ErrorIs(t, err, common.ErrConflict)
+
+	_, err = repo.RemoveItemByID(ctx, l.ID.Hex(), eggs.ID)
+	assert.NoError(t, err)
+	_, err = repo.RemoveItemByID(ctx, l.ID.Hex(), eggs.ID)
+	assert.ErrorIs(t, err, common.ErrNotFound)
+	_, err = repo.ReplaceItem(ctx, l.ID.Hex(), &current, &list.Item{Name: "eggs"})
+	assert.ErrorIs(t, err, common.ErrNotFound)
+
+	l, err = repo.FindListByID(ctx, l.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, []*list.Item{milk}, l.Items)
+}
+
+func TestReplaceList(t *testing.T) {
+	ctx := context.Background()
+	normalizer, err := list.NewNormalizer("trim")
+	assert.NoError(t, err)
+	repo := list.NewInMemoryRepository(normalizer)
+
+	l, err := repo.StoreList(ctx, "groceries", "")
+	assert.NoError(t, err)
+	current := *l
+
+	_, err = repo.ReplaceList(ctx, l.ID.Hex(), &current, "weekend", []string{"weekly"})
+	assert.NoError(t, err)
+	assert.Equal(t, "weekend", l.Name)
+	assert.Equal(t, []string{"weekly"}, l.Tags)
+
+	// the list changed since current was read
+	_, err = repo.ReplaceList(ctx, l.ID.Hex(), &current, "groceries", nil)
+	assert.ErrorIs(t, err, common.ErrConflict)
+	assert.Equal(t, "weekend", l.Name)
+}
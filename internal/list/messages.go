@@ -1,6 +1,9 @@
 package list
 
-import "github.com/NicolasDutronc/shoppinglist-be/pkg/hub"
+import (
+	"github.com/NicolasDutronc/shoppinglist-be/internal/attachment"
+	"github.com/NicolasDutronc/shoppinglist-be/pkg/hub"
+)
 
 type newListMessage struct {
 	hub.BaseMessage
@@ -20,6 +23,16 @@ func (msg *deleteListMessage) GetType() string {
 	return "deleteListMessageType"
 }
 
+type renameListMessage struct {
+	hub.BaseMessage
+	ListID string `json:"listID"`
+	Name   string `json:"name"`
+}
+
+func (msg *renameListMessage) GetType() string {
+	return "renameListMessageType"
+}
+
 type addItemMessage struct {
 	hub.BaseMessage
 	Name     string `json:"name"`
@@ -72,3 +85,86 @@ type clearListMesssage struct {
 func (msg *clearListMesssage) GetType() string {
 	return "clearListMessageType"
 }
+
+type addTagsMessage struct {
+	hub.BaseMessage
+	ListID string   `json:"listID"`
+	Tags   []string `json:"tags"`
+}
+
+func (msg *addTagsMessage) GetType() string {
+	return "addTagsMessageType"
+}
+
+type removeTagsMessage struct {
+	hub.BaseMessage
+	ListID string   `json:"listID"`
+	Tags   []string `json:"tags"`
+}
+
+func (msg *removeTagsMessage) GetType() string {
+	return "removeTagsMessageType"
+}
+
+type addAttachmentMessage struct {
+	hub.BaseMessage
+	Name       string                 `json:"name"`
+	Quantity   string                 `json:"quantity"`
+	Attachment *attachment.Attachment `json:"attachment"`
+}
+
+func (msg *addAttachmentMessage) GetType() string {
+	return "addAttachmentMessageType"
+}
+
+type removeAttachmentMessage struct {
+	hub.BaseMessage
+	Name         string `json:"name"`
+	Quantity     string `json:"quantity"`
+	AttachmentID string `json:"attachmentID"`
+}
+
+func (msg *removeAttachmentMessage) GetType() string {
+	return "removeAttachmentMessageType"
+}
+
+type newFilterMessage struct {
+	hub.BaseMessage
+	NewFilter *Filter `json:"new_filter"`
+}
+
+func (msg *newFilterMessage) GetType() string {
+	return "newFilterMessageType"
+}
+
+type deleteFilterMessage struct {
+	hub.BaseMessage
+	FilterID string `json:"filterID"`
+}
+
+func (msg *deleteFilterMessage) GetType() string {
+	return "deleteFilterMessageType"
+}
+
+// filterUpdateMessage is sent to the subscribers of a filter when a change happened on an item it matches
+type filterUpdateMessage struct {
+	hub.BaseMessage
+	FilterID   string      `json:"filterID"`
+	ListID     string      `json:"listID"`
+	UpdateType string      `json:"update_type"`
+	Update     hub.Message `json:"update"`
+}
+
+func (msg *filterUpdateMessage) GetType() string {
+	return "filterUpdateMessageType"
+}
+
+// RestrictedTo makes the update reach only the subscribers of the filter who can follow the list it comes from
+func (msg *filterUpdateMessage) RestrictedTo() hub.Topic {
+	return hub.TopicFromString(msg.ListID)
+}
+
+// FilterTopic returns the hub topic on which the updates of a filter are published
+func FilterTopic(filterID string) hub.Topic {
+	return hub.TopicFromString("filter-" + filterID)
+}
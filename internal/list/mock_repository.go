@@ -13,8 +13,29 @@ type MockRepository struct {
 	mock.Mock
 }
 
+// AddAttachment provides a mock function with given fields: ctx, listID, itemName, itemQuantity, attachmentID
+func (_m *MockRepository) AddAttachment(ctx context.Context, listID string, itemName string, itemQuantity string, attachmentID string) (int64, error) {
+	ret := _m.Called(ctx, listID, itemName, itemQuantity, attachmentID)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) int64); ok {
+		r0 = rf(ctx, listID, itemName, itemQuantity, attachmentID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, string) error); ok {
+		r1 = rf(ctx, listID, itemName, itemQuantity, attachmentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AddItem provides a mock function with given fields: ctx, listID, itemName, itemQuantity
-func (_m *MockRepository) AddItem(ctx context.Context, listID string, itemName string, itemQuantity string) (*Item, error) {
+func (_m *MockRepository) AddItem(ctx context.Context, listID string, itemName string, itemQuantity string) (*Item, bool, error) {
 	ret := _m.Called(ctx, listID, itemName, itemQuantity)
 
 	var r0 *Item
@@ -26,9 +47,44 @@ func (_m *MockRepository) AddItem(ctx context.Context, listID string, itemName s
 		}
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) bool); ok {
 		r1 = rf(ctx, listID, itemName, itemQuantity)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, string) error); ok {
+		r2 = rf(ctx, listID, itemName, itemQuantity)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// AddTags provides a mock function with given fields: ctx, listID, tags
+func (_m *MockRepository) AddTags(ctx context.Context, listID string, tags ...string) (int64, error) {
+	_va := make([]interface{}, len(tags))
+	for _i := range tags {
+		_va[_i] = tags[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, listID)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) int64); ok {
+		r0 = rf(ctx, listID, tags...)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, ...string) error); ok {
+		r1 = rf(ctx, listID, tags...)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -57,6 +113,29 @@ func (_m *MockRepository) DeleteList(ctx context.Context, listID string) (int64,
 	return r0, r1
 }
 
+// EvaluateFilter provides a mock function with given fields: ctx, filter
+func (_m *MockRepository) EvaluateFilter(ctx context.Context, filter *Filter) ([]*FilteredItem, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*FilteredItem
+	if rf, ok := ret.Get(0).(func(context.Context, *Filter) []*FilteredItem); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*FilteredItem)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *Filter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindAllLists provides a mock function with given fields: ctx
 func (_m *MockRepository) FindAllLists(ctx context.Context) ([]*Shoppinglist, error) {
 	ret := _m.Called(ctx)
@@ -103,6 +182,29 @@ func (_m *MockRepository) FindListByID(ctx context.Context, listID string) (*Sho
 	return r0, r1
 }
 
+// FindListsByOwner provides a mock function with given fields: ctx, ownerID
+func (_m *MockRepository) FindListsByOwner(ctx context.Context, ownerID string) ([]*Shoppinglist, error) {
+	ret := _m.Called(ctx, ownerID)
+
+	var r0 []*Shoppinglist
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*Shoppinglist); ok {
+		r0 = rf(ctx, ownerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*Shoppinglist)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, ownerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RemoveAllItems provides a mock function with given fields: ctx, listID
 func (_m *MockRepository) RemoveAllItems(ctx context.Context, listID string) (int64, error) {
 	ret := _m.Called(ctx, listID)
@@ -124,6 +226,27 @@ func (_m *MockRepository) RemoveAllItems(ctx context.Context, listID string) (in
 	return r0, r1
 }
 
+// RemoveAttachment provides a mock function with given fields: ctx, listID, attachmentID
+func (_m *MockRepository) RemoveAttachment(ctx context.Context, listID string, attachmentID string) (int64, error) {
+	ret := _m.Called(ctx, listID, attachmentID)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int64); ok {
+		r0 = rf(ctx, listID, attachmentID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, listID, attachmentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RemoveItem provides a mock function with given fields: ctx, listID, itemName, itemQuantity
 func (_m *MockRepository) RemoveItem(ctx context.Context, listID string, itemName string, itemQuantity string) (int64, error) {
 	ret := _m.Called(ctx, listID, itemName, itemQuantity)
@@ -145,13 +268,125 @@ func (_m *MockRepository) RemoveItem(ctx context.Context, listID string, itemNam
 	return r0, r1
 }
 
-// StoreList provides a mock function with given fields: ctx, listName
-func (_m *MockRepository) StoreList(ctx context.Context, listName string) (*Shoppinglist, error) {
-	ret := _m.Called(ctx, listName)
+// RemoveItemByID provides a mock function with given fields: ctx, listID, itemID
+func (_m *MockRepository) RemoveItemByID(ctx context.Context, listID string, itemID string) (int64, error) {
+	ret := _m.Called(ctx, listID, itemID)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int64); ok {
+		r0 = rf(ctx, listID, itemID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, listID, itemID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveTags provides a mock function with given fields: ctx, listID, tags
+func (_m *MockRepository) RemoveTags(ctx context.Context, listID string, tags ...string) (int64, error) {
+	_va := make([]interface{}, len(tags))
+	for _i := range tags {
+		_va[_i] = tags[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, listID)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) int64); ok {
+		r0 = rf(ctx, listID, tags...)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, ...string) error); ok {
+		r1 = rf(ctx, listID, tags...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RenameList provides a mock function with given fields: ctx, listID, name
+func (_m *MockRepository) RenameList(ctx context.Context, listID string, name string) (int64, error) {
+	ret := _m.Called(ctx, listID, name)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int64); ok {
+		r0 = rf(ctx, listID, name)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, listID, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReplaceItem provides a mock function with given fields: ctx, listID, current, replacement
+func (_m *MockRepository) ReplaceItem(ctx context.Context, listID string, current *Item, replacement *Item) (int64, error) {
+	ret := _m.Called(ctx, listID, current, replacement)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, *Item, *Item) int64); ok {
+		r0 = rf(ctx, listID, current, replacement)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *Item, *Item) error); ok {
+		r1 = rf(ctx, listID, current, replacement)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReplaceList provides a mock function with given fields: ctx, listID, current, name, tags
+func (_m *MockRepository) ReplaceList(ctx context.Context, listID string, current *Shoppinglist, name string, tags []string) (int64, error) {
+	ret := _m.Called(ctx, listID, current, name, tags)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, *Shoppinglist, string, []string) int64); ok {
+		r0 = rf(ctx, listID, current, name, tags)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *Shoppinglist, string, []string) error); ok {
+		r1 = rf(ctx, listID, current, name, tags)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StoreList provides a mock function with given fields: ctx, listName, ownerID
+func (_m *MockRepository) StoreList(ctx context.Context, listName string, ownerID string) (*Shoppinglist, error) {
+	ret := _m.Called(ctx, listName, ownerID)
 
 	var r0 *Shoppinglist
-	if rf, ok := ret.Get(0).(func(context.Context, string) *Shoppinglist); ok {
-		r0 = rf(ctx, listName)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *Shoppinglist); ok {
+		r0 = rf(ctx, listName, ownerID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*Shoppinglist)
@@ -159,8 +394,8 @@ func (_m *MockRepository) StoreList(ctx context.Context, listName string) (*Shop
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, listName)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, listName, ownerID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -209,3 +444,24 @@ func (_m *MockRepository) UpdateItem(ctx context.Context, listID string, itemNam
 
 	return r0, r1
 }
+
+// UpdateOwner provides a mock function with given fields: ctx, listID, ownerID
+func (_m *MockRepository) UpdateOwner(ctx context.Context, listID string, ownerID string) (int64, error) {
+	ret := _m.Called(ctx, listID, ownerID)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int64); ok {
+		r0 = rf(ctx, listID, ownerID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, listID, ownerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -8,30 +8,33 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // MongoDBRepository contains all the methods to interact with the shoplist collection
 type MongoDBRepository struct {
 	ShoppinglistsCollection *mongo.Collection
+	Normalizer              *Normalizer
 }
 
 // NewMongoDBRepository is a constructor for MongoDBRepository
-func NewMongoDBRepository(coll *mongo.Collection) Repository {
+func NewMongoDBRepository(coll *mongo.Collection, normalizer *Normalizer) Repository {
 	return &MongoDBRepository{
 		ShoppinglistsCollection: coll,
+		Normalizer:              normalizer,
 	}
 }
 
 // FindListByID retrieves a list based on its id
 func (r *MongoDBRepository) FindListByID(ctx context.Context, id string) (*Shoppinglist, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+	objectID, err := common.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
 
 	var list Shoppinglist
 	if err := r.ShoppinglistsCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&list); err != nil {
-		return nil, err
+		return nil, common.MongoError(err, "list %v", id)
 	}
 
 	return &list, nil
@@ -52,16 +55,18 @@ func (r *MongoDBRepository) FindAllLists(ctx context.Context) ([]*Shoppinglist,
 	return lists, err
 }
 
-// StoreList inserts a new empty list
-func (r *MongoDBRepository) StoreList(ctx context.Context, name string) (*Shoppinglist, error) {
+// StoreList inserts a new empty list owned by the user
+func (r *MongoDBRepository) StoreList(ctx context.Context, name string, ownerID string) (*Shoppinglist, error) {
 	list := Shoppinglist{
 		BaseModel: common.BaseModel{
 			ID:        primitive.NewObjectID(),
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		},
-		Name:  name,
-		Items: []*Item{},
+		Name:    name,
+		OwnerID: ownerID,
+		Tags:    []string{},
+		Items:   []*Item{},
 	}
 
 	_, err := r.ShoppinglistsCollection.InsertOne(ctx, list)
@@ -72,9 +77,109 @@ func (r *MongoDBRepository) StoreList(ctx context.Context, name string) (*Shoppi
 	return &list, nil
 }
 
+// FindListsByOwner retrieves the lists owned by the user
+func (r *MongoDBRepository) FindListsByOwner(ctx context.Context, ownerID string) ([]*Shoppinglist, error) {
+	lists := []*Shoppinglist{}
+	cursor, err := r.ShoppinglistsCollection.Find(ctx, bson.M{"owner_id": ownerID})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(ctx, &lists); err != nil {
+		return nil, err
+	}
+
+	return lists, nil
+}
+
+// UpdateOwner sets the owner of the list
+func (r *MongoDBRepository) UpdateOwner(ctx context.Context, id string, ownerID string) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(id)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.ShoppinglistsCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "owner_id", Value: ownerID},
+			{Key: "updated_at", Value: time.Now()},
+		}}},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// RenameList sets the name of the list
+func (r *MongoDBRepository) RenameList(ctx context.Context, id string, name string) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(id)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.ShoppinglistsCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "name", Value: name},
+			{Key: "updated_at", Value: time.Now()},
+		}}},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// ReplaceList sets the name and the tags of the list in a single update, which only matches while they are the current ones
+func (r *MongoDBRepository) ReplaceList(ctx context.Context, id string, current *Shoppinglist, name string, tags []string) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(id)
+	if err != nil {
+		return -1, err
+	}
+
+	// the lists stored without tags have none or an empty array
+	var currentTags interface{} = current.Tags
+	if len(current.Tags) == 0 {
+		currentTags = bson.M{"$in": bson.A{nil, bson.A{}}}
+	}
+
+	result, err := r.ShoppinglistsCollection.UpdateOne(
+		ctx,
+		bson.M{
+			"_id":  objectID,
+			"name": current.Name,
+			"tags": currentTags,
+		},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "name", Value: name},
+			{Key: "tags", Value: tags},
+			{Key: "updated_at", Value: time.Now()},
+		}}},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	if result.MatchedCount == 0 {
+		if _, err := r.FindListByID(ctx, id); err != nil {
+			return -1, err
+		}
+
+		return -1, listChangedError(id)
+	}
+
+	return result.ModifiedCount, nil
+}
+
 // DeleteList removes a list
 func (r *MongoDBRepository) DeleteList(ctx context.Context, id string) (int64, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+	objectID, err := common.ObjectIDFromHex(id)
 	if err != nil {
 		return -1, err
 	}
@@ -87,64 +192,132 @@ func (r *MongoDBRepository) DeleteList(ctx context.Context, id string) (int64, e
 	return result.DeletedCount, nil
 }
 
-// AddItem adds a new item to a list given by its id
-func (r *MongoDBRepository) AddItem(ctx context.Context, id string, name string, quantity string) (*Item, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+// AddItem adds a new item to a list given by its id.
+// The item is only pushed if no item has its key, so that concurrent adds of duplicates insert a single item
+func (r *MongoDBRepository) AddItem(ctx context.Context, id string, name string, quantity string) (*Item, bool, error) {
+	objectID, err := common.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, err
+		return nil, false, err
+	}
+
+	// the items stored before the keys have none, they are compared by name
+	list, err := r.FindListByID(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if duplicate := list.FindDuplicate(name, r.Normalizer); duplicate != nil {
+		return duplicate, true, nil
 	}
 
 	newItem := Item{
-		Name:     name,
-		Quantity: quantity,
-		Done:     false,
+		ID:          primitive.NewObjectID().Hex(),
+		Key:         r.Normalizer.Normalize(name),
+		Name:        name,
+		Quantity:    quantity,
+		Done:        false,
+		Attachments: []string{},
 	}
-	_, err = r.ShoppinglistsCollection.UpdateOne(
+	result, err := r.ShoppinglistsCollection.UpdateOne(
 		ctx,
-		bson.M{"_id": objectID},
+		bson.M{
+			"_id":       objectID,
+			"items.key": bson.M{"$ne": newItem.Key},
+		},
 		bson.D{
-			{"$addToSet", bson.D{{"items", newItem}}},
-			{"$set", bson.D{{"updated_at", time.Now()}}},
+			{Key: "$push", Value: bson.D{{Key: "items", Value: newItem}}},
+			{Key: "$set", Value: bson.D{{Key: "updated_at", Value: time.Now()}}},
 		},
 	)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	return &newItem, nil
+	if result.MatchedCount == 0 {
+		// a duplicate was added in the meantime, or the list was deleted
+		list, err := r.FindListByID(ctx, id)
+		if err != nil {
+			return nil, false, err
+		}
+		if duplicate := list.FindDuplicate(name, r.Normalizer); duplicate != nil {
+			return duplicate, true, nil
+		}
+
+		return nil, false, duplicateItemError(id, name)
+	}
+
+	return &newItem, false, nil
 }
 
-// UpdateItem updates an item based on its name and quantity in a list given its id
+// UpdateItem updates an item based on its name and quantity in a list given its id.
+// The item cannot be renamed like another item of the list
 func (r *MongoDBRepository) UpdateItem(ctx context.Context, id string, name string, quantity string, newName string, newQuantity string) (int64, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+	objectID, err := common.ObjectIDFromHex(id)
 	if err != nil {
 		return -1, err
 	}
 
+	list, err := r.FindListByID(ctx, id)
+	if err != nil {
+		return -1, err
+	}
+
+	items := findItems(list, name, quantity)
+	if len(items) == 0 {
+		return -1, common.NewError(common.ErrNotFound, "Could not find any item matching the name %v and the quantity %v in the list %v", name, quantity, id)
+	}
+	item := items[0]
+
+	if list.FindDuplicate(newName, r.Normalizer, item) != nil {
+		return -1, duplicateItemError(id, newName)
+	}
+
+	newKey := r.Normalizer.Normalize(newName)
+	filter := bson.M{
+		"_id":      objectID,
+		"items.id": item.ID,
+	}
+	// the key only has to be free when it changes, the item holds it otherwise
+	if newKey != r.Normalizer.Normalize(item.Name) {
+		filter["items.key"] = bson.M{"$ne": newKey}
+	}
+
 	result, err := r.ShoppinglistsCollection.UpdateOne(
 		ctx,
-		bson.M{
-			"_id":            objectID,
-			"items.name":     name,
-			"items.quantity": quantity,
-		},
+		filter,
 		bson.D{
-			{"$set", bson.D{{"items.$.name", newName}}},
-			{"$set", bson.D{{"items.$.quantity", newQuantity}}},
-			{"$set", bson.D{{"updated_at", time.Now()}}},
+			{Key: "$set", Value: bson.D{
+				{Key: "items.$[item].name", Value: newName},
+				{Key: "items.$[item].key", Value: newKey},
+				{Key: "items.$[item].quantity", Value: newQuantity},
+				{Key: "updated_at", Value: time.Now()},
+			}},
 		},
+		options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"item.id": item.ID}}}),
 	)
-
 	if err != nil {
 		return -1, err
 	}
 
+	if result.MatchedCount == 0 {
+		// the item was removed or another item took the name in the meantime
+		list, err := r.FindListByID(ctx, id)
+		if err != nil {
+			return -1, err
+		}
+		if list.FindItem(item.ID) == nil {
+			return -1, itemNotFoundError(id, item.ID)
+		}
+
+		return -1, duplicateItemError(id, newName)
+	}
+
 	return result.ModifiedCount, nil
 }
 
 // ToggleItem changes the done boolean value of an item
 func (r *MongoDBRepository) ToggleItem(ctx context.Context, id string, name string, quantity string, done bool) (int64, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+	objectID, err := common.ObjectIDFromHex(id)
 	if err != nil {
 		return -1, err
 	}
@@ -170,7 +343,7 @@ func (r *MongoDBRepository) ToggleItem(ctx context.Context, id string, name stri
 
 // RemoveItem removes an item from a list
 func (r *MongoDBRepository) RemoveItem(ctx context.Context, id string, name string, quantity string) (int64, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+	objectID, err := common.ObjectIDFromHex(id)
 	if err != nil {
 		return -1, err
 	}
@@ -197,9 +370,118 @@ func (r *MongoDBRepository) RemoveItem(ctx context.Context, id string, name stri
 	return result.ModifiedCount, nil
 }
 
+// ReplaceItem replaces the name, the quantity and the done state of an item given by its id in a single update.
+// The update only matches while the item is in its current state and, when it is renamed, while no other item has its new key
+func (r *MongoDBRepository) ReplaceItem(ctx context.Context, id string, current *Item, replacement *Item) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(id)
+	if err != nil {
+		return -1, err
+	}
+
+	list, err := r.FindListByID(ctx, id)
+	if err != nil {
+		return -1, err
+	}
+
+	item := list.FindItem(current.ID)
+	if item == nil {
+		return -1, itemNotFoundError(id, current.ID)
+	}
+	if !item.sameState(current) {
+		return -1, itemChangedError(id, current.ID)
+	}
+	if list.FindDuplicate(replacement.Name, r.Normalizer, item) != nil {
+		return -1, duplicateItemError(id, replacement.Name)
+	}
+
+	newKey := r.Normalizer.Normalize(replacement.Name)
+	filter := bson.M{
+		"_id": objectID,
+		"items": bson.M{"$elemMatch": bson.M{
+			"id":       current.ID,
+			"name":     current.Name,
+			"quantity": current.Quantity,
+			"done":     current.Done,
+		}},
+	}
+	if newKey != r.Normalizer.Normalize(current.Name) {
+		filter["items.key"] = bson.M{"$ne": newKey}
+	}
+
+	result, err := r.ShoppinglistsCollection.UpdateOne(
+		ctx,
+		filter,
+		bson.D{
+			{Key: "$set", Value: bson.D{
+				{Key: "items.$[item].name", Value: replacement.Name},
+				{Key: "items.$[item].key", Value: newKey},
+				{Key: "items.$[item].quantity", Value: replacement.Quantity},
+				{Key: "items.$[item].done", Value: replacement.Done},
+				{Key: "updated_at", Value: time.Now()},
+			}},
+		},
+		options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"item.id": current.ID}}}),
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	if result.MatchedCount == 0 {
+		// the item was removed or changed, or another item took the name in the meantime
+		list, err := r.FindListByID(ctx, id)
+		if err != nil {
+			return -1, err
+		}
+		item := list.FindItem(current.ID)
+		if item == nil {
+			return -1, itemNotFoundError(id, current.ID)
+		}
+		if !item.sameState(current) {
+			return -1, itemChangedError(id, current.ID)
+		}
+
+		return -1, duplicateItemError(id, replacement.Name)
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// RemoveItemByID removes an item given by its id from a list
+func (r *MongoDBRepository) RemoveItemByID(ctx context.Context, id string, itemID string) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(id)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.ShoppinglistsCollection.UpdateOne(
+		ctx,
+		bson.M{
+			"_id":      objectID,
+			"items.id": itemID,
+		},
+		bson.D{
+			{Key: "$pull", Value: bson.D{{Key: "items", Value: bson.D{{Key: "id", Value: itemID}}}}},
+			{Key: "$set", Value: bson.D{{Key: "updated_at", Value: time.Now()}}},
+		},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	if result.MatchedCount == 0 {
+		if _, err := r.FindListByID(ctx, id); err != nil {
+			return -1, err
+		}
+
+		return -1, itemNotFoundError(id, itemID)
+	}
+
+	return result.ModifiedCount, nil
+}
+
 // RemoveAllItems removes all items from a list
 func (r *MongoDBRepository) RemoveAllItems(ctx context.Context, id string) (int64, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+	objectID, err := common.ObjectIDFromHex(id)
 	if err != nil {
 		return -1, err
 	}
@@ -218,3 +500,139 @@ func (r *MongoDBRepository) RemoveAllItems(ctx context.Context, id string) (int6
 
 	return result.ModifiedCount, nil
 }
+
+// AddTags adds the tags to a list given by its id. Tags that are already set are ignored
+func (r *MongoDBRepository) AddTags(ctx context.Context, id string, tags ...string) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(id)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.ShoppinglistsCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.D{
+			{Key: "$addToSet", Value: bson.D{{Key: "tags", Value: bson.D{{Key: "$each", Value: tags}}}}},
+			{Key: "$set", Value: bson.D{{Key: "updated_at", Value: time.Now()}}},
+		},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// RemoveTags removes the tags from a list given by its id
+func (r *MongoDBRepository) RemoveTags(ctx context.Context, id string, tags ...string) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(id)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.ShoppinglistsCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.D{
+			{Key: "$pull", Value: bson.D{{Key: "tags", Value: bson.D{{Key: "$in", Value: tags}}}}},
+			{Key: "$set", Value: bson.D{{Key: "updated_at", Value: time.Now()}}},
+		},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// EvaluateFilter retrieves the items of all lists matching the filter
+func (r *MongoDBRepository) EvaluateFilter(ctx context.Context, filter *Filter) ([]*FilteredItem, error) {
+	listMatch := bson.M{}
+	if len(filter.Tags) > 0 {
+		listMatch["tags"] = bson.M{"$in": filter.Tags}
+	}
+
+	itemMatch := bson.M{}
+	if filter.Done != nil {
+		itemMatch["items.done"] = *filter.Done
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: listMatch}},
+		{{Key: "$unwind", Value: "$items"}},
+		{{Key: "$match", Value: itemMatch}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: "list_id", Value: bson.D{{Key: "$toString", Value: "$_id"}}},
+			{Key: "list_name", Value: "$name"},
+			{Key: "item", Value: "$items"},
+		}}},
+	}
+
+	cursor, err := r.ShoppinglistsCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []*FilteredItem{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// AddAttachment references an attachment from an item given by its name and quantity in a list given by its id
+func (r *MongoDBRepository) AddAttachment(ctx context.Context, id string, name string, quantity string, attachmentID string) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(id)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.ShoppinglistsCollection.UpdateOne(
+		ctx,
+		bson.M{
+			"_id": objectID,
+			"items": bson.M{
+				"$elemMatch": bson.M{
+					"name":     name,
+					"quantity": quantity,
+				},
+			},
+		},
+		bson.D{
+			{Key: "$push", Value: bson.D{{Key: "items.$.attachments", Value: attachmentID}}},
+			{Key: "$set", Value: bson.D{{Key: "updated_at", Value: time.Now()}}},
+		},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// RemoveAttachment removes the reference to an attachment from the item holding it in a list given by its id
+func (r *MongoDBRepository) RemoveAttachment(ctx context.Context, id string, attachmentID string) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(id)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.ShoppinglistsCollection.UpdateOne(
+		ctx,
+		bson.M{
+			"_id":               objectID,
+			"items.attachments": attachmentID,
+		},
+		bson.D{
+			{Key: "$pull", Value: bson.D{{Key: "items.$.attachments", Value: attachmentID}}},
+			{Key: "$set", Value: bson.D{{Key: "updated_at", Value: time.Now()}}},
+		},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,140 @@
+package list
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizationStep transforms an item name into a more canonical form
+type NormalizationStep func(name string) string
+
+// normalizationSteps are the steps that can be configured, by name
+var normalizationSteps = map[string]NormalizationStep{
+	"trim":        Trim,
+	"casefold":    CaseFold,
+	"accents":     StripAccents,
+	"singular_en": eachWord(SingularizeEnglish),
+	"singular_fr": eachWord(SingularizeFrench),
+}
+
+// Normalizer is a pipeline of steps computing the key of an item name.
+// Two items whose names have the same key are considered duplicates
+type Normalizer struct {
+	steps []NormalizationStep
+}
+
+// NewNormalizer builds a normalizer applying the steps given by their names in order.
+// The available steps are trim, casefold, accents, singular_en and singular_fr
+func NewNormalizer(steps ...string) (*Normalizer, error) {
+	normalizer := &Normalizer{
+		steps: make([]NormalizationStep, len(steps)),
+	}
+	for i, name := range steps {
+		step, exists := normalizationSteps[name]
+		if !exists {
+			return nil, fmt.Errorf("unknown normalization step %v", name)
+		}
+		normalizer.steps[i] = step
+	}
+
+	return normalizer, nil
+}
+
+// Normalize returns the key of the given item name. A nil normalizer returns the name unchanged
+func (n *Normalizer) Normalize(name string) string {
+	if n == nil {
+		return name
+	}
+
+	for _, step := range n.steps {
+		name = step(name)
+	}
+
+	return name
+}
+
+// Trim removes the leading and trailing spaces and collapses the inner ones
+func Trim(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// CaseFold lowercases the name
+func CaseFold(name string) string {
+	return strings.ToLower(name)
+}
+
+// StripAccents removes the diacritics of the name, turning "crème brûlée" into "creme brulee"
+func StripAccents(name string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(name) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+
+	return norm.NFC.String(b.String())
+}
+
+// oesPlurals are the plurals in "oes" whose singular ends with "o". The other ones, like "shoes", only lose the "s"
+var oesPlurals = map[string]bool{
+	"potatoes":  true,
+	"tomatoes":  true,
+	"heroes":    true,
+	"mangoes":   true,
+	"avocadoes": true,
+	"echoes":    true,
+}
+
+// SingularizeEnglish turns a lowercased english plural into its singular using the most common rules
+func SingularizeEnglish(word string) string {
+	switch {
+	case len(word) <= 3:
+		return word
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		return strings.TrimSuffix(word, "ies") + "y"
+	case oesPlurals[word]:
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"), strings.HasSuffix(word, "sses"),
+		strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "zes"):
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+		return word
+	case strings.HasSuffix(word, "s"):
+		return strings.TrimSuffix(word, "s")
+	default:
+		return word
+	}
+}
+
+// SingularizeFrench turns a lowercased french plural into its singular using the most common rules
+func SingularizeFrench(word string) string {
+	switch {
+	case len(word) <= 3:
+		return word
+	case strings.HasSuffix(word, "eaux"), strings.HasSuffix(word, "eux"), strings.HasSuffix(word, "oux"):
+		return strings.TrimSuffix(word, "x")
+	case strings.HasSuffix(word, "aux"):
+		return strings.TrimSuffix(word, "aux") + "al"
+	case strings.HasSuffix(word, "ss"):
+		return word
+	case strings.HasSuffix(word, "s"):
+		return strings.TrimSuffix(word, "s")
+	default:
+		return word
+	}
+}
+
+// eachWord applies a word transformation to every word of the name
+func eachWord(step NormalizationStep) NormalizationStep {
+	return func(name string) string {
+		words := strings.Fields(name)
+		for i, word := range words {
+			words[i] = step(word)
+		}
+
+		return strings.Join(words, " ")
+	}
+}
@@ -0,0 +1,91 @@
+package list_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizer(t *testing.T) {
+	normalizer, err := list.NewNormalizer("trim", "casefold", "accents", "singular_en", "singular_fr")
+	assert.NoError(t, err)
+
+	cases := map[string]string{
+		"Tomatoes":        "tomato",
+		"tomatoes ":       "tomato",
+		"tomato":          "tomato",
+		"  Crème  brûlée": "creme brulee",
+		"Gâteaux":         "gateau",
+		"chevaux":         "cheval",
+		"berries":         "berry",
+		"Peaches":         "peach",
+		"glass":           "glass",
+		"riz":             "riz",
+		"Pommes de terre": "pomme de terre",
+		"potatoes":        "potato",
+		"heroes":          "hero",
+		"shoes":           "shoe",
+		"toes":            "toe",
+	}
+	for name, expected := range cases {
+		assert.Equal(t, expected, normalizer.Normalize(name), name)
+	}
+
+	_, err = list.NewNormalizer("trim", "stemming")
+	assert.Error(t, err)
+}
+
+func TestAddItemMergesDuplicates(t *testing.T) {
+	ctx := context.Background()
+	normalizer, err := list.NewNormalizer("trim", "casefold", "singular_en")
+	assert.NoError(t, err)
+	repo := list.NewInMemoryRepository(normalizer)
+
+	l, err := repo.StoreList(ctx, "groceries", "")
+	assert.NoError(t, err)
+
+	first, merged, err := repo.AddItem(ctx, l.ID.Hex(), "Tomatoes", "1kg")
+	assert.NoError(t, err)
+	assert.False(t, merged)
+
+	for _, name := range []string{"tomatoes ", "tomato"} {
+		item, merged, err := repo.AddItem(ctx, l.ID.Hex(), name, "500g")
+		assert.NoError(t, err)
+		assert.True(t, merged)
+		assert.Same(t, first, item)
+	}
+
+	l, err = repo.FindListByID(ctx, l.ID.Hex())
+	assert.NoError(t, err)
+	assert.Len(t, l.Items, 1)
+}
+
+func TestUpdateItemRejectsDuplicates(t *testing.T) {
+	ctx := context.Background()
+	normalizer, err := list.NewNormalizer("trim", "casefold")
+	assert.NoError(t, err)
+	repo := list.NewInMemoryRepository(normalizer)
+
+	l, err := repo.StoreList(ctx, "groceries", "")
+	assert.NoError(t, err)
+	_, _, err = repo.AddItem(ctx, l.ID.Hex(), "Milk", "1L")
+	assert.NoError(t, err)
+	_, _, err = repo.AddItem(ctx, l.ID.Hex(), "eggs", "12")
+	assert.NoError(t, err)
+
+	// renaming the eggs like the milk would make a duplicate
+	_, err = repo.UpdateItem(ctx, l.ID.Hex(), "eggs", "12", "milk ", "12")
+	assert.ErrorIs(t, err, common.ErrConflict)
+
+	// an item can still be renamed with the same key or get another quantity
+	_, err = repo.UpdateItem(ctx, l.ID.Hex(), "Milk", "1L", "milk", "2L")
+	assert.NoError(t, err)
+
+	l, err = repo.FindListByID(ctx, l.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, "eggs", l.Items[1].Name)
+	assert.Equal(t, "milk", l.Items[0].Key)
+}
@@ -14,9 +14,26 @@ type Finder interface {
 	FindAllLists(ctx context.Context) ([]*Shoppinglist, error)
 }
 
-// Creator is a single method interface for creating a list
+// Creator is a single method interface for creating a list owned by a user
 type Creator interface {
-	StoreList(ctx context.Context, listName string) (*Shoppinglist, error)
+	StoreList(ctx context.Context, listName string, ownerID string) (*Shoppinglist, error)
+}
+
+// OwnershipUpdater defines the operations on the owners of the lists
+type OwnershipUpdater interface {
+	FindListsByOwner(ctx context.Context, ownerID string) ([]*Shoppinglist, error)
+	UpdateOwner(ctx context.Context, listID string, ownerID string) (int64, error)
+}
+
+// Renamer is a single method interface for renaming a list
+type Renamer interface {
+	RenameList(ctx context.Context, listID string, name string) (int64, error)
+}
+
+// ListReplacer is a single method interface for replacing the name and the tags of a list.
+// The list is only changed while its name and its tags are the current ones, so that a change made in the meantime is not overwritten
+type ListReplacer interface {
+	ReplaceList(ctx context.Context, listID string, current *Shoppinglist, name string, tags []string) (int64, error)
 }
 
 // Deleter is a single method interface for deleting a list
@@ -24,9 +41,10 @@ type Deleter interface {
 	DeleteList(ctx context.Context, listID string) (int64, error)
 }
 
-// ItemAdder is a single method interface for adding an item to a list
+// ItemAdder is a single method interface for adding an item to a list.
+// When the name is a duplicate of an existing item, the existing item is returned along with true
 type ItemAdder interface {
-	AddItem(ctx context.Context, listID string, itemName string, itemQuantity string) (*Item, error)
+	AddItem(ctx context.Context, listID string, itemName string, itemQuantity string) (*Item, bool, error)
 }
 
 // ItemUpdater is a single method interface for updating an item inside a list
@@ -44,20 +62,84 @@ type ItemRemover interface {
 	RemoveItem(ctx context.Context, listID string, itemName string, itemQuantity string) (int64, error)
 }
 
+// ItemReplacer is a single method interface for replacing the name, the quantity and the done state of an item given by its id.
+// The item is only replaced while it is still the current one, so that a change made in the meantime is not overwritten
+type ItemReplacer interface {
+	ReplaceItem(ctx context.Context, listID string, current *Item, replacement *Item) (int64, error)
+}
+
+// ItemByIDRemover is a single method interface for removing an item given by its id from a list
+type ItemByIDRemover interface {
+	RemoveItemByID(ctx context.Context, listID string, itemID string) (int64, error)
+}
+
 // Clearer is a single method interface for clearing all items from a list
 type Clearer interface {
 	RemoveAllItems(ctx context.Context, listID string) (int64, error)
 }
 
+// Tagger defines the tags operations on a list
+type Tagger interface {
+	AddTags(ctx context.Context, listID string, tags ...string) (int64, error)
+	RemoveTags(ctx context.Context, listID string, tags ...string) (int64, error)
+}
+
+// FilterEvaluator is a single method interface for retrieving the items of all lists matching a filter
+type FilterEvaluator interface {
+	EvaluateFilter(ctx context.Context, filter *Filter) ([]*FilteredItem, error)
+}
+
+// AttachmentLinker defines the operations referencing attachments from the items of a list
+type AttachmentLinker interface {
+	AddAttachment(ctx context.Context, listID string, itemName string, itemQuantity string, attachmentID string) (int64, error)
+	RemoveAttachment(ctx context.Context, listID string, attachmentID string) (int64, error)
+}
+
 // Repository is a wrapper around all the single method interfaces defining the service
 type Repository interface {
 	FinderByID
 	Finder
 	Creator
+	OwnershipUpdater
+	Renamer
+	ListReplacer
 	Deleter
 	ItemAdder
 	ItemUpdater
 	ItemToggler
 	ItemRemover
+	ItemReplacer
+	ItemByIDRemover
 	Clearer
+	Tagger
+	FilterEvaluator
+	AttachmentLinker
+}
+
+// FilterFinderByID is a single method interface for finding a filter by id
+type FilterFinderByID interface {
+	FindFilterByID(ctx context.Context, filterID string) (*Filter, error)
+}
+
+// FilterFinder is a single method interface for listing the filters
+type FilterFinder interface {
+	FindAllFilters(ctx context.Context) ([]*Filter, error)
+}
+
+// FilterCreator is a single method interface for creating a filter
+type FilterCreator interface {
+	StoreFilter(ctx context.Context, name string, tags []string, done *bool) (*Filter, error)
+}
+
+// FilterDeleter is a single method interface for deleting a filter
+type FilterDeleter interface {
+	DeleteFilter(ctx context.Context, filterID string) (int64, error)
+}
+
+// FilterRepository is a wrapper around all the single method interfaces defining the saved filters storage
+type FilterRepository interface {
+	FilterFinderByID
+	FilterFinder
+	FilterCreator
+	FilterDeleter
 }
@@ -2,22 +2,29 @@ package list
 
 import (
 	"context"
+	"io"
 	"time"
 
+	"github.com/NicolasDutronc/shoppinglist-be/internal/attachment"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
 	"github.com/NicolasDutronc/shoppinglist-be/pkg/hub"
 )
 
 // ServiceImpl is the implementation of the Service interface
 type ServiceImpl struct {
-	repository Repository
-	h          hub.Hub
+	repository  Repository
+	filters     FilterRepository
+	attachments attachment.Storage
+	h           hub.Hub
 }
 
-// NewService returns a Shoppinglist service based on a shoplist repository, and a hub
-func NewService(repo Repository, h hub.Hub) Service {
+// NewService returns a Shoppinglist service based on a shoplist repository, a filter repository, an attachment storage and a hub
+func NewService(repo Repository, filterRepo FilterRepository, attachments attachment.Storage, h hub.Hub) Service {
 	return &ServiceImpl{
-		repository: repo,
-		h:          h,
+		repository:  repo,
+		filters:     filterRepo,
+		attachments: attachments,
+		h:           h,
 	}
 }
 
@@ -31,9 +38,13 @@ func (s *ServiceImpl) FindAllLists(ctx context.Context) ([]*Shoppinglist, error)
 	return s.repository.FindAllLists(ctx)
 }
 
-// StoreList inserts a new empty list
-func (s *ServiceImpl) StoreList(ctx context.Context, listName string) (*Shoppinglist, error) {
-	list, err := s.repository.StoreList(ctx, listName)
+// StoreList inserts a new empty list owned by the user
+func (s *ServiceImpl) StoreList(ctx context.Context, listName string, ownerID string) (*Shoppinglist, error) {
+	if err := checkName(listName); err != nil {
+		return nil, err
+	}
+
+	list, err := s.repository.StoreList(ctx, listName, ownerID)
 	if err != nil {
 		return nil, err
 	}
@@ -52,13 +63,116 @@ func (s *ServiceImpl) StoreList(ctx context.Context, listName string) (*Shopping
 	return list, nil
 }
 
-// DeleteList removes a list
+// FindListsByOwner retrieves the lists owned by the user
+func (s *ServiceImpl) FindListsByOwner(ctx context.Context, ownerID string) ([]*Shoppinglist, error) {
+	return s.repository.FindListsByOwner(ctx, ownerID)
+}
+
+// TransferList makes another user the owner of the list
+func (s *ServiceImpl) TransferList(ctx context.Context, listID string, newOwnerID string) (int64, error) {
+	return s.repository.UpdateOwner(ctx, listID, newOwnerID)
+}
+
+// RenameList sets the name of a list and notifies its subscribers
+func (s *ServiceImpl) RenameList(ctx context.Context, listID string, name string) (int64, error) {
+	if err := checkName(name); err != nil {
+		return -1, err
+	}
+
+	n, err := s.repository.RenameList(ctx, listID, name)
+	if err != nil {
+		return -1, err
+	}
+
+	if err := s.h.Publish(ctx, &renameListMessage{
+		BaseMessage: hub.NewBaseMessage(time.Now().Unix(), hub.TopicFromString(listID)),
+		ListID:      listID,
+		Name:        name,
+	}); err != nil {
+		return -1, err
+	}
+
+	return n, nil
+}
+
+// ReplaceList sets the name and the tags of a list in a single update.
+// The subscribers and the filters are notified like for a rename and the tags operations
+func (s *ServiceImpl) ReplaceList(ctx context.Context, listID string, current *Shoppinglist, name string, tags []string) (int64, error) {
+	if err := checkName(name); err != nil {
+		return -1, err
+	}
+	// the repository may change the current list, the messages need its state before the update
+	currentName, currentTags := current.Name, copyTags(current.Tags)
+	added, removed := tagsDifference(currentTags, tags), tagsDifference(tags, currentTags)
+
+	n, err := s.repository.ReplaceList(ctx, listID, current, name, tags)
+	if err != nil {
+		return -1, err
+	}
+
+	if name != currentName {
+		if err := s.h.Publish(ctx, &renameListMessage{
+			BaseMessage: hub.NewBaseMessage(time.Now().Unix(), hub.TopicFromString(listID)),
+			ListID:      listID,
+			Name:        name,
+		}); err != nil {
+			return -1, err
+		}
+	}
+
+	list, err := s.repository.FindListByID(ctx, listID)
+	if err != nil {
+		return -1, err
+	}
+
+	if len(added) > 0 {
+		msg := &addTagsMessage{
+			BaseMessage: hub.NewBaseMessage(time.Now().Unix(), hub.TopicFromString(listID)),
+			ListID:      listID,
+			Tags:        added,
+		}
+		if err := s.h.Publish(ctx, msg); err != nil {
+			return -1, err
+		}
+		if err := s.notifyFilters(ctx, listID, added, list.Items, msg); err != nil {
+			return -1, err
+		}
+	}
+
+	if len(removed) > 0 {
+		msg := &removeTagsMessage{
+			BaseMessage: hub.NewBaseMessage(time.Now().Unix(), hub.TopicFromString(listID)),
+			ListID:      listID,
+			Tags:        removed,
+		}
+		if err := s.h.Publish(ctx, msg); err != nil {
+			return -1, err
+		}
+		if err := s.notifyFilters(ctx, listID, removed, list.Items, msg); err != nil {
+			return -1, err
+		}
+	}
+
+	return n, nil
+}
+
+// DeleteList removes a list along with the attachments of its items
 func (s *ServiceImpl) DeleteList(ctx context.Context, listID string) (int64, error) {
+	list, err := s.repository.FindListByID(ctx, listID)
+	if err != nil {
+		return -1, err
+	}
+	attachmentIDs := collectAttachments(list.Items)
+
 	n, err := s.repository.DeleteList(ctx, listID)
 	if err != nil {
 		return -1, err
 	}
 
+	if err := s.attachments.Delete(ctx, attachmentIDs...); err != nil {
+		return -1, err
+	}
+
 	if err := s.h.DeleteTopic(ctx, hub.TopicFromString(listID)); err != nil {
 		return -1, err
 	}
@@ -73,39 +187,87 @@ func (s *ServiceImpl) DeleteList(ctx context.Context, listID string) (int64, err
 	return n, nil
 }
 
-// AddItem adds a new item to a list given by its id
-func (s *ServiceImpl) AddItem(ctx context.Context, listID string, itemName string, itemQuantity string) (*Item, error) {
-	item, err := s.repository.AddItem(ctx, listID, itemName, itemQuantity)
+// FindItem retrieves an item of a list given by their ids
+func (s *ServiceImpl) FindItem(ctx context.Context, listID string, itemID string) (*Item, error) {
+	list, err := s.repository.FindListByID(ctx, listID)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := s.h.Publish(ctx, &addItemMessage{
+	item := list.FindItem(itemID)
+	if item == nil {
+		return nil, itemNotFoundError(listID, itemID)
+	}
+
+	return item, nil
+}
+
+// AddItem adds a new item to a list given by its id.
+// A duplicate of an existing item is merged into it : nothing changes and the existing item is returned
+func (s *ServiceImpl) AddItem(ctx context.Context, listID string, itemName string, itemQuantity string) (*Item, bool, error) {
+	if err := checkName(itemName); err != nil {
+		return nil, false, err
+	}
+
+	item, merged, err := s.repository.AddItem(ctx, listID, itemName, itemQuantity)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if merged {
+		return item, true, nil
+	}
+
+	msg := &addItemMessage{
 		BaseMessage: hub.NewBaseMessage(time.Now().Unix(), hub.TopicFromString(listID)),
 		Name:        itemName,
 		Quantity:    itemQuantity,
 		Done:        false,
-	}); err != nil {
-		return nil, err
+	}
+	if err := s.h.Publish(ctx, msg); err != nil {
+		return nil, false, err
 	}
 
-	return item, nil
+	list, err := s.repository.FindListByID(ctx, listID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := s.notifyFilters(ctx, listID, list.Tags, []*Item{item}, msg); err != nil {
+		return nil, false, err
+	}
+
+	return item, false, nil
 }
 
 // UpdateItem updates an item based on its name and quantity in a list given its id
 func (s *ServiceImpl) UpdateItem(ctx context.Context, listID string, itemName string, itemQuantity string, itemNewName string, itemNewQuantity string) (int64, error) {
+	if err := checkName(itemNewName); err != nil {
+		return -1, err
+	}
+
 	n, err := s.repository.UpdateItem(ctx, listID, itemName, itemQuantity, itemNewName, itemNewQuantity)
 	if err != nil {
 		return -1, err
 	}
 
-	if err := s.h.Publish(ctx, &updateItemMessage{
+	msg := &updateItemMessage{
 		BaseMessage: hub.NewBaseMessage(time.Now().Unix(), hub.TopicFromString(listID)),
 		Name:        itemName,
 		Quantity:    itemQuantity,
 		NewName:     itemNewName,
 		NewQuantity: itemNewQuantity,
-	}); err != nil {
+	}
+	if err := s.h.Publish(ctx, msg); err != nil {
+		return -1, err
+	}
+
+	list, err := s.repository.FindListByID(ctx, listID)
+	if err != nil {
+		return -1, err
+	}
+
+	if err := s.notifyFilters(ctx, listID, list.Tags, findItems(list, itemNewName, itemNewQuantity), msg); err != nil {
 		return -1, err
 	}
 
@@ -116,52 +278,484 @@ func (s *ServiceImpl) UpdateItem(ctx context.Context, listID string, itemName st
 func (s *ServiceImpl) ToggleItem(ctx context.Context, listID string, itemName string, itemQuantity string, itemDone bool) (int64, error) {
 	n, err := s.repository.ToggleItem(ctx, listID, itemName, itemQuantity, itemDone)
 	if err != nil {
-		return -1, nil
+		return -1, err
 	}
 
-	if err := s.h.Publish(ctx, &toggleItemMessage{
+	msg := &toggleItemMessage{
 		BaseMessage: hub.NewBaseMessage(time.Now().Unix(), hub.TopicFromString(listID)),
 		Name:        itemName,
 		Quantity:    itemQuantity,
 		Value:       itemDone,
-	}); err != nil {
+	}
+	if err := s.h.Publish(ctx, msg); err != nil {
+		return -1, err
+	}
+
+	list, err := s.repository.FindListByID(ctx, listID)
+	if err != nil {
+		return -1, err
+	}
+
+	// the item may enter or leave a filter, so both states are checked
+	states := []*Item{
+		{Name: itemName, Quantity: itemQuantity, Done: !itemDone},
+		{Name: itemName, Quantity: itemQuantity, Done: itemDone},
+	}
+	if err := s.notifyFilters(ctx, listID, list.Tags, states, msg); err != nil {
 		return -1, err
 	}
 
 	return n, nil
 }
 
-// RemoveItem removes an item from a list
+// RemoveItem removes an item from a list along with its attachments
 func (s *ServiceImpl) RemoveItem(ctx context.Context, listID string, itemName string, itemQuantity string) (int64, error) {
+	list, err := s.repository.FindListByID(ctx, listID)
+	if err != nil {
+		return -1, err
+	}
+	tags := copyTags(list.Tags)
+	removed := copyItems(findItems(list, itemName, itemQuantity))
+
 	n, err := s.repository.RemoveItem(ctx, listID, itemName, itemQuantity)
 	if err != nil {
 		return -1, err
 	}
 
-	if err := s.h.Publish(ctx, &deleteItemMessage{
+	if err := s.attachments.Delete(ctx, collectAttachments(removed)...); err != nil {
+		return -1, err
+	}
+
+	msg := &deleteItemMessage{
 		BaseMessage: hub.NewBaseMessage(time.Now().Unix(), hub.TopicFromString(listID)),
 		Name:        itemName,
 		Quantity:    itemQuantity,
-	}); err != nil {
+	}
+	if err := s.h.Publish(ctx, msg); err != nil {
+		return -1, err
+	}
+
+	if err := s.notifyFilters(ctx, listID, tags, removed, msg); err != nil {
 		return -1, err
 	}
 
 	return n, nil
 }
 
-// RemoveAllItems removes all items from a list
+// ReplaceItem replaces the name, the quantity and the done state of an item given by its id in a single update.
+// The subscribers are notified like for an update and a toggle
+func (s *ServiceImpl) ReplaceItem(ctx context.Context, listID string, current *Item, replacement *Item) (int64, error) {
+	if err := checkName(replacement.Name); err != nil {
+		return -1, err
+	}
+	// the repository may change the current item, the messages need its state before the update
+	states := copyItems([]*Item{current, replacement})
+	before, after := states[0], states[1]
+
+	n, err := s.repository.ReplaceItem(ctx, listID, current, replacement)
+	if err != nil {
+		return -1, err
+	}
+
+	messages := []hub.Message{}
+	if after.Name != before.Name || after.Quantity != before.Quantity {
+		messages = append(messages, &updateItemMessage{
+			BaseMessage: hub.NewBaseMessage(time.Now().Unix(), hub.TopicFromString(listID)),
+			Name:        before.Name,
+			Quantity:    before.Quantity,
+			NewName:     after.Name,
+			NewQuantity: after.Quantity,
+		})
+	}
+	if after.Done != before.Done {
+		messages = append(messages, &toggleItemMessage{
+			BaseMessage: hub.NewBaseMessage(time.Now().Unix(), hub.TopicFromString(listID)),
+			Name:        after.Name,
+			Quantity:    after.Quantity,
+			Value:       after.Done,
+		})
+	}
+
+	list, err := s.repository.FindListByID(ctx, listID)
+	if err != nil {
+		return -1, err
+	}
+
+	// the item may enter or leave a filter, so both states are checked
+	for _, msg := range messages {
+		if err := s.h.Publish(ctx, msg); err != nil {
+			return -1, err
+		}
+		if err := s.notifyFilters(ctx, listID, list.Tags, states, msg); err != nil {
+			return -1, err
+		}
+	}
+
+	return n, nil
+}
+
+// RemoveItemByID removes an item given by its id from a list along with its attachments
+func (s *ServiceImpl) RemoveItemByID(ctx context.Context, listID string, itemID string) (int64, error) {
+	list, err := s.repository.FindListByID(ctx, listID)
+	if err != nil {
+		return -1, err
+	}
+	item := list.FindItem(itemID)
+	if item == nil {
+		return -1, itemNotFoundError(listID, itemID)
+	}
+	tags := copyTags(list.Tags)
+	removed := copyItems([]*Item{item})
+
+	n, err := s.repository.RemoveItemByID(ctx, listID, itemID)
+	if err != nil {
+		return -1, err
+	}
+
+	if err := s.attachments.Delete(ctx, collectAttachments(removed)...); err != nil {
+		return -1, err
+	}
+
+	msg := &deleteItemMessage{
+		BaseMessage: hub.NewBaseMessage(time.Now().Unix(), hub.TopicFromString(listID)),
+		Name:        removed[0].Name,
+		Quantity:    removed[0].Quantity,
+	}
+	if err := s.h.Publish(ctx, msg); err != nil {
+		return -1, err
+	}
+
+	if err := s.notifyFilters(ctx, listID, tags, removed, msg); err != nil {
+		return -1, err
+	}
+
+	return n, nil
+}
+
+// RemoveAllItems removes all items from a list along with their attachments
 func (s *ServiceImpl) RemoveAllItems(ctx context.Context, listID string) (int64, error) {
+	list, err := s.repository.FindListByID(ctx, listID)
+	if err != nil {
+		return -1, err
+	}
+	tags := copyTags(list.Tags)
+	removed := copyItems(list.Items)
+
 	n, err := s.repository.RemoveAllItems(ctx, listID)
 	if err != nil {
 		return -1, err
 	}
 
-	if err := s.h.Publish(ctx, &clearListMesssage{
+	if err := s.attachments.Delete(ctx, collectAttachments(removed)...); err != nil {
+		return -1, err
+	}
+
+	msg := &clearListMesssage{
 		BaseMessage: hub.NewBaseMessage(time.Now().Unix(), hub.TopicFromString(listID)),
 		ListID:      listID,
+	}
+	if err := s.h.Publish(ctx, msg); err != nil {
+		return -1, err
+	}
+
+	if err := s.notifyFilters(ctx, listID, tags, removed, msg); err != nil {
+		return -1, err
+	}
+
+	return n, nil
+}
+
+// AddTags tags a list. The filters that start matching the list are notified
+func (s *ServiceImpl) AddTags(ctx context.Context, listID string, tags ...string) (int64, error) {
+	n, err := s.repository.AddTags(ctx, listID, tags...)
+	if err != nil {
+		return -1, err
+	}
+
+	msg := &addTagsMessage{
+		BaseMessage: hub.NewBaseMessage(time.Now().Unix(), hub.TopicFromString(listID)),
+		ListID:      listID,
+		Tags:        tags,
+	}
+	if err := s.h.Publish(ctx, msg); err != nil {
+		return -1, err
+	}
+
+	list, err := s.repository.FindListByID(ctx, listID)
+	if err != nil {
+		return -1, err
+	}
+
+	if err := s.notifyFilters(ctx, listID, tags, list.Items, msg); err != nil {
+		return -1, err
+	}
+
+	return n, nil
+}
+
+// RemoveTags removes tags from a list. The filters that stop matching the list are notified
+func (s *ServiceImpl) RemoveTags(ctx context.Context, listID string, tags ...string) (int64, error) {
+	n, err := s.repository.RemoveTags(ctx, listID, tags...)
+	if err != nil {
+		return -1, err
+	}
+
+	msg := &removeTagsMessage{
+		BaseMessage: hub.NewBaseMessage(time.Now().Unix(), hub.TopicFromString(listID)),
+		ListID:      listID,
+		Tags:        tags,
+	}
+	if err := s.h.Publish(ctx, msg); err != nil {
+		return -1, err
+	}
+
+	list, err := s.repository.FindListByID(ctx, listID)
+	if err != nil {
+		return -1, err
+	}
+
+	if err := s.notifyFilters(ctx, listID, tags, list.Items, msg); err != nil {
+		return -1, err
+	}
+
+	return n, nil
+}
+
+// AddAttachment stores the content and attaches it to an item given by its name and quantity.
+// The content is deleted if it cannot be attached to the item
+func (s *ServiceImpl) AddAttachment(ctx context.Context, listID string, itemName string, itemQuantity string, filename string, contentType string, content io.Reader) (*attachment.Attachment, error) {
+	list, err := s.repository.FindListByID(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(findItems(list, itemName, itemQuantity)) == 0 {
+		return nil, common.NewError(common.ErrNotFound, "Could not find any item matching the name %v and the quantity %v in the list %v", itemName, itemQuantity, listID)
+	}
+
+	stored, err := s.attachments.Save(ctx, filename, contentType, content)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repository.AddAttachment(ctx, listID, itemName, itemQuantity, stored.ID); err != nil {
+		s.attachments.Delete(ctx, stored.ID)
+		return nil, err
+	}
+
+	if err := s.h.Publish(ctx, &addAttachmentMessage{
+		BaseMessage: hub.NewBaseMessage(time.Now().Unix(), hub.TopicFromString(listID)),
+		Name:        itemName,
+		Quantity:    itemQuantity,
+		Attachment:  stored,
+	}); err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+// OpenAttachment returns an attachment of an item of the list along with a reader on its content.
+// An error is returned if the attachment does not belong to the list
+func (s *ServiceImpl) OpenAttachment(ctx context.Context, listID string, attachmentID string) (*attachment.Attachment, io.ReadCloser, error) {
+	list, err := s.repository.FindListByID(ctx, listID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if list.FindAttachment(attachmentID) == nil {
+		return nil, nil, common.NewError(common.ErrNotFound, "there is no attachment with id %v in the list %v", attachmentID, listID)
+	}
+
+	return s.attachments.Open(ctx, attachmentID)
+}
+
+// RemoveAttachment detaches an attachment from its item and deletes it
+func (s *ServiceImpl) RemoveAttachment(ctx context.Context, listID string, attachmentID string) (int64, error) {
+	list, err := s.repository.FindListByID(ctx, listID)
+	if err != nil {
+		return -1, err
+	}
+
+	item := list.FindAttachment(attachmentID)
+	if item == nil {
+		return -1, common.NewError(common.ErrNotFound, "there is no attachment with id %v in the list %v", attachmentID, listID)
+	}
+	itemName, itemQuantity := item.Name, item.Quantity
+
+	n, err := s.repository.RemoveAttachment(ctx, listID, attachmentID)
+	if err != nil {
+		return -1, err
+	}
+
+	if err := s.attachments.Delete(ctx, attachmentID); err != nil {
+		return -1, err
+	}
+
+	if err := s.h.Publish(ctx, &removeAttachmentMessage{
+		BaseMessage:  hub.NewBaseMessage(time.Now().Unix(), hub.TopicFromString(listID)),
+		Name:         itemName,
+		Quantity:     itemQuantity,
+		AttachmentID: attachmentID,
+	}); err != nil {
+		return -1, err
+	}
+
+	return n, nil
+}
+
+// FindFilterByID retrieves a filter based on its id
+func (s *ServiceImpl) FindFilterByID(ctx context.Context, filterID string) (*Filter, error) {
+	return s.filters.FindFilterByID(ctx, filterID)
+}
+
+// FindAllFilters retrieves all filters
+func (s *ServiceImpl) FindAllFilters(ctx context.Context) ([]*Filter, error) {
+	return s.filters.FindAllFilters(ctx)
+}
+
+// StoreFilter saves a new filter and creates the topic on which its updates are published
+func (s *ServiceImpl) StoreFilter(ctx context.Context, name string, tags []string, done *bool) (*Filter, error) {
+	filter, err := s.filters.StoreFilter(ctx, name, tags, done)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.h.AddTopic(ctx, FilterTopic(filter.ID.Hex())); err != nil {
+		return nil, err
+	}
+
+	if err := s.h.Publish(ctx, &newFilterMessage{
+		BaseMessage: hub.NewBaseMessage(time.Now().Unix(), hub.TopicFromString("lists")),
+		NewFilter:   filter,
+	}); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+// DeleteFilter removes a filter along with its topic
+func (s *ServiceImpl) DeleteFilter(ctx context.Context, filterID string) (int64, error) {
+	n, err := s.filters.DeleteFilter(ctx, filterID)
+	if err != nil {
+		return -1, err
+	}
+
+	if err := s.h.DeleteTopic(ctx, FilterTopic(filterID)); err != nil {
+		return -1, err
+	}
+
+	if err := s.h.Publish(ctx, &deleteFilterMessage{
+		BaseMessage: hub.NewBaseMessage(time.Now().Unix(), hub.TopicFromString("lists")),
+		FilterID:    filterID,
 	}); err != nil {
 		return -1, err
 	}
 
 	return n, nil
 }
+
+// EvaluateFilter retrieves the items matching the filter given by its id
+func (s *ServiceImpl) EvaluateFilter(ctx context.Context, filterID string) ([]*FilteredItem, error) {
+	filter, err := s.filters.FindFilterByID(ctx, filterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repository.EvaluateFilter(ctx, filter)
+}
+
+// notifyFilters publishes the update to the topic of every filter matching the list tags and one of the changed items
+func (s *ServiceImpl) notifyFilters(ctx context.Context, listID string, tags []string, changed []*Item, update hub.Message) error {
+	filters, err := s.filters.FindAllFilters(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, filter := range filters {
+		if !filter.MatchesTags(tags) {
+			continue
+		}
+
+		for _, item := range changed {
+			if !filter.MatchesItem(item) {
+				continue
+			}
+
+			if err := s.h.Publish(ctx, &filterUpdateMessage{
+				BaseMessage: hub.NewBaseMessage(time.Now().Unix(), FilterTopic(filter.ID.Hex())),
+				FilterID:    filter.ID.Hex(),
+				ListID:      listID,
+				UpdateType:  update.GetType(),
+				Update:      update,
+			}); err != nil {
+				return err
+			}
+
+			break
+		}
+	}
+
+	return nil
+}
+
+// findItems returns the items of the list matching the name and the quantity
+func findItems(list *Shoppinglist, name string, quantity string) []*Item {
+	items := []*Item{}
+	for _, item := range list.Items {
+		if item.Name == name && item.Quantity == quantity {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// tagsDifference returns the tags that are not in the reference
+func tagsDifference(reference []string, tags []string) []string {
+	difference := []string{}
+	for _, tag := range tags {
+		found := false
+		for _, known := range reference {
+			if known == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			difference = append(difference, tag)
+		}
+	}
+
+	return difference
+}
+
+// copyTags returns a copy of the tags so that it is not altered by the repository
+func copyTags(tags []string) []string {
+	copied := make([]string, len(tags))
+	copy(copied, tags)
+
+	return copied
+}
+
+// copyItems returns a copy of the items so that they are not altered by the repository
+func copyItems(items []*Item) []*Item {
+	copied := make([]*Item, len(items))
+	for i, item := range items {
+		itemCopy := *item
+		copied[i] = &itemCopy
+	}
+
+	return copied
+}
+
+// collectAttachments returns the ids of the attachments of the items
+func collectAttachments(items []*Item) []string {
+	attachmentIDs := []string{}
+	for _, item := range items {
+		attachmentIDs = append(attachmentIDs, item.Attachments...)
+	}
+
+	return attachmentIDs
+}
@@ -1,18 +1,44 @@
 package list
 
-import "context"
+import (
+	"context"
+	"io"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/attachment"
+)
+
+// AttachmentService defines the operations on the attachments of the items
+type AttachmentService interface {
+	AddAttachment(ctx context.Context, listID string, itemName string, itemQuantity string, filename string, contentType string, content io.Reader) (*attachment.Attachment, error)
+
+	OpenAttachment(ctx context.Context, listID string, attachmentID string) (*attachment.Attachment, io.ReadCloser, error)
+
+	RemoveAttachment(ctx context.Context, listID string, attachmentID string) (int64, error)
+}
 
 // Service is the interface defining the list service api
 type Service interface {
+	AttachmentService
+
 	FindListByID(ctx context.Context, listID string) (*Shoppinglist, error)
 
 	FindAllLists(ctx context.Context) ([]*Shoppinglist, error)
 
-	StoreList(ctx context.Context, listName string) (*Shoppinglist, error)
+	StoreList(ctx context.Context, listName string, ownerID string) (*Shoppinglist, error)
+
+	FindListsByOwner(ctx context.Context, ownerID string) ([]*Shoppinglist, error)
+
+	TransferList(ctx context.Context, listID string, newOwnerID string) (int64, error)
+
+	RenameList(ctx context.Context, listID string, name string) (int64, error)
+
+	ReplaceList(ctx context.Context, listID string, current *Shoppinglist, name string, tags []string) (int64, error)
 
 	DeleteList(ctx context.Context, listID string) (int64, error)
 
-	AddItem(ctx context.Context, listID string, itemName string, itemQuantity string) (*Item, error)
+	FindItem(ctx context.Context, listID string, itemID string) (*Item, error)
+
+	AddItem(ctx context.Context, listID string, itemName string, itemQuantity string) (*Item, bool, error)
 
 	UpdateItem(ctx context.Context, listID string, itemName string, itemQuantity string, itemNewName string, itemNewQuantity string) (int64, error)
 
@@ -20,5 +46,23 @@ type Service interface {
 
 	RemoveItem(ctx context.Context, listID string, itemName string, itemQuantity string) (int64, error)
 
+	ReplaceItem(ctx context.Context, listID string, current *Item, replacement *Item) (int64, error)
+
+	RemoveItemByID(ctx context.Context, listID string, itemID string) (int64, error)
+
 	RemoveAllItems(ctx context.Context, listID string) (int64, error)
+
+	AddTags(ctx context.Context, listID string, tags ...string) (int64, error)
+
+	RemoveTags(ctx context.Context, listID string, tags ...string) (int64, error)
+
+	FindFilterByID(ctx context.Context, filterID string) (*Filter, error)
+
+	FindAllFilters(ctx context.Context) ([]*Filter, error)
+
+	StoreFilter(ctx context.Context, name string, tags []string, done *bool) (*Filter, error)
+
+	DeleteFilter(ctx context.Context, filterID string) (int64, error)
+
+	EvaluateFilter(ctx context.Context, filterID string) ([]*FilteredItem, error)
 }
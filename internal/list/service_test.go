@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/NicolasDutronc/shoppinglist-be/internal/attachment"
 	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
 	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
 	mocks "github.com/NicolasDutronc/shoppinglist-be/mocks/pkg/hub"
@@ -18,16 +19,20 @@ import (
 
 type ListServiceTestSuite struct {
 	suite.Suite
-	srv        *list.ServiceImpl
-	mockedRepo *list.MockRepository
-	mockedHub  *mocks.Hub
-	list       *list.Shoppinglist
+	srv              *list.ServiceImpl
+	mockedRepo       *list.MockRepository
+	mockedFilterRepo *list.MockFilterRepository
+	mockedStorage    *attachment.MockStorage
+	mockedHub        *mocks.Hub
+	list             *list.Shoppinglist
 }
 
 func (s *ListServiceTestSuite) SetupTest() {
 	s.mockedRepo = &list.MockRepository{}
+	s.mockedFilterRepo = &list.MockFilterRepository{}
+	s.mockedStorage = &attachment.MockStorage{}
 	s.mockedHub = &mocks.Hub{}
-	s.srv = list.NewService(s.mockedRepo, s.mockedHub).(*list.ServiceImpl)
+	s.srv = list.NewService(s.mockedRepo, s.mockedFilterRepo, s.mockedStorage, s.mockedHub).(*list.ServiceImpl)
 
 	s.list = &list.Shoppinglist{
 		BaseModel: common.BaseModel{
@@ -38,10 +43,12 @@ func (s *ListServiceTestSuite) SetupTest() {
 		Name: "list",
 		Items: []*list.Item{
 			{
+				ID:       "item1ID",
 				Name:     "item1",
 				Quantity: "a lot",
 			},
 			{
+				ID:       "item2ID",
 				Name:     "item2",
 				Quantity: "a little",
 			},
@@ -118,17 +125,17 @@ func (s *ListServiceTestSuite) TestStoreList() {
 	ctx := context.Background()
 
 	// case 1 : the repo returns an error
-	s.mockedRepo.On("StoreList", ctx, "nameThatAlreadyExists").Return(nil, assert.AnError).Once()
-	list, err := s.srv.StoreList(ctx, "nameThatAlreadyExists")
+	s.mockedRepo.On("StoreList", ctx, "nameThatAlreadyExists", "ownerID").Return(nil, assert.AnError).Once()
+	list, err := s.srv.StoreList(ctx, "nameThatAlreadyExists", "ownerID")
 	assert.Nil(s.T(), list)
 	assert.Error(s.T(), err)
 
 	// for other cases, the repo will return the list
-	s.mockedRepo.On("StoreList", ctx, s.list.Name).Return(s.list, nil).Times(3)
+	s.mockedRepo.On("StoreList", ctx, s.list.Name, "ownerID").Return(s.list, nil).Times(3)
 
 	// case 2 : AddTopic returns an error
 	s.mockedHub.On("AddTopic", ctx, hub.TopicFromString(s.list.ID.Hex())).Return(assert.AnError).Once()
-	list, err = s.srv.StoreList(ctx, s.list.Name)
+	list, err = s.srv.StoreList(ctx, s.list.Name, "ownerID")
 	assert.Nil(s.T(), list)
 	assert.Error(s.T(), err)
 
@@ -137,7 +144,7 @@ func (s *ListServiceTestSuite) TestStoreList() {
 
 	// case 3 : Publish returns an error
 	s.mockedHub.On("Publish", ctx, mock.Anything).Return(assert.AnError).Once()
-	list, err = s.srv.StoreList(ctx, s.list.Name)
+	list, err = s.srv.StoreList(ctx, s.list.Name, "ownerID")
 	assert.Nil(s.T(), list)
 	assert.Error(s.T(), err)
 
@@ -145,11 +152,42 @@ func (s *ListServiceTestSuite) TestStoreList() {
 	s.mockedHub.On("Publish", ctx, mock.Anything).Return(nil).Once()
 
 	// case 4 : everything goes well
-	list, err = s.srv.StoreList(ctx, s.list.Name)
+	list, err = s.srv.StoreList(ctx, s.list.Name, "ownerID")
 	assert.NotNil(s.T(), list)
 	assert.NoError(s.T(), err)
 }
 
+func (s *ListServiceTestSuite) TestRenameList() {
+	ctx := context.Background()
+
+	// a blank name never reaches the repository
+	n, err := s.srv.RenameList(ctx, s.list.ID.Hex(), "  ")
+	assert.Equal(s.T(), int64(-1), n)
+	assert.ErrorIs(s.T(), err, list.ErrEmptyName)
+	assert.ErrorIs(s.T(), err, common.ErrValidation)
+
+	s.mockedRepo.On("RenameList", ctx, s.list.ID.Hex(), "groceries").Return(int64(1), nil).Once()
+	s.mockedHub.On("Publish", ctx, mock.Anything).Return(nil).Once()
+	n, err = s.srv.RenameList(ctx, s.list.ID.Hex(), "groceries")
+	assert.Equal(s.T(), int64(1), n)
+	assert.NoError(s.T(), err)
+	s.mockedRepo.AssertExpectations(s.T())
+	s.mockedHub.AssertExpectations(s.T())
+}
+
+func (s *ListServiceTestSuite) TestFindItem() {
+	ctx := context.Background()
+
+	s.mockedRepo.On("FindListByID", ctx, s.list.ID.Hex()).Return(s.list, nil)
+	item, err := s.srv.FindItem(ctx, s.list.ID.Hex(), "item2ID")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "item2", item.Name)
+
+	item, err = s.srv.FindItem(ctx, s.list.ID.Hex(), "unknownID")
+	assert.Nil(s.T(), item)
+	assert.ErrorIs(s.T(), err, common.ErrNotFound)
+}
+
 func (s *ListServiceTestSuite) TestDeleteList() {
 
 }
@@ -166,10 +204,65 @@ func (s *ListServiceTestSuite) TestToggleItem() {
 
 }
 
+func (s *ListServiceTestSuite) TestToggleItemNotifiesFilters() {
+	ctx := context.Background()
+	s.list.Tags = []string{"supermarket"}
+
+	unchecked := false
+	matching := &list.Filter{
+		BaseModel: common.BaseModel{ID: primitive.NewObjectID()},
+		Name:      "unchecked supermarket items",
+		Tags:      []string{"supermarket"},
+		Done:      &unchecked,
+	}
+	otherTag := &list.Filter{
+		BaseModel: common.BaseModel{ID: primitive.NewObjectID()},
+		Name:      "hardware store",
+		Tags:      []string{"hardware store"},
+	}
+
+	s.mockedRepo.On("ToggleItem", ctx, s.list.ID.Hex(), "item1", "a lot", true).Return(int64(1), nil)
+	s.mockedRepo.On("FindListByID", ctx, s.list.ID.Hex()).Return(s.list, nil)
+	s.mockedFilterRepo.On("FindAllFilters", ctx).Return([]*list.Filter{matching, otherTag}, nil)
+	s.mockedHub.On("Publish", ctx, mock.Anything).Return(nil)
+
+	n, err := s.srv.ToggleItem(ctx, s.list.ID.Hex(), "item1", "a lot", true)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(1), n)
+
+	// one message for the list and one for the matching filter
+	s.mockedHub.AssertNumberOfCalls(s.T(), "Publish", 2)
+	// the filter update is only delivered to those who can follow the list
+	s.mockedHub.AssertCalled(s.T(), "Publish", ctx, mock.MatchedBy(func(msg hub.Message) bool {
+		restricted, ok := msg.(hub.Restricted)
+		return msg.GetTopic() == list.FilterTopic(matching.ID.Hex()) && ok && restricted.RestrictedTo() == hub.TopicFromString(s.list.ID.Hex())
+	}))
+	s.mockedHub.AssertNotCalled(s.T(), "Publish", ctx, mock.MatchedBy(func(msg hub.Message) bool {
+		return msg.GetTopic() == list.FilterTopic(otherTag.ID.Hex())
+	}))
+}
+
 func (s *ListServiceTestSuite) TestRemoveItem() {
 
 }
 
+func (s *ListServiceTestSuite) TestRemoveAllItemsDeletesAttachments() {
+	ctx := context.Background()
+	s.list.Items[0].Attachments = []string{"photo1", "photo2"}
+	s.list.Items[1].Attachments = []string{"photo3"}
+
+	s.mockedRepo.On("FindListByID", ctx, s.list.ID.Hex()).Return(s.list, nil)
+	s.mockedRepo.On("RemoveAllItems", ctx, s.list.ID.Hex()).Return(int64(2), nil)
+	s.mockedStorage.On("Delete", ctx, "photo1", "photo2", "photo3").Return(nil).Once()
+	s.mockedFilterRepo.On("FindAllFilters", ctx).Return([]*list.Filter{}, nil)
+	s.mockedHub.On("Publish", ctx, mock.Anything).Return(nil)
+
+	n, err := s.srv.RemoveAllItems(ctx, s.list.ID.Hex())
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(2), n)
+	s.mockedStorage.AssertExpectations(s.T())
+}
+
 func (s *ListServiceTestSuite) TestRemoveAllItems() {
 
 }
@@ -0,0 +1,32 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// LogNotifier writes the messages to a writer instead of delivering them. It is meant for development
+type LogNotifier struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewLogNotifier inits a new notifier writing to w
+func NewLogNotifier(w io.Writer) Notifier {
+	return &LogNotifier{
+		w: w,
+	}
+}
+
+// Notify writes the message
+func (n *LogNotifier) Notify(ctx context.Context, message *Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	_, err := fmt.Fprintf(n.w, "--- %s\nTo: %s\nSubject: %s\n\n%s\n", time.Now().Format(time.RFC3339), message.To, message.Subject, message.Body)
+
+	return err
+}
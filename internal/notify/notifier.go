@@ -0,0 +1,15 @@
+package notify
+
+import "context"
+
+// Message is a notification sent to a user
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers messages to users
+type Notifier interface {
+	Notify(ctx context.Context, message *Message) error
+}
@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPNotifier delivers the messages by email
+type SMTPNotifier struct {
+	address string
+	auth    smtp.Auth
+	from    string
+}
+
+// NewSMTPNotifier inits a new notifier sending emails through the server at host:port.
+// Authentication is skipped when username is empty, like with local mail catchers
+func NewSMTPNotifier(host string, port string, username string, password string, from string) Notifier {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPNotifier{
+		address: net.JoinHostPort(host, port),
+		auth:    auth,
+		from:    from,
+	}
+}
+
+// Notify sends the message as a plain text email
+func (n *SMTPNotifier) Notify(ctx context.Context, message *Message) error {
+	if strings.ContainsAny(message.To+message.Subject, "\r\n") {
+		return fmt.Errorf("invalid recipient or subject %q", message.Subject)
+	}
+
+	body := strings.Join([]string{
+		"From: " + n.from,
+		"To: " + message.To,
+		"Subject: " + message.Subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		message.Body,
+	}, "\r\n")
+
+	return smtp.SendMail(n.address, n.auth, n.from, []string{message.To}, []byte(body))
+}
@@ -0,0 +1,95 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Identity links the subject of an identity provider to a user
+type Identity struct {
+	common.BaseModel `bson:",inline"`
+	Issuer           string `bson:"issuer" json:"issuer"`
+	Subject          string `bson:"subject" json:"subject"`
+	UserID           string `bson:"user_id" json:"user_id"`
+}
+
+// NewIdentity is an Identity constructor
+func NewIdentity(issuer string, subject string, userID string) *Identity {
+	now := time.Now()
+	return &Identity{
+		BaseModel: common.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		Issuer:  issuer,
+		Subject: subject,
+		UserID:  userID,
+	}
+}
+
+// Authorization is a pending authorization request. It keeps the PKCE verifier and the nonce until the provider redirects back with the state
+type Authorization struct {
+	common.BaseModel `bson:",inline"`
+	StateHash        string    `bson:"state_hash"`
+	Nonce            string    `bson:"nonce"`
+	CodeVerifier     string    `bson:"code_verifier"`
+	ExpiresAt        time.Time `bson:"expires_at"`
+}
+
+// NewAuthorization is an Authorization constructor. It returns the authorization along with its state, of which only the hash is stored
+func NewAuthorization(validity time.Duration) (*Authorization, string, error) {
+	state, err := randomString()
+	if err != nil {
+		return nil, "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return nil, "", err
+	}
+	verifier, err := randomString()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	return &Authorization{
+		BaseModel: common.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		StateHash:    HashState(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    now.Add(validity),
+	}, state, nil
+}
+
+// CodeChallenge returns the S256 PKCE challenge of the verifier
+func (a *Authorization) CodeChallenge() string {
+	sum := sha256.Sum256([]byte(a.CodeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// HashState returns the hash under which a state is stored
+func HashState(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomString returns 32 random bytes encoded in base64url, which fits states, nonces and PKCE verifiers
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,110 @@
+package oidc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// InMemoryRepository is an in-memory identities and authorizations repository
+type InMemoryRepository struct {
+	mu             sync.Mutex
+	identities     []*Identity
+	authorizations map[string]*Authorization
+}
+
+// NewInMemoryRepository is a constructor for InMemoryRepository
+func NewInMemoryRepository() Repository {
+	return &InMemoryRepository{
+		identities:     []*Identity{},
+		authorizations: make(map[string]*Authorization),
+	}
+}
+
+// FindIdentity retrieves the identity of the provider subject
+func (r *InMemoryRepository) FindIdentity(ctx context.Context, issuer string, subject string) (*Identity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, identity := range r.identities {
+		if identity.Issuer == issuer && identity.Subject == subject {
+			copied := *identity
+			return &copied, nil
+		}
+	}
+
+	return nil, ErrUnknownIdentity
+}
+
+// StoreIdentity stores an identity
+func (r *InMemoryRepository) StoreIdentity(ctx context.Context, identity *Identity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *identity
+	r.identities = append(r.identities, &copied)
+
+	return nil
+}
+
+// FindIdentitiesByUser retrieves the identities linked to the user
+func (r *InMemoryRepository) FindIdentitiesByUser(ctx context.Context, userID string) ([]*Identity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	identities := []*Identity{}
+	for _, identity := range r.identities {
+		if identity.UserID == userID {
+			copied := *identity
+			identities = append(identities, &copied)
+		}
+	}
+
+	return identities, nil
+}
+
+// DeleteIdentitiesByUser unlinks the user from all their provider subjects
+func (r *InMemoryRepository) DeleteIdentitiesByUser(ctx context.Context, userID string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := []*Identity{}
+	for _, identity := range r.identities {
+		if identity.UserID != userID {
+			kept = append(kept, identity)
+		}
+	}
+	n := int64(len(r.identities) - len(kept))
+	r.identities = kept
+
+	return n, nil
+}
+
+// StoreAuthorization stores a pending authorization
+func (r *InMemoryRepository) StoreAuthorization(ctx context.Context, authorization *Authorization) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *authorization
+	r.authorizations[authorization.StateHash] = &copied
+
+	return nil
+}
+
+// ConsumeAuthorization removes the authorization matching the state hash and returns it if it has not expired
+func (r *InMemoryRepository) ConsumeAuthorization(ctx context.Context, stateHash string, now time.Time) (*Authorization, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	authorization, exists := r.authorizations[stateHash]
+	if !exists {
+		return nil, ErrInvalidState
+	}
+	delete(r.authorizations, stateHash)
+
+	if !now.Before(authorization.ExpiresAt) {
+		return nil, ErrInvalidState
+	}
+
+	return authorization, nil
+}
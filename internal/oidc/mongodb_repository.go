@@ -0,0 +1,94 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoDBRepository contains all the methods to interact with the identities and authorizations collections
+type MongoDBRepository struct {
+	IdentitiesCollection     *mongo.Collection
+	AuthorizationsCollection *mongo.Collection
+}
+
+// NewMongoDBRepository is a constructor for MongoDBRepository
+func NewMongoDBRepository(identitiesColl *mongo.Collection, authorizationsColl *mongo.Collection) Repository {
+	return &MongoDBRepository{
+		IdentitiesCollection:     identitiesColl,
+		AuthorizationsCollection: authorizationsColl,
+	}
+}
+
+// FindIdentity retrieves the identity of the provider subject
+func (r *MongoDBRepository) FindIdentity(ctx context.Context, issuer string, subject string) (*Identity, error) {
+	var identity Identity
+	err := r.IdentitiesCollection.FindOne(ctx, bson.M{"issuer": issuer, "subject": subject}).Decode(&identity)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrUnknownIdentity
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+// StoreIdentity inserts an identity
+func (r *MongoDBRepository) StoreIdentity(ctx context.Context, identity *Identity) error {
+	_, err := r.IdentitiesCollection.InsertOne(ctx, identity)
+
+	return err
+}
+
+// FindIdentitiesByUser retrieves the identities linked to the user
+func (r *MongoDBRepository) FindIdentitiesByUser(ctx context.Context, userID string) ([]*Identity, error) {
+	identities := []*Identity{}
+	cursor, err := r.IdentitiesCollection.Find(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(ctx, &identities); err != nil {
+		return nil, err
+	}
+
+	return identities, nil
+}
+
+// DeleteIdentitiesByUser unlinks the user from all their provider subjects
+func (r *MongoDBRepository) DeleteIdentitiesByUser(ctx context.Context, userID string) (int64, error) {
+	result, err := r.IdentitiesCollection.DeleteMany(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return -1, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// StoreAuthorization inserts a pending authorization
+func (r *MongoDBRepository) StoreAuthorization(ctx context.Context, authorization *Authorization) error {
+	_, err := r.AuthorizationsCollection.InsertOne(ctx, authorization)
+
+	return err
+}
+
+// ConsumeAuthorization atomically removes the authorization matching the state hash if it has not expired
+func (r *MongoDBRepository) ConsumeAuthorization(ctx context.Context, stateHash string, now time.Time) (*Authorization, error) {
+	var authorization Authorization
+	err := r.AuthorizationsCollection.FindOneAndDelete(
+		ctx,
+		bson.M{"state_hash": stateHash, "expires_at": bson.M{"$gt": now}},
+	).Decode(&authorization)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrInvalidState
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &authorization, nil
+}
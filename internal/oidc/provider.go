@@ -0,0 +1,167 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/NicolasDutronc/shoppinglist-be/pkg/keyring"
+)
+
+// Metadata holds the endpoints of a provider, as published in its discovery document
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider is an OpenID Connect identity provider. It caches the signing keys of the provider,
+// and fetches them again when an ID token is signed with an unknown key
+type Provider struct {
+	Metadata
+	client *http.Client
+
+	mu   sync.Mutex
+	keys map[string]interface{}
+}
+
+// Discover fetches the discovery document of the issuer and returns the provider it describes
+func Discover(ctx context.Context, client *http.Client, issuer string) (*Provider, error) {
+	var metadata Metadata
+	if err := getJSON(ctx, client, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", &metadata); err != nil {
+		return nil, fmt.Errorf("cannot discover the provider %s: %w", issuer, err)
+	}
+
+	if metadata.Issuer != issuer {
+		return nil, fmt.Errorf("the provider %s advertises the issuer %s", issuer, metadata.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("the discovery document of the provider %s is incomplete", issuer)
+	}
+
+	return &Provider{
+		Metadata: metadata,
+		client:   client,
+		keys:     make(map[string]interface{}),
+	}, nil
+}
+
+// key returns the public key matching the key id, fetching the key set again when it is unknown
+func (p *Provider) key(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, exists := p.keys[kid]; exists {
+		return key, nil
+	}
+
+	keys, err := p.fetchKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+
+	key, exists := p.keys[kid]
+	if !exists {
+		return nil, fmt.Errorf("the provider has no key %s", kid)
+	}
+
+	return key, nil
+}
+
+// fetchKeys retrieves the key set of the provider. Keys that are not meant for signatures or of unsupported types are skipped
+func (p *Provider) fetchKeys(ctx context.Context) (map[string]interface{}, error) {
+	var set keyring.JSONWebKeySet
+	if err := getJSON(ctx, p.client, p.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("cannot fetch the provider keys: %w", err)
+	}
+
+	keys := make(map[string]interface{})
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	return keys, nil
+}
+
+// exchange redeems an authorization code at the token endpoint and returns the ID token
+func (p *Provider) exchange(ctx context.Context, clientID string, clientSecret string, redirectURL string, code string, verifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURL},
+		"code_verifier": {verifier},
+	}
+	// confidential clients authenticate with their secret, public ones only identify themselves and rely on PKCE
+	if clientSecret == "" {
+		form.Set("client_id", clientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("cannot decode the token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("the provider refused the code: %s %s", body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return "", errors.New("the provider did not return an ID token")
+	}
+
+	return body.IDToken, nil
+}
+
+// getJSON decodes the JSON document served at the url
+func getJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
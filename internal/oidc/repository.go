@@ -0,0 +1,42 @@
+package oidc
+
+import (
+	"context"
+	"time"
+)
+
+// IdentityFinder is a single method interface for finding the identity of a provider subject
+type IdentityFinder interface {
+	FindIdentity(ctx context.Context, issuer string, subject string) (*Identity, error)
+}
+
+// IdentityStorer is a single method interface for linking a provider subject to a user
+type IdentityStorer interface {
+	StoreIdentity(ctx context.Context, identity *Identity) error
+}
+
+// UserIdentities defines the operations on all the identities linked to a user
+type UserIdentities interface {
+	FindIdentitiesByUser(ctx context.Context, userID string) ([]*Identity, error)
+	DeleteIdentitiesByUser(ctx context.Context, userID string) (int64, error)
+}
+
+// AuthorizationStorer is a single method interface for storing a pending authorization request
+type AuthorizationStorer interface {
+	StoreAuthorization(ctx context.Context, authorization *Authorization) error
+}
+
+// AuthorizationConsumer is a single method interface for using a pending authorization request.
+// It removes the authorization matching the state hash and returns it if it has not expired
+type AuthorizationConsumer interface {
+	ConsumeAuthorization(ctx context.Context, stateHash string, now time.Time) (*Authorization, error)
+}
+
+// Repository is a wrapper around all the single method interfaces defining the identities and authorizations storage
+type Repository interface {
+	IdentityFinder
+	IdentityStorer
+	UserIdentities
+	AuthorizationStorer
+	AuthorizationConsumer
+}
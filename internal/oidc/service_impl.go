@@ -0,0 +1,207 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// authorizationValidity is the time given to the user to log in at the provider
+const authorizationValidity = 10 * time.Minute
+
+var (
+	// ErrInvalidState is returned when the provider redirects back with an unknown, used or expired state
+	ErrInvalidState = errors.New("invalid or expired authorization state")
+	// ErrInvalidIDToken is returned when the ID token of the provider cannot be trusted
+	ErrInvalidIDToken = errors.New("invalid ID token")
+	// ErrUnknownIdentity is returned when the subject of the provider is not linked to a user and auto-provisioning is disabled
+	ErrUnknownIdentity = errors.New("the identity is not linked to any user")
+)
+
+// Client identifies this application at the provider
+type Client struct {
+	ID          string
+	Secret      string
+	RedirectURL string
+	Scopes      []string
+}
+
+// ServiceImpl is the concrete implementation of the OpenID Connect service interface
+type ServiceImpl struct {
+	repo          Repository
+	users         Accounts
+	provider      *Provider
+	client        *Client
+	autoProvision bool
+}
+
+// NewService inits a new OpenID Connect service.
+// When autoProvision is true, a user without permissions is created on the first login of an unknown subject
+func NewService(repo Repository, users Accounts, provider *Provider, client *Client, autoProvision bool) Service {
+	return &ServiceImpl{
+		repo:          repo,
+		users:         users,
+		provider:      provider,
+		client:        client,
+		autoProvision: autoProvision,
+	}
+}
+
+// AuthorizationURL starts a login and returns the provider URL the user has to be redirected to
+func (s *ServiceImpl) AuthorizationURL(ctx context.Context) (string, error) {
+	authorization, state, err := NewAuthorization(authorizationValidity)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.repo.StoreAuthorization(ctx, authorization); err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(s.provider.AuthorizationEndpoint)
+	if err != nil {
+		return "", err
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", s.client.ID)
+	query.Set("redirect_uri", s.client.RedirectURL)
+	query.Set("scope", strings.Join(append([]string{"openid"}, s.client.Scopes...), " "))
+	query.Set("state", state)
+	query.Set("nonce", authorization.Nonce)
+	query.Set("code_challenge", authorization.CodeChallenge())
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+// Callback completes a login once the provider redirected back with the state and an authorization code.
+// It returns the linked user along with its session tokens, or a challenge when the user enabled two-factor authentication
+func (s *ServiceImpl) Callback(ctx context.Context, state string, code string) (*user.User, *user.Tokens, *user.Challenge, error) {
+	authorization, err := s.repo.ConsumeAuthorization(ctx, HashState(state), time.Now())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	rawIDToken, err := s.provider.exchange(ctx, s.client.ID, s.client.Secret, s.client.RedirectURL, code, authorization.CodeVerifier)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	claims, err := s.verifyIDToken(ctx, rawIDToken, authorization.Nonce)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	userID, err := s.resolveIdentity(ctx, claims)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return s.users.LoginWithIdentity(ctx, userID)
+}
+
+// idTokenClaims are the claims of an ID token used to link and provision users
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	AuthorizedParty   string `json:"azp"`
+	Nonce             string `json:"nonce"`
+	PreferredUsername string `json:"preferred_username"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+}
+
+// verifyIDToken checks the signature of the ID token against the provider keys, then its issuer, audience, expiry and nonce
+func (s *ServiceImpl) verifyIDToken(ctx context.Context, rawIDToken string, nonce string) (*idTokenClaims, error) {
+	parser := &jwt.Parser{
+		ValidMethods: []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA"},
+	}
+
+	var claims idTokenClaims
+	if _, err := parser.ParseWithClaims(rawIDToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return s.provider.key(ctx, kid)
+	}); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	switch {
+	case !claims.VerifyIssuer(s.provider.Issuer, true):
+		return nil, fmt.Errorf("%w: unexpected issuer", ErrInvalidIDToken)
+	case !claims.VerifyAudience(s.client.ID, true):
+		return nil, fmt.Errorf("%w: unexpected audience", ErrInvalidIDToken)
+	case len(claims.Audience) > 1 && claims.AuthorizedParty != s.client.ID:
+		return nil, fmt.Errorf("%w: unexpected authorized party", ErrInvalidIDToken)
+	case !claims.VerifyExpiresAt(time.Now(), true):
+		return nil, fmt.Errorf("%w: expired", ErrInvalidIDToken)
+	case claims.Nonce != nonce:
+		return nil, fmt.Errorf("%w: unexpected nonce", ErrInvalidIDToken)
+	case claims.Subject == "":
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+
+	return &claims, nil
+}
+
+// resolveIdentity returns the id of the user linked to the subject, provisioning one if allowed
+func (s *ServiceImpl) resolveIdentity(ctx context.Context, claims *idTokenClaims) (string, error) {
+	identity, err := s.repo.FindIdentity(ctx, claims.Issuer, claims.Subject)
+	if err == nil {
+		return identity.UserID, nil
+	}
+	if !errors.Is(err, ErrUnknownIdentity) || !s.autoProvision {
+		return "", err
+	}
+
+	provisioned, err := s.provision(ctx, claims)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.repo.StoreIdentity(ctx, NewIdentity(claims.Issuer, claims.Subject, provisioned.ID.Hex())); err != nil {
+		return "", err
+	}
+
+	return provisioned.ID.Hex(), nil
+}
+
+// provision creates a user without permissions for the subject. Its password is random, so it can only log in through the provider until it resets it.
+// The name comes from the claims and is suffixed when it is already taken
+func (s *ServiceImpl) provision(ctx context.Context, claims *idTokenClaims) (*user.User, error) {
+	name := claims.PreferredUsername
+	if name == "" && claims.Email != "" {
+		name = strings.SplitN(claims.Email, "@", 2)[0]
+	}
+	if name == "" {
+		name = "user"
+	}
+	if _, err := s.users.FindByName(ctx, name); err == nil {
+		name = fmt.Sprintf("%s-%s", name, HashState(claims.Issuer + claims.Subject)[:6])
+	}
+
+	password, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+
+	provisioned, err := s.users.Store(ctx, name, password)
+	if err != nil {
+		return nil, err
+	}
+
+	// only verified emails are trusted, they receive the password resets
+	if claims.Email != "" && claims.EmailVerified {
+		if _, err := s.users.UpdateEmail(ctx, provisioned.ID.Hex(), claims.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	return provisioned, nil
+}
@@ -0,0 +1,21 @@
+package oidc
+
+import (
+	"context"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+)
+
+// Accounts is the part of the user service used to find, provision and log in the users of the provider
+type Accounts interface {
+	FindByName(ctx context.Context, userName string) (*user.User, error)
+	Store(ctx context.Context, name string, password string, permissions ...*user.Permission) (*user.User, error)
+	UpdateEmail(ctx context.Context, userID string, newEmail string) (int64, error)
+	LoginWithIdentity(ctx context.Context, userID string) (*user.User, *user.Tokens, *user.Challenge, error)
+}
+
+// Service defines the login through an OpenID Connect provider with the authorization code flow and PKCE
+type Service interface {
+	AuthorizationURL(ctx context.Context) (string, error)
+	Callback(ctx context.Context, state string, code string) (*user.User, *user.Tokens, *user.Challenge, error)
+}
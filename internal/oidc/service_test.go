@@ -0,0 +1,214 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NicolasDutronc/autokey"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/oidc"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/NicolasDutronc/shoppinglist-be/pkg/keyring"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// testProvider is a stand-in identity provider. It skips the login page and issues a code for the configured subject
+type testProvider struct {
+	*httptest.Server
+	key      *rsa.PrivateKey
+	clientID string
+
+	mu      sync.Mutex
+	subject string
+	claims  jwt.MapClaims
+	codes   map[string]url.Values
+}
+
+func newTestProvider(t *testing.T, clientID string) *testProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	p := &testProvider{
+		key:      key,
+		clientID: clientID,
+		codes:    make(map[string]url.Values),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+			"jwks_uri":               p.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test-key",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		authorization, exists := p.codes[r.PostFormValue("code")]
+		delete(p.codes, r.PostFormValue("code"))
+		p.mu.Unlock()
+
+		sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if !exists || r.PostFormValue("client_id") != p.clientID || base64.RawURLEncoding.EncodeToString(sum[:]) != authorization.Get("code_challenge") {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		claims := jwt.MapClaims{
+			"iss":   p.URL,
+			"aud":   p.clientID,
+			"sub":   p.subject,
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"nonce": authorization.Get("nonce"),
+		}
+		for k, v := range p.claims {
+			claims[k] = v
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "test-key"
+		idToken, err := token.SignedString(key)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "token_type": "Bearer"})
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+
+	return p
+}
+
+// authorize plays the user logging in at the provider and returns the state and code the provider redirects back with
+func (p *testProvider) authorize(t *testing.T, authURL string, subject string, claims jwt.MapClaims) (string, string) {
+	parsed, err := url.Parse(authURL)
+	assert.NoError(t, err)
+	query := parsed.Query()
+	assert.Equal(t, p.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, "code", query.Get("response_type"))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.subject = subject
+	p.claims = claims
+	code := base64.RawURLEncoding.EncodeToString([]byte(query.Get("state")))[:16]
+	p.codes[code] = query
+
+	return query.Get("state"), code
+}
+
+func newUserService() user.Service {
+	consumer := &autokey.MockConsumer{}
+	consumer.On("Get").Return("superSecretKey", nil)
+
+	return user.NewService(user.NewInMemoryRepository(), user.NewInMemorySessionRepository(), user.NewInMemoryRoleRepository(), user.NewInMemoryAccessTokenRepository(), user.NewArgon2idHasher(&user.Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}), keyring.NewSymmetric(consumer), time.Minute, time.Hour)
+}
+
+func TestCallbackProvisionsUsers(t *testing.T) {
+	ctx := context.Background()
+	idp := newTestProvider(t, "shoplist")
+	provider, err := oidc.Discover(ctx, idp.Client(), idp.URL)
+	assert.NoError(t, err)
+
+	users := newUserService()
+	_, err = users.Store(ctx, "alice", "password")
+	assert.NoError(t, err)
+	srv := oidc.NewService(oidc.NewInMemoryRepository(), users, provider, &oidc.Client{ID: "shoplist", RedirectURL: "http://localhost/callback"}, true)
+
+	authURL, err := srv.AuthorizationURL(ctx)
+	assert.NoError(t, err)
+	state, code := idp.authorize(t, authURL, "alice-subject", jwt.MapClaims{"preferred_username": "alice", "email": "alice@example.com", "email_verified": true})
+
+	// the name is taken by a local user, who is not taken over
+	provisioned, tokens, challenge, err := srv.Callback(ctx, state, code)
+	assert.NoError(t, err)
+	assert.NotNil(t, tokens)
+	assert.Nil(t, challenge)
+	assert.NotEqual(t, "alice", provisioned.Name)
+	assert.Equal(t, "alice@example.com", provisioned.Email)
+	assert.Empty(t, provisioned.Permissions)
+
+	// the state cannot be replayed
+	_, _, _, err = srv.Callback(ctx, state, code)
+	assert.ErrorIs(t, err, oidc.ErrInvalidState)
+
+	// the next login finds the linked user
+	authURL, err = srv.AuthorizationURL(ctx)
+	assert.NoError(t, err)
+	state, code = idp.authorize(t, authURL, "alice-subject", nil)
+	loggedIn, _, _, err := srv.Callback(ctx, state, code)
+	assert.NoError(t, err)
+	assert.Equal(t, provisioned.ID, loggedIn.ID)
+}
+
+func TestCallbackWithoutProvisioning(t *testing.T) {
+	ctx := context.Background()
+	idp := newTestProvider(t, "shoplist")
+	provider, err := oidc.Discover(ctx, idp.Client(), idp.URL)
+	assert.NoError(t, err)
+	srv := oidc.NewService(oidc.NewInMemoryRepository(), newUserService(), provider, &oidc.Client{ID: "shoplist", RedirectURL: "http://localhost/callback"}, false)
+
+	authURL, err := srv.AuthorizationURL(ctx)
+	assert.NoError(t, err)
+	state, code := idp.authorize(t, authURL, "bob-subject", nil)
+
+	_, _, _, err = srv.Callback(ctx, state, code)
+	assert.ErrorIs(t, err, oidc.ErrUnknownIdentity)
+}
+
+func TestCallbackRejectsUntrustedIDTokens(t *testing.T) {
+	ctx := context.Background()
+	idp := newTestProvider(t, "shoplist")
+	provider, err := oidc.Discover(ctx, idp.Client(), idp.URL)
+	assert.NoError(t, err)
+	srv := oidc.NewService(oidc.NewInMemoryRepository(), newUserService(), provider, &oidc.Client{ID: "shoplist", RedirectURL: "http://localhost/callback"}, true)
+
+	cases := map[string]jwt.MapClaims{
+		"audience": {"aud": "someone-else"},
+		"issuer":   {"iss": "https://evil.example.com"},
+		"expiry":   {"exp": time.Now().Add(-time.Minute).Unix()},
+		"nonce":    {"nonce": "replayed"},
+	}
+
+	for name, claims := range cases {
+		authURL, err := srv.AuthorizationURL(ctx)
+		assert.NoError(t, err)
+		state, code := idp.authorize(t, authURL, "mallory-subject", claims)
+
+		_, _, _, err = srv.Callback(ctx, state, code)
+		assert.ErrorIs(t, err, oidc.ErrInvalidIDToken, name)
+	}
+}
+
+func TestDiscoverRejectsMismatchingIssuer(t *testing.T) {
+	idp := newTestProvider(t, "shoplist")
+
+	_, err := oidc.Discover(context.Background(), idp.Client(), idp.URL+"/other")
+	assert.Error(t, err)
+}
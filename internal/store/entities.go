@@ -0,0 +1,55 @@
+package store
+
+import (
+	"strings"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Store is a shop where the items of the lists can be bought
+type Store struct {
+	common.BaseModel `bson:",inline"`
+	Name             string `bson:"name" json:"name"`
+	Address          string `bson:"address" json:"address"`
+}
+
+// PriceSource tells how a price was recorded
+type PriceSource string
+
+const (
+	// SourceTrip is a price captured while shopping
+	SourceTrip PriceSource = "trip"
+
+	// SourceManual is a price entered by hand
+	SourceManual PriceSource = "manual"
+)
+
+// Price is the price of a product in a store at a given time. The amount is in cents
+type Price struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	StoreID    string             `bson:"store_id" json:"store_id"`
+	Product    string             `bson:"product" json:"product"`
+	Amount     int64              `bson:"amount" json:"amount"`
+	Source     PriceSource        `bson:"source" json:"source"`
+	RecordedAt time.Time          `bson:"recorded_at" json:"recorded_at"`
+}
+
+// NewPrice is a Price constructor. The product name is turned into its key
+func NewPrice(storeID string, product string, amount int64, source PriceSource) *Price {
+	return &Price{
+		ID:         primitive.NewObjectID(),
+		StoreID:    storeID,
+		Product:    ProductKey(product),
+		Amount:     amount,
+		Source:     source,
+		RecordedAt: time.Now(),
+	}
+}
+
+// ProductKey returns the key under which the prices of a product are recorded.
+// It is the lowercased name with collapsed spaces so that list items and prices can be matched
+func ProductKey(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
@@ -0,0 +1,133 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InMemoryRepository is an in-memory stores and prices repository
+type InMemoryRepository struct {
+	stores map[string]*Store
+	prices []*Price
+}
+
+// NewInMemoryRepository is a constructor of InMemoryRepository
+func NewInMemoryRepository() Repository {
+	return &InMemoryRepository{
+		stores: make(map[string]*Store),
+		prices: []*Price{},
+	}
+}
+
+// FindStoreByID retrieves a store based on its id
+func (r *InMemoryRepository) FindStoreByID(ctx context.Context, storeID string) (*Store, error) {
+	store, exists := r.stores[storeID]
+	if !exists {
+		return nil, common.NewError(common.ErrNotFound, "there is no store with id %v", storeID)
+	}
+
+	return store, nil
+}
+
+// FindAllStores retrieves all stores
+func (r *InMemoryRepository) FindAllStores(ctx context.Context) ([]*Store, error) {
+	stores := []*Store{}
+	for _, store := range r.stores {
+		stores = append(stores, store)
+	}
+	sort.Slice(stores, func(i, j int) bool {
+		return stores[i].Name < stores[j].Name
+	})
+
+	return stores, nil
+}
+
+// CreateStore inserts a new store
+func (r *InMemoryRepository) CreateStore(ctx context.Context, name string, address string) (*Store, error) {
+	newStore := &Store{
+		BaseModel: common.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+		Name:    name,
+		Address: address,
+	}
+
+	r.stores[newStore.ID.Hex()] = newStore
+
+	return newStore, nil
+}
+
+// DeleteStore removes a store and the prices recorded in it
+func (r *InMemoryRepository) DeleteStore(ctx context.Context, storeID string) (int64, error) {
+	if _, err := r.FindStoreByID(ctx, storeID); err != nil {
+		return -1, err
+	}
+
+	delete(r.stores, storeID)
+
+	prices := []*Price{}
+	for _, price := range r.prices {
+		if price.StoreID != storeID {
+			prices = append(prices, price)
+		}
+	}
+	r.prices = prices
+
+	return 1, nil
+}
+
+// RecordPrices inserts new prices
+func (r *InMemoryRepository) RecordPrices(ctx context.Context, prices ...*Price) (int64, error) {
+	r.prices = append(r.prices, prices...)
+
+	return int64(len(prices)), nil
+}
+
+// FindPriceHistory retrieves all the prices of a product, most recent first
+func (r *InMemoryRepository) FindPriceHistory(ctx context.Context, product string) ([]*Price, error) {
+	key := ProductKey(product)
+	prices := []*Price{}
+	for _, price := range r.prices {
+		if price.Product == key {
+			prices = append(prices, price)
+		}
+	}
+	sort.SliceStable(prices, func(i, j int) bool {
+		return prices[i].RecordedAt.After(prices[j].RecordedAt)
+	})
+
+	return prices, nil
+}
+
+// FindLatestPrices retrieves the latest price of the given products in each store
+func (r *InMemoryRepository) FindLatestPrices(ctx context.Context, products ...string) ([]*Price, error) {
+	keys := make(map[string]bool, len(products))
+	for _, product := range products {
+		keys[ProductKey(product)] = true
+	}
+
+	latest := make(map[[2]string]*Price)
+	for _, price := range r.prices {
+		if !keys[price.Product] {
+			continue
+		}
+
+		id := [2]string{price.StoreID, price.Product}
+		if current, exists := latest[id]; !exists || !price.RecordedAt.Before(current.RecordedAt) {
+			latest[id] = price
+		}
+	}
+
+	prices := []*Price{}
+	for _, price := range latest {
+		prices = append(prices, price)
+	}
+
+	return prices, nil
+}
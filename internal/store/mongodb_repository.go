@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDBRepository contains all the methods to interact with the stores and prices collections
+type MongoDBRepository struct {
+	StoresCollection *mongo.Collection
+	PricesCollection *mongo.Collection
+}
+
+// NewMongoDBRepository is a constructor for MongoDBRepository
+func NewMongoDBRepository(stores *mongo.Collection, prices *mongo.Collection) Repository {
+	return &MongoDBRepository{
+		StoresCollection: stores,
+		PricesCollection: prices,
+	}
+}
+
+// FindStoreByID retrieves a store based on its id
+func (r *MongoDBRepository) FindStoreByID(ctx context.Context, storeID string) (*Store, error) {
+	objectID, err := common.ObjectIDFromHex(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var store Store
+	if err := r.StoresCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&store); err != nil {
+		return nil, common.MongoError(err, "store %v", storeID)
+	}
+
+	return &store, nil
+}
+
+// FindAllStores retrieves all stores
+func (r *MongoDBRepository) FindAllStores(ctx context.Context) ([]*Store, error) {
+	stores := []*Store{}
+	cursor, err := r.StoresCollection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(ctx, &stores); err != nil {
+		return nil, err
+	}
+
+	return stores, nil
+}
+
+// CreateStore inserts a new store
+func (r *MongoDBRepository) CreateStore(ctx context.Context, name string, address string) (*Store, error) {
+	store := Store{
+		BaseModel: common.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+		Name:    name,
+		Address: address,
+	}
+
+	if _, err := r.StoresCollection.InsertOne(ctx, store); err != nil {
+		return nil, err
+	}
+
+	return &store, nil
+}
+
+// DeleteStore removes a store and the prices recorded in it
+func (r *MongoDBRepository) DeleteStore(ctx context.Context, storeID string) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(storeID)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.StoresCollection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return -1, err
+	}
+
+	if _, err := r.PricesCollection.DeleteMany(ctx, bson.M{"store_id": storeID}); err != nil {
+		return -1, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// RecordPrices inserts new prices
+func (r *MongoDBRepository) RecordPrices(ctx context.Context, prices ...*Price) (int64, error) {
+	if len(prices) == 0 {
+		return 0, nil
+	}
+
+	documents := make([]interface{}, len(prices))
+	for i, price := range prices {
+		documents[i] = price
+	}
+
+	result, err := r.PricesCollection.InsertMany(ctx, documents)
+	if err != nil {
+		return -1, err
+	}
+
+	return int64(len(result.InsertedIDs)), nil
+}
+
+// FindPriceHistory retrieves all the prices of a product, most recent first
+func (r *MongoDBRepository) FindPriceHistory(ctx context.Context, product string) ([]*Price, error) {
+	prices := []*Price{}
+	cursor, err := r.PricesCollection.Find(
+		ctx,
+		bson.M{"product": ProductKey(product)},
+		options.Find().SetSort(bson.M{"recorded_at": -1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(ctx, &prices); err != nil {
+		return nil, err
+	}
+
+	return prices, nil
+}
+
+// FindLatestPrices retrieves the latest price of the given products in each store
+func (r *MongoDBRepository) FindLatestPrices(ctx context.Context, products ...string) ([]*Price, error) {
+	keys := make([]string, len(products))
+	for i, product := range products {
+		keys[i] = ProductKey(product)
+	}
+
+	prices := []*Price{}
+	cursor, err := r.PricesCollection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"product": bson.M{"$in": keys}}}},
+		{{Key: "$sort", Value: bson.M{"recorded_at": -1}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"store_id": "$store_id", "product": "$product"},
+			"price": bson.M{"$first": "$$ROOT"},
+		}}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$price"}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(ctx, &prices); err != nil {
+		return nil, err
+	}
+
+	return prices, nil
+}
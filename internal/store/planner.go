@@ -0,0 +1,174 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
+)
+
+// PlanMode tells how the items of a list are assigned to the stores
+type PlanMode string
+
+const (
+	// ModeSplit buys every item in the store where it is the cheapest
+	ModeSplit PlanMode = "split"
+
+	// ModeSingle buys everything in the one store with the lowest total
+	ModeSingle PlanMode = "single"
+)
+
+// ErrUnknownMode is returned when planning with a mode that does not exist
+var ErrUnknownMode = common.NewError(common.ErrValidation, "unknown plan mode")
+
+// PlannedItem is an item of a list along with the price it will be bought at
+type PlannedItem struct {
+	Item  *list.Item `json:"item"`
+	Price *Price     `json:"price"`
+}
+
+// StorePlan is the part of a plan to buy in a store
+type StorePlan struct {
+	Store *Store         `json:"store"`
+	Items []*PlannedItem `json:"items"`
+	Total int64          `json:"total"`
+}
+
+// Plan tells where to buy the items of a list. Items without a known price in the chosen stores are left unpriced
+type Plan struct {
+	Mode     PlanMode     `json:"mode"`
+	Stores   []*StorePlan `json:"stores"`
+	Unpriced []*list.Item `json:"unpriced"`
+	Total    int64        `json:"total"`
+}
+
+// PlanList assigns the items left to buy in the list to the stores, using the latest price of each product in each store.
+// Prices are per item as recorded, quantities are not taken into account
+func PlanList(l *list.Shoppinglist, stores []*Store, prices []*Price, mode PlanMode) (*Plan, error) {
+	// latest known price of each product indexed by store
+	byStore := make(map[string]map[string]*Price, len(stores))
+	for _, store := range stores {
+		byStore[store.ID.Hex()] = make(map[string]*Price)
+	}
+	for _, price := range prices {
+		storePrices, exists := byStore[price.StoreID]
+		if !exists {
+			continue
+		}
+		if current, exists := storePrices[price.Product]; !exists || price.RecordedAt.After(current.RecordedAt) {
+			storePrices[price.Product] = price
+		}
+	}
+
+	items := []*list.Item{}
+	for _, item := range l.Items {
+		if !item.Done {
+			items = append(items, item)
+		}
+	}
+
+	switch mode {
+	case ModeSplit:
+		return planSplit(items, stores, byStore), nil
+	case ModeSingle:
+		return planSingle(items, stores, byStore), nil
+	default:
+		return nil, fmt.Errorf("%w %v", ErrUnknownMode, mode)
+	}
+}
+
+func planSplit(items []*list.Item, stores []*Store, byStore map[string]map[string]*Price) *Plan {
+	plan := &Plan{
+		Mode:     ModeSplit,
+		Stores:   []*StorePlan{},
+		Unpriced: []*list.Item{},
+	}
+	storePlans := make(map[string]*StorePlan)
+
+	for _, item := range items {
+		key := ProductKey(item.Name)
+
+		var cheapest *Store
+		var cheapestPrice *Price
+		for _, store := range stores {
+			price, exists := byStore[store.ID.Hex()][key]
+			if exists && (cheapestPrice == nil || price.Amount < cheapestPrice.Amount) {
+				cheapest = store
+				cheapestPrice = price
+			}
+		}
+
+		if cheapest == nil {
+			plan.Unpriced = append(plan.Unpriced, item)
+			continue
+		}
+
+		storePlan, exists := storePlans[cheapest.ID.Hex()]
+		if !exists {
+			storePlan = &StorePlan{
+				Store: cheapest,
+				Items: []*PlannedItem{},
+			}
+			storePlans[cheapest.ID.Hex()] = storePlan
+		}
+		storePlan.Items = append(storePlan.Items, &PlannedItem{Item: item, Price: cheapestPrice})
+		storePlan.Total += cheapestPrice.Amount
+		plan.Total += cheapestPrice.Amount
+	}
+
+	// keep the order of the stores
+	for _, store := range stores {
+		if storePlan, exists := storePlans[store.ID.Hex()]; exists {
+			plan.Stores = append(plan.Stores, storePlan)
+		}
+	}
+
+	return plan
+}
+
+func planSingle(items []*list.Item, stores []*Store, byStore map[string]map[string]*Price) *Plan {
+	plan := &Plan{
+		Mode:     ModeSingle,
+		Stores:   []*StorePlan{},
+		Unpriced: items,
+	}
+
+	// the best store is the one pricing the most items, then the one with the lowest total
+	var best *StorePlan
+	var bestUnpriced []*list.Item
+	for _, store := range stores {
+		storePlan := &StorePlan{
+			Store: store,
+			Items: []*PlannedItem{},
+		}
+		unpriced := []*list.Item{}
+		for _, item := range items {
+			price, exists := byStore[store.ID.Hex()][ProductKey(item.Name)]
+			if !exists {
+				unpriced = append(unpriced, item)
+				continue
+			}
+			storePlan.Items = append(storePlan.Items, &PlannedItem{Item: item, Price: price})
+			storePlan.Total += price.Amount
+		}
+
+		if len(storePlan.Items) == 0 {
+			continue
+		}
+
+		if best == nil ||
+			len(storePlan.Items) > len(best.Items) ||
+			(len(storePlan.Items) == len(best.Items) && storePlan.Total < best.Total) {
+			best = storePlan
+			bestUnpriced = unpriced
+		}
+	}
+
+	if best != nil {
+		plan.Stores = append(plan.Stores, best)
+		plan.Unpriced = bestUnpriced
+		plan.Total = best.Total
+	}
+
+	return plan
+}
@@ -0,0 +1,75 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/store"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newStore(name string) *store.Store {
+	return &store.Store{
+		BaseModel: common.BaseModel{ID: primitive.NewObjectID()},
+		Name:      name,
+	}
+}
+
+func price(s *store.Store, product string, amount int64, age time.Duration) *store.Price {
+	p := store.NewPrice(s.ID.Hex(), product, amount, store.SourceManual)
+	p.RecordedAt = p.RecordedAt.Add(-age)
+	return p
+}
+
+func TestPlanList(t *testing.T) {
+	a, b, c := newStore("a"), newStore("b"), newStore("c")
+	stores := []*store.Store{a, b, c}
+	l := &list.Shoppinglist{
+		Items: []*list.Item{
+			{Name: "Milk", Quantity: "1"},
+			{Name: "bread", Quantity: "1"},
+			{Name: "Coffee  beans", Quantity: "1"},
+			{Name: "saffron", Quantity: "1"},
+			{Name: "eggs", Quantity: "12", Done: true},
+		},
+	}
+	prices := []*store.Price{
+		price(a, "milk", 100, 0),
+		price(a, "bread", 250, 0),
+		price(a, "coffee beans", 900, 0),
+		price(b, "milk", 90, 0),
+		price(b, "bread", 200, 0),
+		// the old price of b is ignored
+		price(b, "coffee beans", 500, 48*time.Hour),
+		price(b, "coffee beans", 1000, 0),
+		price(c, "coffee beans", 700, 0),
+		price(c, "eggs", 10, 0),
+	}
+
+	plan, err := store.PlanList(l, stores, prices, store.ModeSplit)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(90+200+700), plan.Total)
+	if assert.Len(t, plan.Stores, 2) {
+		assert.Equal(t, b, plan.Stores[0].Store)
+		assert.Len(t, plan.Stores[0].Items, 2)
+		assert.Equal(t, c, plan.Stores[1].Store)
+		assert.Len(t, plan.Stores[1].Items, 1)
+	}
+	if assert.Len(t, plan.Unpriced, 1) {
+		assert.Equal(t, "saffron", plan.Unpriced[0].Name)
+	}
+
+	plan, err = store.PlanList(l, stores, prices, store.ModeSingle)
+	assert.NoError(t, err)
+	if assert.Len(t, plan.Stores, 1) {
+		assert.Equal(t, a, plan.Stores[0].Store)
+	}
+	assert.Equal(t, int64(100+250+900), plan.Total)
+	assert.Len(t, plan.Unpriced, 1)
+
+	_, err = store.PlanList(l, stores, prices, "cheapest")
+	assert.ErrorIs(t, err, store.ErrUnknownMode)
+}
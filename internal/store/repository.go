@@ -0,0 +1,49 @@
+package store
+
+import "context"
+
+// FinderByID is a single method interface for finding a store by id
+type FinderByID interface {
+	FindStoreByID(ctx context.Context, storeID string) (*Store, error)
+}
+
+// Finder is a single method interface for listing the stores
+type Finder interface {
+	FindAllStores(ctx context.Context) ([]*Store, error)
+}
+
+// Creator is a single method interface for creating a store
+type Creator interface {
+	CreateStore(ctx context.Context, name string, address string) (*Store, error)
+}
+
+// Deleter is a single method interface for deleting a store along with its prices
+type Deleter interface {
+	DeleteStore(ctx context.Context, storeID string) (int64, error)
+}
+
+// PriceRecorder is a single method interface for recording prices
+type PriceRecorder interface {
+	RecordPrices(ctx context.Context, prices ...*Price) (int64, error)
+}
+
+// PriceHistoryFinder is a single method interface for retrieving all the prices recorded for a product, most recent first
+type PriceHistoryFinder interface {
+	FindPriceHistory(ctx context.Context, product string) ([]*Price, error)
+}
+
+// LatestPriceFinder is a single method interface for retrieving the latest price of each product in each store
+type LatestPriceFinder interface {
+	FindLatestPrices(ctx context.Context, products ...string) ([]*Price, error)
+}
+
+// Repository is a wrapper around all the single method interfaces defining the stores and prices storage
+type Repository interface {
+	FinderByID
+	Finder
+	Creator
+	Deleter
+	PriceRecorder
+	PriceHistoryFinder
+	LatestPriceFinder
+}
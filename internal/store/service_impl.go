@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
+)
+
+// ErrInvalidPrice is returned when recording a price that is not positive or has no product
+var ErrInvalidPrice = common.NewError(common.ErrValidation, "invalid price")
+
+// ServiceImpl is the concrete implementation of the store service interface
+type ServiceImpl struct {
+	repo Repository
+}
+
+// NewService inits a new store service
+func NewService(repo Repository) Service {
+	return &ServiceImpl{
+		repo: repo,
+	}
+}
+
+// FindStoreByID returns the store identified by the given id
+func (s *ServiceImpl) FindStoreByID(ctx context.Context, storeID string) (*Store, error) {
+	return s.repo.FindStoreByID(ctx, storeID)
+}
+
+// FindAllStores returns all the stores
+func (s *ServiceImpl) FindAllStores(ctx context.Context) ([]*Store, error) {
+	return s.repo.FindAllStores(ctx)
+}
+
+// CreateStore creates a new store
+func (s *ServiceImpl) CreateStore(ctx context.Context, name string, address string) (*Store, error) {
+	return s.repo.CreateStore(ctx, name, address)
+}
+
+// DeleteStore deletes a store and its prices
+func (s *ServiceImpl) DeleteStore(ctx context.Context, storeID string) (int64, error) {
+	return s.repo.DeleteStore(ctx, storeID)
+}
+
+// FindPriceHistory returns all the prices recorded for a product, most recent first
+func (s *ServiceImpl) FindPriceHistory(ctx context.Context, product string) ([]*Price, error) {
+	return s.repo.FindPriceHistory(ctx, product)
+}
+
+// RecordPrices records the prices of products in a store
+func (s *ServiceImpl) RecordPrices(ctx context.Context, storeID string, source PriceSource, entries ...*PriceEntry) (int64, error) {
+	if source != SourceTrip && source != SourceManual {
+		return -1, common.NewError(common.ErrValidation, "unknown price source %v", source)
+	}
+
+	if _, err := s.repo.FindStoreByID(ctx, storeID); err != nil {
+		return -1, err
+	}
+
+	prices := make([]*Price, len(entries))
+	for i, entry := range entries {
+		if ProductKey(entry.Product) == "" || entry.Amount <= 0 {
+			return -1, fmt.Errorf("%w for product %q : %v", ErrInvalidPrice, entry.Product, entry.Amount)
+		}
+		prices[i] = NewPrice(storeID, entry.Product, entry.Amount, source)
+	}
+
+	return s.repo.RecordPrices(ctx, prices...)
+}
+
+// PlanList tells where to buy the items of the list
+func (s *ServiceImpl) PlanList(ctx context.Context, l *list.Shoppinglist, mode PlanMode) (*Plan, error) {
+	stores, err := s.repo.FindAllStores(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]string, len(l.Items))
+	for i, item := range l.Items {
+		products[i] = item.Name
+	}
+
+	prices, err := s.repo.FindLatestPrices(ctx, products...)
+	if err != nil {
+		return nil, err
+	}
+
+	return PlanList(l, stores, prices, mode)
+}
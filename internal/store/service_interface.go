@@ -0,0 +1,24 @@
+package store
+
+import (
+	"context"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/list"
+)
+
+// PriceEntry is a price to record for a product
+type PriceEntry struct {
+	Product string `json:"product"`
+	Amount  int64  `json:"amount"`
+}
+
+// Service defines the operations on the stores, their prices and the shopping plans
+type Service interface {
+	FinderByID
+	Finder
+	Creator
+	Deleter
+	PriceHistoryFinder
+	RecordPrices(ctx context.Context, storeID string, source PriceSource, entries ...*PriceEntry) (int64, error)
+	PlanList(ctx context.Context, l *list.Shoppinglist, mode PlanMode) (*Plan, error)
+}
@@ -0,0 +1,76 @@
+package user
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// accessTokenPrefix starts every personal access token so that they are told apart from the JWTs, and easy to spot in leaked secrets
+	accessTokenPrefix = "slp_"
+	// accessTokenTouchInterval is the minimum time between two updates of the last used timestamp of a token
+	accessTokenTouchInterval = time.Minute
+)
+
+var (
+	// ErrInvalidAccessToken is returned when authenticating with an unknown or expired personal access token
+	ErrInvalidAccessToken = errors.New("invalid personal access token")
+	// ErrScopedCredentials is returned when managing personal access tokens while authenticated with one
+	ErrScopedCredentials = common.NewError(common.ErrForbidden, "personal access tokens cannot manage personal access tokens")
+	// ErrAccessTokenLogout is returned when logging out with a personal access token, which has no session and is revoked instead
+	ErrAccessTokenLogout = common.NewError(common.ErrValidation, "personal access tokens have no session to log out, revoke the token instead")
+)
+
+// AccessToken is a named, long-lived credential restricted to scopes, meant for scripts. Only the hash of the token is stored
+type AccessToken struct {
+	common.BaseModel `bson:",inline"`
+	UserID           string        `bson:"user_id" json:"user_id"`
+	Name             string        `bson:"name" json:"name"`
+	TokenHash        string        `bson:"token_hash" json:"-"`
+	Scopes           []*Permission `bson:"scopes" json:"scopes"`
+	ExpiresAt        *time.Time    `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+	LastUsedAt       *time.Time    `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+}
+
+// NewAccessToken is an AccessToken constructor. It returns the access token along with the token given to the user.
+// A zero validity means that the token never expires
+func NewAccessToken(userID string, name string, validity time.Duration, scopes ...*Permission) (*AccessToken, string, error) {
+	secret, _, err := newSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	token := accessTokenPrefix + secret
+
+	now := time.Now()
+	accessToken := &AccessToken{
+		BaseModel: common.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hashSecret(token),
+		Scopes:    scopes,
+	}
+	if validity > 0 {
+		expiresAt := now.Add(validity)
+		accessToken.ExpiresAt = &expiresAt
+	}
+
+	return accessToken, token, nil
+}
+
+// IsExpired returns true if the token has an expiry which is past
+func (t *AccessToken) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// isAccessToken returns true if the token looks like a personal access token
+func isAccessToken(token string) bool {
+	return strings.HasPrefix(token, accessTokenPrefix)
+}
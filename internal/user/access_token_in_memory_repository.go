@@ -0,0 +1,97 @@
+package user
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+)
+
+// InMemoryAccessTokenRepository is an in-memory personal access token repository
+type InMemoryAccessTokenRepository struct {
+	mu           sync.Mutex
+	accessTokens map[string]*AccessToken
+}
+
+// NewInMemoryAccessTokenRepository inits a new in-memory personal access token repository
+func NewInMemoryAccessTokenRepository() AccessTokenRepository {
+	return &InMemoryAccessTokenRepository{
+		accessTokens: make(map[string]*AccessToken),
+	}
+}
+
+// FindAccessTokenByHash returns the personal access token matching the hash
+func (r *InMemoryAccessTokenRepository) FindAccessTokenByHash(ctx context.Context, tokenHash string) (*AccessToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, accessToken := range r.accessTokens {
+		if accessToken.TokenHash == tokenHash {
+			copied := *accessToken
+			return &copied, nil
+		}
+	}
+
+	return nil, ErrInvalidAccessToken
+}
+
+// FindAccessTokensByUser returns the personal access tokens of the user, most recent first
+func (r *InMemoryAccessTokenRepository) FindAccessTokensByUser(ctx context.Context, userID string) ([]*AccessToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	accessTokens := []*AccessToken{}
+	for _, accessToken := range r.accessTokens {
+		if accessToken.UserID == userID {
+			copied := *accessToken
+			accessTokens = append(accessTokens, &copied)
+		}
+	}
+
+	sort.Slice(accessTokens, func(i, j int) bool {
+		return accessTokens[i].CreatedAt.After(accessTokens[j].CreatedAt)
+	})
+
+	return accessTokens, nil
+}
+
+// StoreAccessToken stores the personal access token
+func (r *InMemoryAccessTokenRepository) StoreAccessToken(ctx context.Context, accessToken *AccessToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *accessToken
+	r.accessTokens[accessToken.ID.Hex()] = &copied
+
+	return nil
+}
+
+// TouchAccessToken records the last use of the personal access token
+func (r *InMemoryAccessTokenRepository) TouchAccessToken(ctx context.Context, tokenID string, usedAt time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	accessToken, exists := r.accessTokens[tokenID]
+	if !exists {
+		return -1, common.NewError(common.ErrNotFound, "there is no personal access token with id %v", tokenID)
+	}
+	accessToken.LastUsedAt = &usedAt
+
+	return 1, nil
+}
+
+// DeleteAccessToken removes a personal access token of the user
+func (r *InMemoryAccessTokenRepository) DeleteAccessToken(ctx context.Context, userID string, tokenID string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	accessToken, exists := r.accessTokens[tokenID]
+	if !exists || accessToken.UserID != userID {
+		return 0, nil
+	}
+	delete(r.accessTokens, tokenID)
+
+	return 1, nil
+}
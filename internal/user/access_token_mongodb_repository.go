@@ -0,0 +1,94 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDBAccessTokenRepository is a personal access token repository based on mongodb
+type MongoDBAccessTokenRepository struct {
+	AccessTokenCollection *mongo.Collection
+}
+
+// NewMongoDBAccessTokenRepository inits a new mongodb personal access token repository
+func NewMongoDBAccessTokenRepository(collection *mongo.Collection) AccessTokenRepository {
+	return &MongoDBAccessTokenRepository{
+		AccessTokenCollection: collection,
+	}
+}
+
+// FindAccessTokenByHash returns the personal access token matching the hash
+func (r *MongoDBAccessTokenRepository) FindAccessTokenByHash(ctx context.Context, tokenHash string) (*AccessToken, error) {
+	var accessToken AccessToken
+	err := r.AccessTokenCollection.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&accessToken)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrInvalidAccessToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &accessToken, nil
+}
+
+// FindAccessTokensByUser returns the personal access tokens of the user, most recent first
+func (r *MongoDBAccessTokenRepository) FindAccessTokensByUser(ctx context.Context, userID string) ([]*AccessToken, error) {
+	accessTokens := []*AccessToken{}
+	cursor, err := r.AccessTokenCollection.Find(ctx, bson.M{"user_id": userID}, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(ctx, &accessTokens); err != nil {
+		return nil, err
+	}
+
+	return accessTokens, nil
+}
+
+// StoreAccessToken inserts the personal access token
+func (r *MongoDBAccessTokenRepository) StoreAccessToken(ctx context.Context, accessToken *AccessToken) error {
+	_, err := r.AccessTokenCollection.InsertOne(ctx, accessToken)
+
+	return err
+}
+
+// TouchAccessToken records the last use of the personal access token
+func (r *MongoDBAccessTokenRepository) TouchAccessToken(ctx context.Context, tokenID string, usedAt time.Time) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(tokenID)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.AccessTokenCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "last_used_at", Value: usedAt}}}},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// DeleteAccessToken removes a personal access token of the user
+func (r *MongoDBAccessTokenRepository) DeleteAccessToken(ctx context.Context, userID string, tokenID string) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(tokenID)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.AccessTokenCollection.DeleteOne(ctx, bson.M{"_id": objectID, "user_id": userID})
+	if err != nil {
+		return -1, err
+	}
+
+	return result.DeletedCount, nil
+}
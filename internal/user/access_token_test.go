@@ -0,0 +1,70 @@
+package user_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessTokens(t *testing.T) {
+	ctx := context.Background()
+	srv := user.NewService(user.NewInMemoryRepository(), user.NewInMemorySessionRepository(), user.NewInMemoryRoleRepository(), user.NewInMemoryAccessTokenRepository(), testHasher, nil, time.Minute, time.Hour)
+
+	alice, err := srv.Store(ctx, "alice", "password", &user.Permission{Action: "write", ResourceID: "list-*"})
+	assert.NoError(t, err)
+	alice, err = srv.FindByID(ctx, alice.ID.Hex())
+	assert.NoError(t, err)
+
+	// scopes are restricted to the permissions of the user
+	_, _, err = srv.CreateAccessToken(ctx, alice, "admin", 0, &user.Permission{Action: "write", ResourceID: "users"})
+	assert.Error(t, err)
+
+	accessToken, token, err := srv.CreateAccessToken(ctx, alice, "home automation", 0, &user.Permission{Action: "read", ResourceID: "list-*"})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, "slp_"))
+	assert.NotEqual(t, token, accessToken.TokenHash)
+
+	authenticated, err := srv.Authenticate(ctx, token)
+	assert.NoError(t, err)
+	assert.Equal(t, alice.ID, authenticated.ID)
+	assert.NoError(t, authenticated.Can("read", "list-1"))
+	assert.Error(t, authenticated.Can("write", "list-1"))
+
+	tokens, err := srv.FindAccessTokens(ctx, alice.ID.Hex())
+	assert.NoError(t, err)
+	assert.Len(t, tokens, 1)
+	assert.NotNil(t, tokens[0].LastUsedAt)
+
+	// a token cannot mint other tokens
+	_, _, err = srv.CreateAccessToken(ctx, authenticated, "escalation", 0)
+	assert.ErrorIs(t, err, user.ErrScopedCredentials)
+
+	n, err := srv.RevokeAccessToken(ctx, "someoneElse", accessToken.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+	n, err = srv.RevokeAccessToken(ctx, alice.ID.Hex(), accessToken.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	_, err = srv.Authenticate(ctx, token)
+	assert.ErrorIs(t, err, user.ErrInvalidAccessToken)
+}
+
+func TestExpiredAccessToken(t *testing.T) {
+	ctx := context.Background()
+	srv := user.NewService(user.NewInMemoryRepository(), user.NewInMemorySessionRepository(), user.NewInMemoryRoleRepository(), user.NewInMemoryAccessTokenRepository(), testHasher, nil, time.Minute, time.Hour)
+
+	alice, err := srv.Store(ctx, "alice", "password")
+	assert.NoError(t, err)
+
+	_, token, err := srv.CreateAccessToken(ctx, alice, "short lived", time.Nanosecond)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond)
+
+	_, err = srv.Authenticate(ctx, token)
+	assert.ErrorIs(t, err, user.ErrInvalidAccessToken)
+}
@@ -0,0 +1,101 @@
+package user
+
+import (
+	"strings"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+)
+
+const (
+	// defaultPageSize is the number of users per page when the query does not set it
+	defaultPageSize = 20
+	// maxPageSize is the maximum number of users per page
+	maxPageSize = 100
+)
+
+var (
+	// ErrUserDisabled is returned when a disabled user logs in or uses a token
+	ErrUserDisabled = common.NewError(common.ErrForbidden, "the user is disabled")
+	// ErrPasswordResetRequired is returned when a user who has to reset their password logs in with it
+	ErrPasswordResetRequired = common.NewError(common.ErrForbidden, "the password has to be reset")
+)
+
+// UserQuery selects users in the directory. The zero value selects every user, and each set field narrows the selection
+type UserQuery struct {
+	// NamePrefix selects the users whose name starts with it
+	NamePrefix string
+	// Permission selects the users holding a permission on the resource, or with the action, or both. Role permissions are not considered
+	PermissionAction   string
+	PermissionResource string
+	// Role selects the users having the role
+	Role string
+	// Disabled selects the disabled users when true, and the enabled ones when false
+	Disabled *bool
+	// Page starts at 1, and PageSize is bounded by maxPageSize
+	Page     int64
+	PageSize int64
+}
+
+// normalize bounds the page and the page size and trims the name prefix
+func (q *UserQuery) normalize() {
+	q.NamePrefix = strings.TrimSpace(q.NamePrefix)
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 {
+		q.PageSize = defaultPageSize
+	}
+	if q.PageSize > maxPageSize {
+		q.PageSize = maxPageSize
+	}
+}
+
+// skip returns the number of users before the page
+func (q *UserQuery) skip() int64 {
+	return (q.Page - 1) * q.PageSize
+}
+
+// matches returns true if the user is selected by the query
+func (q *UserQuery) matches(u *User) bool {
+	if !strings.HasPrefix(u.Name, q.NamePrefix) {
+		return false
+	}
+
+	if q.Disabled != nil && u.Disabled != *q.Disabled {
+		return false
+	}
+
+	if q.Role != "" && !containsString(u.Roles, q.Role) {
+		return false
+	}
+
+	if q.PermissionAction == "" && q.PermissionResource == "" {
+		return true
+	}
+	for _, p := range u.Permissions {
+		if (q.PermissionAction == "" || p.Action == q.PermissionAction) && (q.PermissionResource == "" || p.ResourceID == q.PermissionResource) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UserPage is a page of the users selected by a query, sorted by name
+type UserPage struct {
+	Users    []*User
+	Total    int64
+	Page     int64
+	PageSize int64
+}
+
+// containsString returns true if the value is in the slice
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
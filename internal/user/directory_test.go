@@ -0,0 +1,121 @@
+package user_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchUsers(t *testing.T) {
+	ctx := context.Background()
+	srv := user.NewService(user.NewInMemoryRepository(), user.NewInMemorySessionRepository(), user.NewInMemoryRoleRepository(), user.NewInMemoryAccessTokenRepository(), testHasher, nil, time.Minute, time.Hour)
+
+	for _, name := range []string{"carol", "alice", "albert", "bob"} {
+		_, err := srv.Store(ctx, name, "password")
+		assert.NoError(t, err)
+	}
+	_, err := srv.Store(ctx, "bob", "password")
+	assert.ErrorIs(t, err, common.ErrConflict)
+	_, err = srv.FindByID(ctx, "not an id")
+	assert.ErrorIs(t, err, common.ErrValidation)
+	bob, err := srv.FindByName(ctx, "bob")
+	assert.NoError(t, err)
+	_, err = srv.AddPermissions(ctx, nil, bob.ID.Hex(), &user.Permission{Action: "write", ResourceID: "users"})
+	assert.NoError(t, err)
+
+	// users are sorted by name and paginated
+	page, err := srv.SearchUsers(ctx, &user.UserQuery{PageSize: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), page.Total)
+	assert.Len(t, page.Users, 3)
+	assert.Equal(t, "albert", page.Users[0].Name)
+	page, err = srv.SearchUsers(ctx, &user.UserQuery{Page: 2, PageSize: 3})
+	assert.NoError(t, err)
+	assert.Len(t, page.Users, 1)
+	assert.Equal(t, "carol", page.Users[0].Name)
+	page, err = srv.SearchUsers(ctx, &user.UserQuery{Page: 3, PageSize: 3})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Users)
+
+	page, err = srv.SearchUsers(ctx, &user.UserQuery{NamePrefix: "al"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), page.Total)
+	assert.Equal(t, int64(1), page.Page)
+	assert.Equal(t, int64(20), page.PageSize)
+
+	page, err = srv.SearchUsers(ctx, &user.UserQuery{PermissionResource: "users"})
+	assert.NoError(t, err)
+	assert.Len(t, page.Users, 1)
+	assert.Equal(t, "bob", page.Users[0].Name)
+
+	// disabled users are filtered on and refused
+	n, err := srv.DisableUsers(ctx, bob.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	disabled := true
+	page, err = srv.SearchUsers(ctx, &user.UserQuery{Disabled: &disabled})
+	assert.NoError(t, err)
+	assert.Len(t, page.Users, 1)
+	assert.Equal(t, "bob", page.Users[0].Name)
+
+	_, _, _, err = srv.Login(ctx, "bob", "password")
+	assert.ErrorIs(t, err, user.ErrUserDisabled)
+
+	n, err = srv.EnableUsers(ctx, bob.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	page, err = srv.SearchUsers(ctx, &user.UserQuery{Disabled: &disabled})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Users)
+}
+
+func TestDisabledUserAccessToken(t *testing.T) {
+	ctx := context.Background()
+	srv := user.NewService(user.NewInMemoryRepository(), user.NewInMemorySessionRepository(), user.NewInMemoryRoleRepository(), user.NewInMemoryAccessTokenRepository(), testHasher, nil, time.Minute, time.Hour)
+
+	alice, err := srv.Store(ctx, "alice", "password")
+	assert.NoError(t, err)
+	_, token, err := srv.CreateAccessToken(ctx, alice, "script", 0)
+	assert.NoError(t, err)
+
+	_, err = srv.DisableUsers(ctx, alice.ID.Hex())
+	assert.NoError(t, err)
+
+	_, err = srv.Authenticate(ctx, token)
+	assert.ErrorIs(t, err, user.ErrUserDisabled)
+}
+
+func TestForcePasswordReset(t *testing.T) {
+	ctx := context.Background()
+	users := user.NewService(user.NewInMemoryRepository(), user.NewInMemorySessionRepository(), user.NewInMemoryRoleRepository(), user.NewInMemoryAccessTokenRepository(), testHasher, nil, time.Minute, time.Hour)
+	notifier := &recordingNotifier{}
+	srv := user.NewResetService(users, user.NewInMemoryResetTokenRepository(), user.NewInMemoryLoginAttemptsRepository(), notifier, &user.ResetPolicy{}, time.Hour, "")
+
+	alice, err := users.Store(ctx, "alice", "compromised")
+	assert.NoError(t, err)
+	_, err = users.UpdateEmail(ctx, alice.ID.Hex(), "alice@example.org")
+	assert.NoError(t, err)
+	bob, err := users.Store(ctx, "bob", "compromised")
+	assert.NoError(t, err)
+
+	n, unreachable, err := srv.ForcePasswordReset(ctx, alice.ID.Hex(), bob.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.Equal(t, []string{bob.ID.Hex()}, unreachable)
+	assert.Len(t, notifier.sent(), 1)
+	assert.Equal(t, "alice@example.org", notifier.sent()[0].To)
+
+	// the old password is refused until a new one is set
+	_, _, _, err = users.Login(ctx, "alice", "compromised")
+	assert.ErrorIs(t, err, user.ErrPasswordResetRequired)
+
+	_, err = users.SetPassword(ctx, alice.ID.Hex(), "new password")
+	assert.NoError(t, err)
+	reset, err := users.FindByID(ctx, alice.ID.Hex())
+	assert.NoError(t, err)
+	assert.False(t, reset.PasswordResetRequired)
+}
@@ -1,7 +1,8 @@
 package user
 
 import (
-	"fmt"
+	"path"
+	"strings"
 	"time"
 
 	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
@@ -12,8 +13,22 @@ import (
 type User struct {
 	common.BaseModel `bson:",inline"`
 	Name             string        `bson:"name" json:"name"`
-	Password         string        `bson:"password"`
-	Permissions      []*Permission `bson:"permissions"`
+	Avatar           string        `bson:"avatar" json:"avatar"`
+	Email            string        `bson:"email" json:"-"`
+	Password         string        `bson:"password" json:"-"`
+	Permissions      []*Permission `bson:"permissions" json:"-"`
+	Roles            []string      `bson:"roles" json:"-"`
+	TOTP             *TOTP         `bson:"totp,omitempty" json:"-"`
+	Profile          *Profile      `bson:"profile,omitempty" json:"-"`
+	// Disabled users can neither log in nor use their tokens
+	Disabled bool `bson:"disabled" json:"-"`
+	// PasswordResetRequired users cannot log in with their password until they reset it
+	PasswordResetRequired bool `bson:"password_reset_required" json:"-"`
+
+	// RolePermissions are the permissions granted by the roles of the user. They are resolved on authentication
+	RolePermissions []*Permission `bson:"-" json:"-"`
+	// Scopes restrict the permissions of the user when authenticated with a personal access token. Nil means unrestricted
+	Scopes []*Permission `bson:"-" json:"-"`
 }
 
 // NewUser is a User constructor
@@ -30,22 +45,81 @@ func NewUser(name string, password string, permissions ...*Permission) *User {
 	}
 }
 
-// Can checks if the user can do the action on the resource
+// Can checks if the user can do the action on the resource with its own permissions or the ones of its roles.
+// When the user is restricted to scopes, one of them must allow it too
 func (u *User) Can(action string, resourceID string) error {
-	for _, p := range u.Permissions {
-		if p.Action == "*" && p.ResourceID == "*" {
-			return nil
+	if u.Scopes != nil && !allowedByAny(u.Scopes, action, resourceID) {
+		return common.NewError(common.ErrForbidden, "User %s cannot %s on resource %s with the scopes of the token", u.Name, action, resourceID)
+	}
+
+	if allowedByAny(u.Permissions, action, resourceID) || allowedByAny(u.RolePermissions, action, resourceID) {
+		return nil
+	}
+
+	return common.NewError(common.ErrForbidden, "User %s cannot %s on resource %s", u.Name, action, resourceID)
+}
+
+// allowedByAny returns true if one of the permissions allows the action on the resource
+func allowedByAny(permissions []*Permission, action string, resourceID string) bool {
+	for _, p := range permissions {
+		if p.Allows(action, resourceID) {
+			return true
 		}
-		if p.Action == action && p.ResourceID == resourceID {
-			return nil
+	}
+
+	return false
+}
+
+// HasTwoFactor returns true if the user has to give a TOTP code after the password
+func (u *User) HasTwoFactor() bool {
+	return u.TOTP != nil && u.TOTP.Enabled
+}
+
+// HasRole returns true if the user was given the role
+func (u *User) HasRole(role string) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
 		}
 	}
 
-	return fmt.Errorf("User %s cannot %s on resource %s", u.Name, action, resourceID)
+	return false
 }
 
-// Permission is a triplet that represents the right to do some action on a resource
+// impliedActions lists the actions granted along with an action
+var impliedActions = map[string][]string{
+	"write": {"read"},
+}
+
+// Permission is a triplet that represents the right to do some action on a resource.
+// Both fields are glob patterns, like list-*, and the action can list alternatives separated by |, like read|write
 type Permission struct {
 	ResourceID string `bson:"resource_id"`
 	Action     string `bson:"action"`
 }
+
+// Allows returns true if the permission grants the action on the resource, directly or through an implied action
+func (p *Permission) Allows(action string, resourceID string) bool {
+	if !matchPattern(p.ResourceID, resourceID) {
+		return false
+	}
+
+	for _, granted := range strings.Split(p.Action, "|") {
+		if matchPattern(granted, action) {
+			return true
+		}
+		for _, implied := range impliedActions[granted] {
+			if implied == action {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matchPattern matches a value against a glob pattern. A malformed pattern matches nothing
+func matchPattern(pattern string, value string) bool {
+	matched, err := path.Match(pattern, value)
+	return err == nil && matched
+}
@@ -2,9 +2,9 @@ package user
 
 import (
 	"context"
-	"fmt"
+	"sort"
 
-	"go.mongodb.org/mongo-driver/bson/primitive"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
 )
 
 // InMemoryRepository is a user repository based on mongodb
@@ -21,7 +21,7 @@ func NewInMemoryRepository() Repository {
 
 // FindByID returns the first user that matches the given id
 func (r *InMemoryRepository) FindByID(ctx context.Context, userID string) (*User, error) {
-	objectID, err := primitive.ObjectIDFromHex(userID)
+	objectID, err := common.ObjectIDFromHex(userID)
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +32,7 @@ func (r *InMemoryRepository) FindByID(ctx context.Context, userID string) (*User
 		}
 	}
 
-	return nil, fmt.Errorf("User not found for id %s", userID)
+	return nil, common.NewError(common.ErrNotFound, "User not found for id %s", userID)
 }
 
 // FindByName returns the first user that matches the given name
@@ -43,11 +43,43 @@ func (r *InMemoryRepository) FindByName(ctx context.Context, userName string) (*
 		}
 	}
 
-	return nil, fmt.Errorf("User not found with name %s", userName)
+	return nil, common.NewError(common.ErrNotFound, "User not found with name %s", userName)
+}
+
+// SearchUsers returns a page of the users selected by the query, sorted by name, and the total number of selected users
+func (r *InMemoryRepository) SearchUsers(ctx context.Context, query *UserQuery) ([]*User, int64, error) {
+	query.normalize()
+
+	selected := []*User{}
+	for _, user := range r.UserCollection {
+		if query.matches(user) {
+			selected = append(selected, user)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Name < selected[j].Name })
+
+	total := int64(len(selected))
+	start := query.skip()
+	if start > total {
+		start = total
+	}
+	end := start + query.PageSize
+	if end > total {
+		end = total
+	}
+
+	return selected[start:end], total, nil
 }
 
 // Store creates a new user and stores it
 func (r *InMemoryRepository) Store(ctx context.Context, name string, password string, permissions ...*Permission) (*User, error) {
+	// names are unique, like in the mongodb collection
+	for _, existing := range r.UserCollection {
+		if existing.Name == name {
+			return nil, common.NewError(common.ErrConflict, "user %v already exists", name)
+		}
+	}
+
 	user := NewUser(name, password, permissions...)
 
 	r.UserCollection = append(r.UserCollection, user)
@@ -57,7 +89,7 @@ func (r *InMemoryRepository) Store(ctx context.Context, name string, password st
 
 // UpdateName updates the name of the first user that matches the given id
 func (r *InMemoryRepository) UpdateName(ctx context.Context, userID string, newName string) (int64, error) {
-	objectID, err := primitive.ObjectIDFromHex(userID)
+	objectID, err := common.ObjectIDFromHex(userID)
 	if err != nil {
 		return -1, err
 	}
@@ -69,12 +101,12 @@ func (r *InMemoryRepository) UpdateName(ctx context.Context, userID string, newN
 		}
 	}
 
-	return -1, fmt.Errorf("User not found for id %s", userID)
+	return -1, common.NewError(common.ErrNotFound, "User not found for id %s", userID)
 }
 
-// UpdatePassword updates the password
+// UpdatePassword updates the password and lifts the password reset requirement
 func (r *InMemoryRepository) UpdatePassword(ctx context.Context, userID string, newPassword string) (int64, error) {
-	objectID, err := primitive.ObjectIDFromHex(userID)
+	objectID, err := common.ObjectIDFromHex(userID)
 	if err != nil {
 		return -1, err
 	}
@@ -82,17 +114,101 @@ func (r *InMemoryRepository) UpdatePassword(ctx context.Context, userID string,
 	for idx, user := range r.UserCollection {
 		if user.ID == objectID {
 			r.UserCollection[idx].Password = newPassword
+			r.UserCollection[idx].PasswordResetRequired = false
 			return 1, nil
 		}
 	}
 
-	return -1, fmt.Errorf("User not found for id %s", userID)
+	return -1, common.NewError(common.ErrNotFound, "User not found for id %s", userID)
+
+}
+
+// UpdateEmail updates the email address
+func (r *InMemoryRepository) UpdateEmail(ctx context.Context, userID string, newEmail string) (int64, error) {
+	user, err := r.FindByID(ctx, userID)
+	if err != nil {
+		return -1, err
+	}
+
+	user.Email = newEmail
+
+	return 1, nil
+}
+
+// UpdateProfile replaces the profile
+func (r *InMemoryRepository) UpdateProfile(ctx context.Context, userID string, profile *Profile) (int64, error) {
+	user, err := r.FindByID(ctx, userID)
+	if err != nil {
+		return -1, err
+	}
+
+	user.Profile = profile
+
+	return 1, nil
+}
+
+// UpdateAvatar sets the attachment id of the avatar
+func (r *InMemoryRepository) UpdateAvatar(ctx context.Context, userID string, avatarID string) (int64, error) {
+	user, err := r.FindByID(ctx, userID)
+	if err != nil {
+		return -1, err
+	}
+
+	user.Avatar = avatarID
+
+	return 1, nil
+}
+
+// UpdateTOTP replaces the two-factor authentication settings. A nil value removes them
+func (r *InMemoryRepository) UpdateTOTP(ctx context.Context, userID string, totp *TOTP) (int64, error) {
+	user, err := r.FindByID(ctx, userID)
+	if err != nil {
+		return -1, err
+	}
+
+	user.TOTP = totp
+
+	return 1, nil
+}
+
+// UseTOTPStep records the period of an accepted code, unless a code of this period or a later one was already used
+func (r *InMemoryRepository) UseTOTPStep(ctx context.Context, userID string, step int64) (int64, error) {
+	user, err := r.FindByID(ctx, userID)
+	if err != nil {
+		return -1, err
+	}
+	if user.TOTP == nil || user.TOTP.LastUsedStep >= step {
+		return 0, nil
+	}
+
+	user.TOTP.LastUsedStep = step
+
+	return 1, nil
+}
+
+// UseRecoveryCode removes the recovery code given by its hash, unless it was already used
+func (r *InMemoryRepository) UseRecoveryCode(ctx context.Context, userID string, recoveryCodeHash string) (int64, error) {
+	user, err := r.FindByID(ctx, userID)
+	if err != nil {
+		return -1, err
+	}
+	if user.TOTP == nil {
+		return 0, nil
+	}
+
+	for i, hash := range user.TOTP.RecoveryCodeHashes {
+		if hash == recoveryCodeHash {
+			user.TOTP.RecoveryCodeHashes = append(user.TOTP.RecoveryCodeHashes[:i:i], user.TOTP.RecoveryCodeHashes[i+1:]...)
+			return 1, nil
+		}
+	}
 
+	return 0, nil
 }
 
 // Delete deletes a user
 func (r *InMemoryRepository) Delete(ctx context.Context, userID string) (int64, error) {
-	objectID, err := primitive.ObjectIDFromHex(userID)
+	objectID, err := common.ObjectIDFromHex(userID)
 	if err != nil {
 		return -1, err
 	}
@@ -104,12 +220,12 @@ func (r *InMemoryRepository) Delete(ctx context.Context, userID string) (int64,
 		}
 	}
 
-	return -1, fmt.Errorf("User not found for id %s", userID)
+	return -1, common.NewError(common.ErrNotFound, "User not found for id %s", userID)
 }
 
 // AddPermissions adds the permissions to the user
 func (r *InMemoryRepository) AddPermissions(ctx context.Context, userID string, permissions ...*Permission) (int64, error) {
-	objectID, err := primitive.ObjectIDFromHex(userID)
+	objectID, err := common.ObjectIDFromHex(userID)
 	if err != nil {
 		return -1, err
 	}
@@ -121,12 +237,12 @@ func (r *InMemoryRepository) AddPermissions(ctx context.Context, userID string,
 		}
 	}
 
-	return -1, fmt.Errorf("User not found for id %s", userID)
+	return -1, common.NewError(common.ErrNotFound, "User not found for id %s", userID)
 }
 
 // RemovePermissions adds the permissions to the user
 func (r *InMemoryRepository) RemovePermissions(ctx context.Context, userID string, permissions ...*Permission) (int64, error) {
-	objectID, err := primitive.ObjectIDFromHex(userID)
+	objectID, err := common.ObjectIDFromHex(userID)
 	if err != nil {
 		return -1, err
 	}
@@ -146,5 +262,103 @@ func (r *InMemoryRepository) RemovePermissions(ctx context.Context, userID strin
 		}
 	}
 
-	return -1, fmt.Errorf("User not found for id %s", userID)
+	return -1, common.NewError(common.ErrNotFound, "User not found for id %s", userID)
+}
+
+// RemoveResourcePermissions takes from every user the permissions they hold on the resources
+func (r *InMemoryRepository) RemoveResourcePermissions(ctx context.Context, resourceIDs ...string) (int64, error) {
+	var modified int64
+	for _, user := range r.UserCollection {
+		kept := []*Permission{}
+		for _, permission := range user.Permissions {
+			if !containsString(resourceIDs, permission.ResourceID) {
+				kept = append(kept, permission)
+			}
+		}
+		if len(kept) != len(user.Permissions) {
+			user.Permissions = kept
+			modified++
+		}
+	}
+
+	return modified, nil
+}
+
+// AddRoles gives the roles to the user
+func (r *InMemoryRepository) AddRoles(ctx context.Context, userID string, roles ...string) (int64, error) {
+	user, err := r.FindByID(ctx, userID)
+	if err != nil {
+		return -1, err
+	}
+
+	for _, role := range roles {
+		if !user.HasRole(role) {
+			user.Roles = append(user.Roles, role)
+		}
+	}
+
+	return 1, nil
+}
+
+// RemoveRoles takes the roles from the user
+func (r *InMemoryRepository) RemoveRoles(ctx context.Context, userID string, roles ...string) (int64, error) {
+	user, err := r.FindByID(ctx, userID)
+	if err != nil {
+		return -1, err
+	}
+
+	kept := []string{}
+	for _, role := range user.Roles {
+		removed := false
+		for _, roleToRemove := range roles {
+			if role == roleToRemove {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			kept = append(kept, role)
+		}
+	}
+	user.Roles = kept
+
+	return 1, nil
+}
+
+// UpdateDisabled disables or enables the users
+func (r *InMemoryRepository) UpdateDisabled(ctx context.Context, disabled bool, userIDs ...string) (int64, error) {
+	return r.updateMany(ctx, userIDs, func(user *User) bool {
+		changed := user.Disabled != disabled
+		user.Disabled = disabled
+		return changed
+	})
+}
+
+// UpdatePasswordResetRequired sets whether the users have to reset their password before logging in with it
+func (r *InMemoryRepository) UpdatePasswordResetRequired(ctx context.Context, required bool, userIDs ...string) (int64, error) {
+	return r.updateMany(ctx, userIDs, func(user *User) bool {
+		changed := user.PasswordResetRequired != required
+		user.PasswordResetRequired = required
+		return changed
+	})
+}
+
+// updateMany applies the update to the users given by their ids and counts the ones it changed. Unknown ids are skipped
+func (r *InMemoryRepository) updateMany(ctx context.Context, userIDs []string, update func(user *User) bool) (int64, error) {
+	var modified int64
+	for _, userID := range userIDs {
+		if _, err := common.ObjectIDFromHex(userID); err != nil {
+			return -1, err
+		}
+
+		user, err := r.FindByID(ctx, userID)
+		if err != nil {
+			continue
+		}
+		if update(user) {
+			modified++
+		}
+	}
+
+	return modified, nil
 }
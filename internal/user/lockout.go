@@ -0,0 +1,126 @@
+package user
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// SecurityEventLockout is recorded when an account or an address gets locked out
+	SecurityEventLockout = "lockout"
+	// SecurityEventUnlock is recorded when an admin unlocks an account
+	SecurityEventUnlock = "unlock"
+)
+
+// LockedOutError is returned when logging in from a locked out account or address
+type LockedOutError struct {
+	Until time.Time
+}
+
+// Error returns the error message
+func (e *LockedOutError) Error() string {
+	return fmt.Sprintf("too many failed attempts, retry after %s", e.Until.Format(time.RFC3339))
+}
+
+// RetryAfter returns the time to wait before trying again
+func (e *LockedOutError) RetryAfter(now time.Time) time.Duration {
+	return e.Until.Sub(now)
+}
+
+// LoginAttempts counts the failed logins of an account or an address
+type LoginAttempts struct {
+	Key           string    `bson:"key"`
+	Failures      int64     `bson:"failures"`
+	LastFailureAt time.Time `bson:"last_failure_at"`
+	LockedUntil   time.Time `bson:"locked_until"`
+	// ExpiresAt is when the failures are forgotten
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// IsLocked returns true if the key is locked out at the given time
+func (a *LoginAttempts) IsLocked(now time.Time) bool {
+	return now.Before(a.LockedUntil)
+}
+
+// LockoutPolicy configures when accounts and addresses get locked out.
+// Once the threshold is reached, every failure locks the key for BaseDelay, doubled for each failure above the threshold, up to MaxDelay
+type LockoutPolicy struct {
+	AccountThreshold int64
+	IPThreshold      int64
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	// ResetAfter is the time after the last failure when the failures are forgotten
+	ResetAfter time.Duration
+}
+
+// delay returns how long a key is locked out after the given number of failures
+func (p *LockoutPolicy) delay(failures int64, threshold int64) time.Duration {
+	if threshold <= 0 || failures < threshold {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := threshold; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	return delay
+}
+
+// ResetPolicy limits the password reset requests counted within Window. Above AccountThreshold, the requests for a user are silently dropped,
+// and above IPThreshold, the requests from an address are refused. A zero threshold does not limit
+type ResetPolicy struct {
+	AccountThreshold int64
+	IPThreshold      int64
+	Window           time.Duration
+}
+
+// SecurityEvent records something that happened to the security of an account
+type SecurityEvent struct {
+	common.BaseModel `bson:",inline"`
+	Type             string `bson:"type" json:"type"`
+	Subject          string `bson:"subject" json:"subject"`
+	IP               string `bson:"ip,omitempty" json:"ip,omitempty"`
+	Details          string `bson:"details,omitempty" json:"details,omitempty"`
+}
+
+// NewSecurityEvent is a SecurityEvent constructor
+func NewSecurityEvent(eventType string, subject string, ip string, details string) *SecurityEvent {
+	return &SecurityEvent{
+		BaseModel: common.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+		Type:    eventType,
+		Subject: subject,
+		IP:      ip,
+		Details: details,
+	}
+}
+
+// accountKey is the key under which the failures of an account are counted
+func accountKey(userName string) string {
+	return "account:" + userName
+}
+
+// ipKey is the key under which the failures of an address are counted
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// resetAccountKey is the key under which the password reset requests for an account are counted
+func resetAccountKey(userName string) string {
+	return "reset-account:" + userName
+}
+
+// resetIPKey is the key under which the password reset requests from an address are counted
+func resetIPKey(ip string) string {
+	return "reset-ip:" + ip
+}
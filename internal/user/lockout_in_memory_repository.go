@@ -0,0 +1,95 @@
+package user
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// InMemoryLoginAttemptsRepository is an in-memory failed logins repository
+type InMemoryLoginAttemptsRepository struct {
+	mu       sync.Mutex
+	attempts map[string]*LoginAttempts
+}
+
+// NewInMemoryLoginAttemptsRepository inits a new in-memory failed logins repository
+func NewInMemoryLoginAttemptsRepository() LoginAttemptsRepository {
+	return &InMemoryLoginAttemptsRepository{
+		attempts: make(map[string]*LoginAttempts),
+	}
+}
+
+// FindLoginAttempts retrieves the failed logins of the keys. Expired ones are forgotten
+func (r *InMemoryLoginAttemptsRepository) FindLoginAttempts(ctx context.Context, keys ...string) ([]*LoginAttempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	found := []*LoginAttempts{}
+	for _, key := range keys {
+		if attempts := r.get(key, time.Now()); attempts != nil {
+			copied := *attempts
+			found = append(found, &copied)
+		}
+	}
+
+	return found, nil
+}
+
+// RecordLoginFailure counts a failed login of the key
+func (r *InMemoryLoginAttemptsRepository) RecordLoginFailure(ctx context.Context, key string, now time.Time, expiresAt time.Time) (*LoginAttempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts := r.get(key, now)
+	if attempts == nil {
+		attempts = &LoginAttempts{Key: key}
+		r.attempts[key] = attempts
+	}
+	attempts.Failures++
+	attempts.LastFailureAt = now
+	attempts.ExpiresAt = expiresAt
+
+	copied := *attempts
+	return &copied, nil
+}
+
+// LockLogin locks the key out until the given time
+func (r *InMemoryLoginAttemptsRepository) LockLogin(ctx context.Context, key string, until time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts, exists := r.attempts[key]
+	if !exists || !until.After(attempts.LockedUntil) {
+		return 0, nil
+	}
+	attempts.LockedUntil = until
+
+	return 1, nil
+}
+
+// ResetLoginAttempts forgets the failed logins of the key
+func (r *InMemoryLoginAttemptsRepository) ResetLoginAttempts(ctx context.Context, key string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.attempts[key]; !exists {
+		return 0, nil
+	}
+	delete(r.attempts, key)
+
+	return 1, nil
+}
+
+// get returns the attempts of the key, dropping them if they expired
+func (r *InMemoryLoginAttemptsRepository) get(key string, now time.Time) *LoginAttempts {
+	attempts, exists := r.attempts[key]
+	if !exists {
+		return nil
+	}
+	if !now.Before(attempts.ExpiresAt) {
+		delete(r.attempts, key)
+		return nil
+	}
+
+	return attempts
+}
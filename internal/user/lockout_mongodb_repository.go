@@ -0,0 +1,84 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDBLoginAttemptsRepository is a failed logins repository based on mongodb
+type MongoDBLoginAttemptsRepository struct {
+	LoginAttemptsCollection *mongo.Collection
+}
+
+// NewMongoDBLoginAttemptsRepository inits a new mongodb failed logins repository
+func NewMongoDBLoginAttemptsRepository(collection *mongo.Collection) LoginAttemptsRepository {
+	return &MongoDBLoginAttemptsRepository{
+		LoginAttemptsCollection: collection,
+	}
+}
+
+// FindLoginAttempts retrieves the failed logins of the keys
+func (r *MongoDBLoginAttemptsRepository) FindLoginAttempts(ctx context.Context, keys ...string) ([]*LoginAttempts, error) {
+	attempts := []*LoginAttempts{}
+	cursor, err := r.LoginAttemptsCollection.Find(ctx, bson.M{"key": bson.M{"$in": keys}})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(ctx, &attempts); err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
+
+// RecordLoginFailure atomically counts a failed login of the key
+func (r *MongoDBLoginAttemptsRepository) RecordLoginFailure(ctx context.Context, key string, now time.Time, expiresAt time.Time) (*LoginAttempts, error) {
+	var attempts LoginAttempts
+	err := r.LoginAttemptsCollection.FindOneAndUpdate(
+		ctx,
+		bson.M{"key": key},
+		bson.D{
+			{Key: "$inc", Value: bson.D{{Key: "failures", Value: 1}}},
+			{Key: "$set", Value: bson.D{
+				{Key: "last_failure_at", Value: now},
+				{Key: "expires_at", Value: expiresAt},
+			}},
+			{Key: "$setOnInsert", Value: bson.D{{Key: "locked_until", Value: time.Time{}}}},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&attempts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &attempts, nil
+}
+
+// LockLogin locks the key out until the given time
+func (r *MongoDBLoginAttemptsRepository) LockLogin(ctx context.Context, key string, until time.Time) (int64, error) {
+	result, err := r.LoginAttemptsCollection.UpdateOne(
+		ctx,
+		bson.M{"key": key},
+		bson.D{{Key: "$max", Value: bson.D{{Key: "locked_until", Value: until}}}},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// ResetLoginAttempts forgets the failed logins of the key
+func (r *MongoDBLoginAttemptsRepository) ResetLoginAttempts(ctx context.Context, key string) (int64, error) {
+	result, err := r.LoginAttemptsCollection.DeleteOne(ctx, bson.M{"key": key})
+	if err != nil {
+		return -1, err
+	}
+
+	return result.DeletedCount, nil
+}
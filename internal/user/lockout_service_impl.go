@@ -0,0 +1,113 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// LockoutServiceImpl is the concrete implementation of the lockout service interface
+type LockoutServiceImpl struct {
+	attempts LoginAttemptsRepository
+	events   SecurityEventRepository
+	policy   *LockoutPolicy
+}
+
+// NewLockoutService inits a new lockout service applying the policy
+func NewLockoutService(attempts LoginAttemptsRepository, events SecurityEventRepository, policy *LockoutPolicy) LockoutService {
+	return &LockoutServiceImpl{
+		attempts: attempts,
+		events:   events,
+		policy:   policy,
+	}
+}
+
+// CheckLogin returns a LockedOutError if the account or the address is locked out. An empty user name or address is not checked
+func (s *LockoutServiceImpl) CheckLogin(ctx context.Context, userName string, ip string) error {
+	attempts, err := s.attempts.FindLoginAttempts(ctx, loginKeys(userName, ip)...)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var lockedOut *LockedOutError
+	for _, a := range attempts {
+		if a.IsLocked(now) && (lockedOut == nil || a.LockedUntil.After(lockedOut.Until)) {
+			lockedOut = &LockedOutError{Until: a.LockedUntil}
+		}
+	}
+	if lockedOut != nil {
+		return lockedOut
+	}
+
+	return nil
+}
+
+// RecordLoginFailure counts a failure for the account and the address and locks them out once their threshold is reached
+func (s *LockoutServiceImpl) RecordLoginFailure(ctx context.Context, userName string, ip string) error {
+	now := time.Now()
+	thresholds := map[string]int64{}
+	if userName != "" {
+		thresholds[accountKey(userName)] = s.policy.AccountThreshold
+	}
+	if ip != "" {
+		thresholds[ipKey(ip)] = s.policy.IPThreshold
+	}
+
+	for key, threshold := range thresholds {
+		attempts, err := s.attempts.RecordLoginFailure(ctx, key, now, now.Add(s.policy.ResetAfter))
+		if err != nil {
+			return err
+		}
+
+		delay := s.policy.delay(attempts.Failures, threshold)
+		if delay == 0 {
+			continue
+		}
+
+		if _, err := s.attempts.LockLogin(ctx, key, now.Add(delay)); err != nil {
+			return err
+		}
+
+		details := fmt.Sprintf("%d failed attempts, locked out for %v", attempts.Failures, delay)
+		if err := s.events.RecordSecurityEvent(ctx, NewSecurityEvent(SecurityEventLockout, key, ip, details)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RecordLoginSuccess forgets the failures of the account. The failures of the address are kept
+// so that an attacker owning an account cannot use it to reset the counter
+func (s *LockoutServiceImpl) RecordLoginSuccess(ctx context.Context, userName string, ip string) error {
+	_, err := s.attempts.ResetLoginAttempts(ctx, accountKey(userName))
+	return err
+}
+
+// Unlock forgets the failures of the account and records who unlocked it
+func (s *LockoutServiceImpl) Unlock(ctx context.Context, userName string, unlockedBy string) error {
+	if _, err := s.attempts.ResetLoginAttempts(ctx, accountKey(userName)); err != nil {
+		return err
+	}
+
+	return s.events.RecordSecurityEvent(ctx, NewSecurityEvent(SecurityEventUnlock, accountKey(userName), "", "unlocked by "+unlockedBy))
+}
+
+// FindSecurityEvents directly calls the repository
+func (s *LockoutServiceImpl) FindSecurityEvents(ctx context.Context, limit int64) ([]*SecurityEvent, error) {
+	return s.events.FindSecurityEvents(ctx, limit)
+}
+
+// loginKeys returns the keys under which the failures of the account and the address are counted
+func loginKeys(userName string, ip string) []string {
+	keys := []string{}
+	if userName != "" {
+		keys = append(keys, accountKey(userName))
+	}
+	if ip != "" {
+		keys = append(keys, ipKey(ip))
+	}
+
+	return keys
+}
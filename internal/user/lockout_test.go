@@ -0,0 +1,61 @@
+package user_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockout(t *testing.T) {
+	ctx := context.Background()
+	srv := user.NewLockoutService(user.NewInMemoryLoginAttemptsRepository(), user.NewInMemorySecurityEventRepository(), &user.LockoutPolicy{
+		AccountThreshold: 3,
+		IPThreshold:      5,
+		BaseDelay:        time.Minute,
+		MaxDelay:         3 * time.Minute,
+		ResetAfter:       time.Hour,
+	})
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, srv.CheckLogin(ctx, "alice", "10.0.0.1"))
+		assert.NoError(t, srv.RecordLoginFailure(ctx, "alice", "10.0.0.1"))
+	}
+	assert.NoError(t, srv.CheckLogin(ctx, "alice", "10.0.0.1"))
+
+	// the third failure locks the account out, from any address
+	assert.NoError(t, srv.RecordLoginFailure(ctx, "alice", "10.0.0.1"))
+	var lockedOut *user.LockedOutError
+	assert.True(t, errors.As(srv.CheckLogin(ctx, "alice", "10.0.0.2"), &lockedOut))
+	assert.InDelta(t, time.Minute.Seconds(), lockedOut.RetryAfter(time.Now()).Seconds(), 1)
+	assert.NoError(t, srv.CheckLogin(ctx, "bob", "10.0.0.2"))
+
+	// the delay doubles with each failure, up to the maximum
+	assert.NoError(t, srv.RecordLoginFailure(ctx, "alice", "10.0.0.2"))
+	assert.True(t, errors.As(srv.CheckLogin(ctx, "alice", ""), &lockedOut))
+	assert.InDelta(t, (2 * time.Minute).Seconds(), lockedOut.RetryAfter(time.Now()).Seconds(), 1)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, srv.RecordLoginFailure(ctx, "alice", "10.0.0.2"))
+	}
+	assert.True(t, errors.As(srv.CheckLogin(ctx, "alice", ""), &lockedOut))
+	assert.InDelta(t, (3 * time.Minute).Seconds(), lockedOut.RetryAfter(time.Now()).Seconds(), 1)
+
+	// the second address reached its own threshold with a failure on another account
+	assert.NoError(t, srv.RecordLoginFailure(ctx, "bob", "10.0.0.2"))
+	assert.Error(t, srv.CheckLogin(ctx, "carol", "10.0.0.2"))
+	assert.NoError(t, srv.CheckLogin(ctx, "carol", "10.0.0.3"))
+
+	// an admin unlocks the account
+	assert.NoError(t, srv.Unlock(ctx, "alice", "admin"))
+	assert.NoError(t, srv.CheckLogin(ctx, "alice", "10.0.0.1"))
+
+	events, err := srv.FindSecurityEvents(ctx, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, user.SecurityEventUnlock, events[0].Type)
+	assert.Equal(t, "unlocked by admin", events[0].Details)
+	assert.Equal(t, user.SecurityEventLockout, events[len(events)-1].Type)
+	assert.Equal(t, "account:alice", events[len(events)-1].Subject)
+}
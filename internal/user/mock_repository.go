@@ -41,6 +41,34 @@ func (_m *MockRepository) AddPermissions(ctx context.Context, userID string, per
 	return r0, r1
 }
 
+// AddRoles provides a mock function with given fields: ctx, userID, roles
+func (_m *MockRepository) AddRoles(ctx context.Context, userID string, roles ...string) (int64, error) {
+	_va := make([]interface{}, len(roles))
+	for _i := range roles {
+		_va[_i] = roles[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, userID)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) int64); ok {
+		r0 = rf(ctx, userID, roles...)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, ...string) error); ok {
+		r1 = rf(ctx, userID, roles...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Delete provides a mock function with given fields: ctx, userID
 func (_m *MockRepository) Delete(ctx context.Context, userID string) (int64, error) {
 	ret := _m.Called(ctx, userID)
@@ -136,6 +164,92 @@ func (_m *MockRepository) RemovePermissions(ctx context.Context, userID string,
 	return r0, r1
 }
 
+// RemoveResourcePermissions provides a mock function with given fields: ctx, resourceIDs
+func (_m *MockRepository) RemoveResourcePermissions(ctx context.Context, resourceIDs ...string) (int64, error) {
+	_va := make([]interface{}, len(resourceIDs))
+	for _i := range resourceIDs {
+		_va[_i] = resourceIDs[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, ...string) int64); ok {
+		r0 = rf(ctx, resourceIDs...)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, ...string) error); ok {
+		r1 = rf(ctx, resourceIDs...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveRoles provides a mock function with given fields: ctx, userID, roles
+func (_m *MockRepository) RemoveRoles(ctx context.Context, userID string, roles ...string) (int64, error) {
+	_va := make([]interface{}, len(roles))
+	for _i := range roles {
+		_va[_i] = roles[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, userID)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, ...string) int64); ok {
+		r0 = rf(ctx, userID, roles...)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, ...string) error); ok {
+		r1 = rf(ctx, userID, roles...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchUsers provides a mock function with given fields: ctx, query
+func (_m *MockRepository) SearchUsers(ctx context.Context, query *UserQuery) ([]*User, int64, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 []*User
+	if rf, ok := ret.Get(0).(func(context.Context, *UserQuery) []*User); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*User)
+		}
+	}
+
+	var r1 int64
+	if rf, ok := ret.Get(1).(func(context.Context, *UserQuery) int64); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, *UserQuery) error); ok {
+		r2 = rf(ctx, query)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // Store provides a mock function with given fields: ctx, name, password, permissions
 func (_m *MockRepository) Store(ctx context.Context, name string, password string, permissions ...*Permission) (*User, error) {
 	_va := make([]interface{}, len(permissions))
@@ -166,6 +280,76 @@ func (_m *MockRepository) Store(ctx context.Context, name string, password strin
 	return r0, r1
 }
 
+// UpdateAvatar provides a mock function with given fields: ctx, userID, avatarID
+func (_m *MockRepository) UpdateAvatar(ctx context.Context, userID string, avatarID string) (int64, error) {
+	ret := _m.Called(ctx, userID, avatarID)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int64); ok {
+		r0 = rf(ctx, userID, avatarID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, avatarID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateDisabled provides a mock function with given fields: ctx, disabled, userIDs
+func (_m *MockRepository) UpdateDisabled(ctx context.Context, disabled bool, userIDs ...string) (int64, error) {
+	_va := make([]interface{}, len(userIDs))
+	for _i := range userIDs {
+		_va[_i] = userIDs[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, disabled)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, bool, ...string) int64); ok {
+		r0 = rf(ctx, disabled, userIDs...)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, bool, ...string) error); ok {
+		r1 = rf(ctx, disabled, userIDs...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateEmail provides a mock function with given fields: ctx, userID, newEmail
+func (_m *MockRepository) UpdateEmail(ctx context.Context, userID string, newEmail string) (int64, error) {
+	ret := _m.Called(ctx, userID, newEmail)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int64); ok {
+		r0 = rf(ctx, userID, newEmail)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, newEmail)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateName provides a mock function with given fields: ctx, userID, newName
 func (_m *MockRepository) UpdateName(ctx context.Context, userID string, newName string) (int64, error) {
 	ret := _m.Called(ctx, userID, newName)
@@ -207,3 +391,115 @@ func (_m *MockRepository) UpdatePassword(ctx context.Context, userID string, new
 
 	return r0, r1
 }
+
+// UpdatePasswordResetRequired provides a mock function with given fields: ctx, required, userIDs
+func (_m *MockRepository) UpdatePasswordResetRequired(ctx context.Context, required bool, userIDs ...string) (int64, error) {
+	_va := make([]interface{}, len(userIDs))
+	for _i := range userIDs {
+		_va[_i] = userIDs[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, required)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, bool, ...string) int64); ok {
+		r0 = rf(ctx, required, userIDs...)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, bool, ...string) error); ok {
+		r1 = rf(ctx, required, userIDs...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateProfile provides a mock function with given fields: ctx, userID, profile
+func (_m *MockRepository) UpdateProfile(ctx context.Context, userID string, profile *Profile) (int64, error) {
+	ret := _m.Called(ctx, userID, profile)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, *Profile) int64); ok {
+		r0 = rf(ctx, userID, profile)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *Profile) error); ok {
+		r1 = rf(ctx, userID, profile)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateTOTP provides a mock function with given fields: ctx, userID, totp
+func (_m *MockRepository) UpdateTOTP(ctx context.Context, userID string, totp *TOTP) (int64, error) {
+	ret := _m.Called(ctx, userID, totp)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, *TOTP) int64); ok {
+		r0 = rf(ctx, userID, totp)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *TOTP) error); ok {
+		r1 = rf(ctx, userID, totp)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UseRecoveryCode provides a mock function with given fields: ctx, userID, recoveryCodeHash
+func (_m *MockRepository) UseRecoveryCode(ctx context.Context, userID string, recoveryCodeHash string) (int64, error) {
+	ret := _m.Called(ctx, userID, recoveryCodeHash)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int64); ok {
+		r0 = rf(ctx, userID, recoveryCodeHash)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, recoveryCodeHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UseTOTPStep provides a mock function with given fields: ctx, userID, step
+func (_m *MockRepository) UseTOTPStep(ctx context.Context, userID string, step int64) (int64, error) {
+	ret := _m.Called(ctx, userID, step)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) int64); ok {
+		r0 = rf(ctx, userID, step)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = rf(ctx, userID, step)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
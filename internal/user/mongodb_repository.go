@@ -2,11 +2,14 @@ package user
 
 import (
 	"context"
+	"regexp"
 	"time"
 
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // MongoDBRepository is a user repository based on mongodb
@@ -23,14 +26,14 @@ func NewMongoDBRepository(collection *mongo.Collection) Repository {
 
 // FindByID returns the first user that matches the given id
 func (r *MongoDBRepository) FindByID(ctx context.Context, userID string) (*User, error) {
-	objectID, err := primitive.ObjectIDFromHex(userID)
+	objectID, err := common.ObjectIDFromHex(userID)
 	if err != nil {
 		return nil, err
 	}
 
 	var user User
 	if err := r.UserCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&user); err != nil {
-		return nil, err
+		return nil, common.MongoError(err, "user %v", userID)
 	}
 
 	return &user, nil
@@ -40,18 +43,61 @@ func (r *MongoDBRepository) FindByID(ctx context.Context, userID string) (*User,
 func (r *MongoDBRepository) FindByName(ctx context.Context, userName string) (*User, error) {
 	var user User
 	if err := r.UserCollection.FindOne(ctx, bson.M{"name": userName}).Decode(&user); err != nil {
-		return nil, err
+		return nil, common.MongoError(err, "user %v", userName)
 	}
 
 	return &user, nil
 }
 
+// SearchUsers returns a page of the users selected by the query, sorted by name, and the total number of selected users
+func (r *MongoDBRepository) SearchUsers(ctx context.Context, query *UserQuery) ([]*User, int64, error) {
+	query.normalize()
+
+	filter := bson.M{}
+	if query.NamePrefix != "" {
+		filter["name"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(query.NamePrefix)}
+	}
+	if query.Role != "" {
+		filter["roles"] = query.Role
+	}
+	if query.Disabled != nil {
+		filter["disabled"] = *query.Disabled
+	}
+	permission := bson.M{}
+	if query.PermissionAction != "" {
+		permission["action"] = query.PermissionAction
+	}
+	if query.PermissionResource != "" {
+		permission["resource_id"] = query.PermissionResource
+	}
+	if len(permission) > 0 {
+		filter["permissions"] = bson.M{"$elemMatch": permission}
+	}
+
+	total, err := r.UserCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	users := []*User{}
+	cursor, err := r.UserCollection.Find(ctx, filter, options.Find().SetSort(bson.M{"name": 1}).SetSkip(query.skip()).SetLimit(query.PageSize))
+	if err != nil {
+		return nil, -1, err
+	}
+
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, -1, err
+	}
+
+	return users, total, nil
+}
+
 // Store creates a new user and stores it
 func (r *MongoDBRepository) Store(ctx context.Context, name string, password string, permissions ...*Permission) (*User, error) {
 	user := NewUser(name, password, permissions...)
 
 	if _, err := r.UserCollection.InsertOne(ctx, *user); err != nil {
-		return nil, err
+		return nil, common.MongoError(err, "user %v", name)
 	}
 
 	return user, nil
@@ -60,7 +106,7 @@ func (r *MongoDBRepository) Store(ctx context.Context, name string, password str
 
 // UpdateName updates the name of the first user that matches the given id
 func (r *MongoDBRepository) UpdateName(ctx context.Context, userID string, newName string) (int64, error) {
-	objectID, err := primitive.ObjectIDFromHex(userID)
+	objectID, err := common.ObjectIDFromHex(userID)
 	if err != nil {
 		return -1, err
 	}
@@ -81,9 +127,9 @@ func (r *MongoDBRepository) UpdateName(ctx context.Context, userID string, newNa
 	return result.ModifiedCount, nil
 }
 
-// UpdatePassword updates the password
+// UpdatePassword updates the password and lifts the password reset requirement
 func (r *MongoDBRepository) UpdatePassword(ctx context.Context, userID string, newPassword string) (int64, error) {
-	objectID, err := primitive.ObjectIDFromHex(userID)
+	objectID, err := common.ObjectIDFromHex(userID)
 	if err != nil {
 		return -1, err
 	}
@@ -92,8 +138,11 @@ func (r *MongoDBRepository) UpdatePassword(ctx context.Context, userID string, n
 		ctx,
 		bson.M{"_id": objectID},
 		bson.D{
-			{"$set", bson.D{{"name", newPassword}}},
-			{"$set", bson.D{{"updated_at", time.Now()}}},
+			{Key: "$set", Value: bson.D{
+				{Key: "password", Value: newPassword},
+				{Key: "password_reset_required", Value: false},
+				{Key: "updated_at", Value: time.Now()},
+			}},
 		},
 	)
 
@@ -105,9 +154,155 @@ func (r *MongoDBRepository) UpdatePassword(ctx context.Context, userID string, n
 
 }
 
+// UpdateEmail updates the email address
+func (r *MongoDBRepository) UpdateEmail(ctx context.Context, userID string, newEmail string) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(userID)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.UserCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.D{
+			{Key: "$set", Value: bson.D{
+				{Key: "email", Value: newEmail},
+				{Key: "updated_at", Value: time.Now()},
+			}},
+		},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// UpdateProfile replaces the profile
+func (r *MongoDBRepository) UpdateProfile(ctx context.Context, userID string, profile *Profile) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(userID)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.UserCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.D{
+			{Key: "$set", Value: bson.D{
+				{Key: "profile", Value: profile},
+				{Key: "updated_at", Value: time.Now()},
+			}},
+		},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// UpdateAvatar sets the attachment id of the avatar
+func (r *MongoDBRepository) UpdateAvatar(ctx context.Context, userID string, avatarID string) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(userID)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.UserCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.D{
+			{Key: "$set", Value: bson.D{
+				{Key: "avatar", Value: avatarID},
+				{Key: "updated_at", Value: time.Now()},
+			}},
+		},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// UpdateTOTP replaces the two-factor authentication settings. A nil value removes them
+func (r *MongoDBRepository) UpdateTOTP(ctx context.Context, userID string, totp *TOTP) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(userID)
+	if err != nil {
+		return -1, err
+	}
+
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "totp", Value: totp},
+			{Key: "updated_at", Value: time.Now()},
+		}},
+	}
+	if totp == nil {
+		update = bson.D{
+			{Key: "$unset", Value: bson.D{{Key: "totp", Value: ""}}},
+			{Key: "$set", Value: bson.D{{Key: "updated_at", Value: time.Now()}}},
+		}
+	}
+
+	result, err := r.UserCollection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// UseTOTPStep records the period of an accepted code, unless a code of this period or a later one was already used
+func (r *MongoDBRepository) UseTOTPStep(ctx context.Context, userID string, step int64) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(userID)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.UserCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID, "totp.last_used_step": bson.M{"$lt": step}},
+		bson.D{
+			{Key: "$set", Value: bson.D{
+				{Key: "totp.last_used_step", Value: step},
+				{Key: "updated_at", Value: time.Now()},
+			}},
+		},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// UseRecoveryCode removes the recovery code given by its hash, unless it was already used
+func (r *MongoDBRepository) UseRecoveryCode(ctx context.Context, userID string, recoveryCodeHash string) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(userID)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.UserCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID, "totp.recovery_code_hashes": recoveryCodeHash},
+		bson.D{
+			{Key: "$pull", Value: bson.D{{Key: "totp.recovery_code_hashes", Value: recoveryCodeHash}}},
+			{Key: "$set", Value: bson.D{{Key: "updated_at", Value: time.Now()}}},
+		},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
 // Delete deletes a user
 func (r *MongoDBRepository) Delete(ctx context.Context, userID string) (int64, error) {
-	objectID, err := primitive.ObjectIDFromHex(userID)
+	objectID, err := common.ObjectIDFromHex(userID)
 	if err != nil {
 		return -1, err
 	}
@@ -126,7 +321,7 @@ func (r *MongoDBRepository) Delete(ctx context.Context, userID string) (int64, e
 
 // AddPermissions adds the permissions to the user
 func (r *MongoDBRepository) AddPermissions(ctx context.Context, userID string, permissions ...*Permission) (int64, error) {
-	objectID, err := primitive.ObjectIDFromHex(userID)
+	objectID, err := common.ObjectIDFromHex(userID)
 	if err != nil {
 		return -1, err
 	}
@@ -149,7 +344,7 @@ func (r *MongoDBRepository) AddPermissions(ctx context.Context, userID string, p
 
 // RemovePermissions adds the permissions to the user
 func (r *MongoDBRepository) RemovePermissions(ctx context.Context, userID string, permissions ...*Permission) (int64, error) {
-	objectID, err := primitive.ObjectIDFromHex(userID)
+	objectID, err := common.ObjectIDFromHex(userID)
 	if err != nil {
 		return -1, err
 	}
@@ -173,3 +368,99 @@ func (r *MongoDBRepository) RemovePermissions(ctx context.Context, userID string
 
 	return result.ModifiedCount, nil
 }
+
+// RemoveResourcePermissions takes from every user the permissions they hold on the resources
+func (r *MongoDBRepository) RemoveResourcePermissions(ctx context.Context, resourceIDs ...string) (int64, error) {
+	result, err := r.UserCollection.UpdateMany(
+		ctx,
+		bson.M{"permissions.resource_id": bson.M{"$in": resourceIDs}},
+		bson.D{
+			{Key: "$pull", Value: bson.D{{Key: "permissions", Value: bson.D{{Key: "resource_id", Value: bson.D{{Key: "$in", Value: resourceIDs}}}}}}},
+			{Key: "$set", Value: bson.D{{Key: "updated_at", Value: time.Now()}}},
+		},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// AddRoles gives the roles to the user
+func (r *MongoDBRepository) AddRoles(ctx context.Context, userID string, roles ...string) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(userID)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.UserCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.D{
+			{Key: "$addToSet", Value: bson.D{{Key: "roles", Value: bson.D{{Key: "$each", Value: roles}}}}},
+			{Key: "$set", Value: bson.D{{Key: "updated_at", Value: time.Now()}}},
+		},
+	)
+
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// RemoveRoles takes the roles from the user
+func (r *MongoDBRepository) RemoveRoles(ctx context.Context, userID string, roles ...string) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(userID)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.UserCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID},
+		bson.D{
+			{Key: "$pull", Value: bson.D{{Key: "roles", Value: bson.D{{Key: "$in", Value: roles}}}}},
+			{Key: "$set", Value: bson.D{{Key: "updated_at", Value: time.Now()}}},
+		},
+	)
+
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// UpdateDisabled disables or enables the users
+func (r *MongoDBRepository) UpdateDisabled(ctx context.Context, disabled bool, userIDs ...string) (int64, error) {
+	return r.updateMany(ctx, userIDs, bson.D{{Key: "disabled", Value: disabled}})
+}
+
+// UpdatePasswordResetRequired sets whether the users have to reset their password before logging in with it
+func (r *MongoDBRepository) UpdatePasswordResetRequired(ctx context.Context, required bool, userIDs ...string) (int64, error) {
+	return r.updateMany(ctx, userIDs, bson.D{{Key: "password_reset_required", Value: required}})
+}
+
+// updateMany sets the fields of the users given by their ids
+func (r *MongoDBRepository) updateMany(ctx context.Context, userIDs []string, fields bson.D) (int64, error) {
+	objectIDs := make([]primitive.ObjectID, 0, len(userIDs))
+	for _, userID := range userIDs {
+		objectID, err := common.ObjectIDFromHex(userID)
+		if err != nil {
+			return -1, err
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+
+	result, err := r.UserCollection.UpdateMany(
+		ctx,
+		bson.M{"_id": bson.M{"$in": objectIDs}},
+		bson.D{{Key: "$set", Value: append(fields, bson.E{Key: "updated_at", Value: time.Now()})}},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,81 @@
+package user_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NicolasDutronc/autokey"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/NicolasDutronc/shoppinglist-be/pkg/keyring"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testHasher keeps the cost of argon2id low so that the tests stay fast
+var testHasher = user.NewArgon2idHasher(&user.Argon2idParams{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+})
+
+func TestArgon2idHasher(t *testing.T) {
+	hash, err := testHasher.Hash("password")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+	assert.NoError(t, testHasher.Verify(hash, "password"))
+	assert.ErrorIs(t, testHasher.Verify(hash, "wrong"), user.ErrPasswordMismatch)
+	assert.False(t, testHasher.NeedsRehash(hash))
+
+	// the salt is random
+	other, err := testHasher.Hash("password")
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, other)
+
+	// hashes made with other parameters are still verified but have to be upgraded
+	stronger := user.NewArgon2idHasher(&user.Argon2idParams{Memory: 2048, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	assert.NoError(t, stronger.Verify(hash, "password"))
+	assert.True(t, stronger.NeedsRehash(hash))
+
+	// bcrypt hashes made before argon2id are still verified but have to be upgraded
+	legacy, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	assert.NoError(t, testHasher.Verify(string(legacy), "password"))
+	assert.ErrorIs(t, testHasher.Verify(string(legacy), "wrong"), user.ErrPasswordMismatch)
+	assert.True(t, testHasher.NeedsRehash(string(legacy)))
+
+	assert.ErrorIs(t, testHasher.Verify("plain", "plain"), user.ErrUnknownHash)
+	assert.ErrorIs(t, testHasher.Verify("$argon2id$v=19$m=1024,t=1,p=1$%%%$%%%", "password"), user.ErrUnknownHash)
+}
+
+func TestLoginRehashesOutdatedPasswords(t *testing.T) {
+	ctx := context.Background()
+	consumer := &autokey.MockConsumer{}
+	consumer.On("Get").Return("superSecretKey", nil)
+	repo := user.NewInMemoryRepository()
+	srv := user.NewService(repo, user.NewInMemorySessionRepository(), user.NewInMemoryRoleRepository(), user.NewInMemoryAccessTokenRepository(), testHasher, keyring.NewSymmetric(consumer), time.Minute, time.Hour)
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	alice, err := repo.Store(ctx, "alice", string(legacy))
+	assert.NoError(t, err)
+
+	// a wrong password leaves the hash untouched
+	_, _, _, err = srv.Login(ctx, "alice", "wrong")
+	assert.ErrorIs(t, err, user.ErrPasswordMismatch)
+	stored, err := repo.FindByID(ctx, alice.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, string(legacy), stored.Password)
+
+	_, tokens, _, err := srv.Login(ctx, "alice", "password")
+	assert.NoError(t, err)
+	assert.NotNil(t, tokens)
+	stored, err = repo.FindByID(ctx, alice.ID.Hex())
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(stored.Password, "$argon2id$"))
+	assert.False(t, testHasher.NeedsRehash(stored.Password))
+	assert.NoError(t, testHasher.Verify(stored.Password, "password"))
+}
@@ -0,0 +1,107 @@
+package user
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"golang.org/x/text/language"
+)
+
+// maxDisplayNameLength is the maximum number of characters of a display name
+const maxDisplayNameLength = 64
+
+// ErrInvalidProfile is returned when updating a profile with invalid preferences
+var ErrInvalidProfile = common.NewError(common.ErrValidation, "invalid profile")
+
+// Units is the unit system quantities are displayed in
+type Units string
+
+const (
+	// UnitsMetric displays quantities in grams, liters and meters. It is the default
+	UnitsMetric Units = "metric"
+	// UnitsImperial displays quantities in pounds, ounces and gallons
+	UnitsImperial Units = "imperial"
+)
+
+// NotificationPreferences tell which notifications the user wants to receive
+type NotificationPreferences struct {
+	ListChanges    bool `bson:"list_changes" json:"list_changes"`
+	SecurityAlerts bool `bson:"security_alerts" json:"security_alerts"`
+}
+
+// Profile holds how the user is presented to the others and the preferences other features read, like the unit system or the locale used to sort
+type Profile struct {
+	DisplayName   string                  `bson:"display_name" json:"display_name"`
+	Locale        string                  `bson:"locale" json:"locale"`
+	Units         Units                   `bson:"units" json:"units"`
+	DefaultList   string                  `bson:"default_list" json:"default_list"`
+	Notifications NotificationPreferences `bson:"notifications" json:"notifications"`
+}
+
+// DefaultProfile returns the preferences of the users who never changed them
+func DefaultProfile() *Profile {
+	return &Profile{
+		Locale: language.English.String(),
+		Units:  UnitsMetric,
+		Notifications: NotificationPreferences{
+			SecurityAlerts: true,
+		},
+	}
+}
+
+// Preferences returns the profile of the user, or the default one when it was never set
+func (u *User) Preferences() *Profile {
+	if u.Profile == nil {
+		return DefaultProfile()
+	}
+
+	return u.Profile
+}
+
+// DisplayedName returns the display name of the user, falling back on the user name
+func (u *User) DisplayedName() string {
+	if u.Profile == nil || u.Profile.DisplayName == "" {
+		return u.Name
+	}
+
+	return u.Profile.DisplayName
+}
+
+// LanguageTag returns the locale of the profile, to format or collate in the language of the user
+func (p *Profile) LanguageTag() language.Tag {
+	tag, err := language.Parse(p.Locale)
+	if err != nil {
+		return language.English
+	}
+
+	return tag
+}
+
+// normalize validates the profile, trims the display name, canonicalizes the locale and fills the missing preferences with their default
+func (p *Profile) normalize() error {
+	p.DisplayName = strings.TrimSpace(p.DisplayName)
+	if utf8.RuneCountInString(p.DisplayName) > maxDisplayNameLength {
+		return fmt.Errorf("%w: the display name exceeds %d characters", ErrInvalidProfile, maxDisplayNameLength)
+	}
+
+	if p.Locale == "" {
+		p.Locale = DefaultProfile().Locale
+	}
+	tag, err := language.Parse(p.Locale)
+	if err != nil {
+		return fmt.Errorf("%w: unknown locale %s", ErrInvalidProfile, p.Locale)
+	}
+	p.Locale = tag.String()
+
+	switch p.Units {
+	case "":
+		p.Units = UnitsMetric
+	case UnitsMetric, UnitsImperial:
+	default:
+		return fmt.Errorf("%w: unknown units %s", ErrInvalidProfile, p.Units)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package user
+
+import (
+	"context"
+	"io"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/attachment"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+)
+
+// ErrNoAvatar is returned when opening the avatar of a user who has none
+var ErrNoAvatar = common.NewError(common.ErrNotFound, "the user has no avatar")
+
+// ProfileServiceImpl is the concrete implementation of the profile service interface
+type ProfileServiceImpl struct {
+	repo    Repository
+	storage attachment.Storage
+}
+
+// NewProfileService inits a new profile service. The avatars are kept in the attachment storage
+func NewProfileService(repo Repository, storage attachment.Storage) ProfileService {
+	return &ProfileServiceImpl{
+		repo:    repo,
+		storage: storage,
+	}
+}
+
+// FindProfile returns the profile of the user, or the default one when it was never set
+func (s *ProfileServiceImpl) FindProfile(ctx context.Context, userID string) (*Profile, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return user.Preferences(), nil
+}
+
+// UpdateProfile validates and replaces the profile of the user. The missing preferences are set to their default
+func (s *ProfileServiceImpl) UpdateProfile(ctx context.Context, userID string, profile *Profile) (int64, error) {
+	if err := profile.normalize(); err != nil {
+		return -1, err
+	}
+
+	return s.repo.UpdateProfile(ctx, userID, profile)
+}
+
+// UpdateAvatar stores the image as the new avatar of the user and deletes the previous one
+func (s *ProfileServiceImpl) UpdateAvatar(ctx context.Context, userID string, filename string, contentType string, content io.Reader) (*attachment.Attachment, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := user.Avatar
+
+	stored, err := s.storage.Save(ctx, filename, contentType, content)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.UpdateAvatar(ctx, userID, stored.ID); err != nil {
+		// do not leave an orphan file behind
+		if deleteErr := s.storage.Delete(ctx, stored.ID); deleteErr != nil {
+			return nil, deleteErr
+		}
+		return nil, err
+	}
+
+	if previous != "" {
+		if err := s.storage.Delete(ctx, previous); err != nil {
+			return nil, err
+		}
+	}
+
+	return stored, nil
+}
+
+// OpenAvatar returns the description and the content of the avatar of the user
+func (s *ProfileServiceImpl) OpenAvatar(ctx context.Context, userID string) (*attachment.Attachment, io.ReadCloser, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.Avatar == "" {
+		return nil, nil, ErrNoAvatar
+	}
+
+	return s.storage.Open(ctx, user.Avatar)
+}
+
+// RemoveAvatar removes the avatar of the user and deletes the image
+func (s *ProfileServiceImpl) RemoveAvatar(ctx context.Context, userID string) (int64, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return -1, err
+	}
+	previous := user.Avatar
+	if previous == "" {
+		return 0, nil
+	}
+
+	n, err := s.repo.UpdateAvatar(ctx, userID, "")
+	if err != nil {
+		return -1, err
+	}
+
+	if err := s.storage.Delete(ctx, previous); err != nil {
+		return -1, err
+	}
+
+	return n, nil
+}
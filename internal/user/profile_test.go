@@ -0,0 +1,83 @@
+package user_test
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/attachment"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateProfile(t *testing.T) {
+	ctx := context.Background()
+	repo := user.NewInMemoryRepository()
+	srv := user.NewProfileService(repo, nil)
+	alice, err := repo.Store(ctx, "alice", "password")
+	assert.NoError(t, err)
+
+	// users who never set their profile get the default preferences
+	profile, err := srv.FindProfile(ctx, alice.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, user.DefaultProfile(), profile)
+	assert.Equal(t, "alice", alice.DisplayedName())
+
+	invalid := []*user.Profile{
+		{Units: "cubits"},
+		{Locale: "not a locale"},
+		{DisplayName: strings.Repeat("a", 65)},
+	}
+	for _, p := range invalid {
+		_, err := srv.UpdateProfile(ctx, alice.ID.Hex(), p)
+		assert.ErrorIs(t, err, user.ErrInvalidProfile)
+	}
+
+	_, err = srv.UpdateProfile(ctx, alice.ID.Hex(), &user.Profile{DisplayName: "  Alice  ", Locale: "fr-fr", Units: user.UnitsImperial})
+	assert.NoError(t, err)
+
+	profile, err = srv.FindProfile(ctx, alice.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, "Alice", profile.DisplayName)
+	assert.Equal(t, "fr-FR", profile.Locale)
+	assert.Equal(t, "fr-FR", profile.LanguageTag().String())
+	assert.Equal(t, user.UnitsImperial, profile.Units)
+	assert.Equal(t, "Alice", alice.DisplayedName())
+}
+
+func TestUpdateAvatar(t *testing.T) {
+	ctx := context.Background()
+	storage, err := attachment.NewDiskStorage(t.TempDir())
+	assert.NoError(t, err)
+	repo := user.NewInMemoryRepository()
+	srv := user.NewProfileService(repo, storage)
+	alice, err := repo.Store(ctx, "alice", "password")
+	assert.NoError(t, err)
+
+	_, _, err = srv.OpenAvatar(ctx, alice.ID.Hex())
+	assert.ErrorIs(t, err, user.ErrNoAvatar)
+
+	first, err := srv.UpdateAvatar(ctx, alice.ID.Hex(), "first.png", "image/png", strings.NewReader("first"))
+	assert.NoError(t, err)
+	second, err := srv.UpdateAvatar(ctx, alice.ID.Hex(), "second.png", "image/png", strings.NewReader("second"))
+	assert.NoError(t, err)
+
+	// the replaced avatar is deleted from the storage
+	_, _, err = storage.Open(ctx, first.ID)
+	assert.Error(t, err)
+
+	opened, content, err := srv.OpenAvatar(ctx, alice.ID.Hex())
+	assert.NoError(t, err)
+	body, err := ioutil.ReadAll(content)
+	content.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, second.ID, opened.ID)
+	assert.Equal(t, "second", string(body))
+
+	n, err := srv.RemoveAvatar(ctx, alice.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	_, _, err = storage.Open(ctx, second.ID)
+	assert.Error(t, err)
+}
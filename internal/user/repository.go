@@ -1,6 +1,9 @@
 package user
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // FinderByID is a single method interface for finding a user from the database based on its id
 type FinderByID interface {
@@ -27,6 +30,33 @@ type PasswordUpdater interface {
 	UpdatePassword(ctx context.Context, userID string, newPassword string) (int64, error)
 }
 
+// EmailUpdater is a single method interface for updating the email address of a user
+type EmailUpdater interface {
+	UpdateEmail(ctx context.Context, userID string, newEmail string) (int64, error)
+}
+
+// ProfileUpdater is a single method interface for replacing the profile of a user
+type ProfileUpdater interface {
+	UpdateProfile(ctx context.Context, userID string, profile *Profile) (int64, error)
+}
+
+// AvatarUpdater is a single method interface for setting the attachment id of the avatar of a user. An empty id removes it
+type AvatarUpdater interface {
+	UpdateAvatar(ctx context.Context, userID string, avatarID string) (int64, error)
+}
+
+// TOTPUpdater is a single method interface for replacing the two-factor authentication settings of a user. A nil value removes them
+type TOTPUpdater interface {
+	UpdateTOTP(ctx context.Context, userID string, totp *TOTP) (int64, error)
+}
+
+// SecondFactorConsumer defines the uses of the second factor. Each update only happens if the step or the recovery code is still unused,
+// so that concurrent logins cannot use the same code twice
+type SecondFactorConsumer interface {
+	UseTOTPStep(ctx context.Context, userID string, step int64) (int64, error)
+	UseRecoveryCode(ctx context.Context, userID string, recoveryCodeHash string) (int64, error)
+}
+
 // Deleter is a single method interface for deleting a user from the database
 type Deleter interface {
 	Delete(ctx context.Context, userID string) (int64, error)
@@ -38,13 +68,212 @@ type PermissionsUpdater interface {
 	RemovePermissions(ctx context.Context, userID string, permissions ...*Permission) (int64, error)
 }
 
+// ResourcePermissionsRemover is a single method interface for taking from every user the permissions they hold on the resources
+type ResourcePermissionsRemover interface {
+	RemoveResourcePermissions(ctx context.Context, resourceIDs ...string) (int64, error)
+}
+
+// RolesUpdater defines user roles operations
+type RolesUpdater interface {
+	AddRoles(ctx context.Context, userID string, roles ...string) (int64, error)
+	RemoveRoles(ctx context.Context, userID string, roles ...string) (int64, error)
+}
+
+// Searcher is a single method interface for listing a page of the users selected by a query, sorted by name.
+// The total number of selected users is returned with the page
+type Searcher interface {
+	SearchUsers(ctx context.Context, query *UserQuery) ([]*User, int64, error)
+}
+
+// StatusUpdater defines the bulk account status operations
+type StatusUpdater interface {
+	UpdateDisabled(ctx context.Context, disabled bool, userIDs ...string) (int64, error)
+	UpdatePasswordResetRequired(ctx context.Context, required bool, userIDs ...string) (int64, error)
+}
+
 // Repository defines all possible actions on users database
 type Repository interface {
 	FinderByID
 	FinderByName
+	Searcher
 	Storer
 	NameUpdater
 	PasswordUpdater
+	EmailUpdater
+	TOTPUpdater
+	SecondFactorConsumer
+	ProfileUpdater
+	AvatarUpdater
 	Deleter
 	PermissionsUpdater
+	ResourcePermissionsRemover
+	RolesUpdater
+	StatusUpdater
+}
+
+// SessionFinderByID is a single method interface for finding a session by id
+type SessionFinderByID interface {
+	FindSessionByID(ctx context.Context, sessionID string) (*Session, error)
+}
+
+// SessionStorer is a single method interface for storing a new session
+type SessionStorer interface {
+	StoreSession(ctx context.Context, userID string, refreshTokenHash string, expiresAt time.Time) (*Session, error)
+}
+
+// SessionRotator is a single method interface for replacing the refresh token of a session.
+// The update only happens if the current hash still matches so that a refresh token can only be used once
+type SessionRotator interface {
+	RotateSession(ctx context.Context, sessionID string, currentHash string, newHash string, expiresAt time.Time) (int64, error)
+}
+
+// SessionRevoker defines the revocation operations on sessions
+type SessionRevoker interface {
+	RevokeSession(ctx context.Context, sessionID string) (int64, error)
+	RevokeAllSessions(ctx context.Context, userID string) (int64, error)
+}
+
+// RevocationList is a single method interface telling whether a session can no longer be used
+type RevocationList interface {
+	IsRevoked(ctx context.Context, sessionID string) (bool, error)
+}
+
+// SessionRepository defines all possible actions on the sessions database
+type SessionRepository interface {
+	SessionFinderByID
+	SessionStorer
+	SessionRotator
+	SessionRevoker
+	RevocationList
+}
+
+// RoleFinderByName is a single method interface for finding a role by name
+type RoleFinderByName interface {
+	FindRoleByName(ctx context.Context, name string) (*Role, error)
+}
+
+// RoleFinder defines the role listing operations
+type RoleFinder interface {
+	FindAllRoles(ctx context.Context) ([]*Role, error)
+	FindRolesByNames(ctx context.Context, names ...string) ([]*Role, error)
+}
+
+// RoleStorer is a single method interface for storing a new role
+type RoleStorer interface {
+	StoreRole(ctx context.Context, name string, permissions ...*Permission) (*Role, error)
+}
+
+// RolePermissionsUpdater is a single method interface for replacing the permissions of a role
+type RolePermissionsUpdater interface {
+	UpdateRolePermissions(ctx context.Context, name string, permissions ...*Permission) (int64, error)
+}
+
+// RoleDeleter is a single method interface for deleting a role
+type RoleDeleter interface {
+	DeleteRole(ctx context.Context, name string) (int64, error)
+}
+
+// RoleRepository defines all possible actions on the roles database
+type RoleRepository interface {
+	RoleFinderByName
+	RoleFinder
+	RoleStorer
+	RolePermissionsUpdater
+	RoleDeleter
+}
+
+// ResetTokenStorer is a single method interface for storing a new password reset token
+type ResetTokenStorer interface {
+	StoreResetToken(ctx context.Context, userID string, tokenHash string, expiresAt time.Time) (*ResetToken, error)
+}
+
+// ResetTokenConsumer is a single method interface for using a password reset token.
+// The token is marked as used in the same operation so that it can only be used once
+type ResetTokenConsumer interface {
+	ConsumeResetToken(ctx context.Context, tokenHash string, now time.Time) (*ResetToken, error)
+}
+
+// ResetTokenRepository defines all possible actions on the password reset tokens database
+type ResetTokenRepository interface {
+	ResetTokenStorer
+	ResetTokenConsumer
+}
+
+// LoginAttemptsFinder is a single method interface for retrieving the failed logins of keys. Unknown keys are left out
+type LoginAttemptsFinder interface {
+	FindLoginAttempts(ctx context.Context, keys ...string) ([]*LoginAttempts, error)
+}
+
+// LoginFailureRecorder is a single method interface for counting a failed login.
+// The count is incremented atomically so that concurrent instances share it, and the updated attempts are returned
+type LoginFailureRecorder interface {
+	RecordLoginFailure(ctx context.Context, key string, now time.Time, expiresAt time.Time) (*LoginAttempts, error)
+}
+
+// LoginLocker is a single method interface for locking a key out until the given time
+type LoginLocker interface {
+	LockLogin(ctx context.Context, key string, until time.Time) (int64, error)
+}
+
+// LoginAttemptsResetter is a single method interface for forgetting the failed logins of a key
+type LoginAttemptsResetter interface {
+	ResetLoginAttempts(ctx context.Context, key string) (int64, error)
+}
+
+// LoginAttemptsRepository defines all possible actions on the failed logins database
+type LoginAttemptsRepository interface {
+	LoginAttemptsFinder
+	LoginFailureRecorder
+	LoginLocker
+	LoginAttemptsResetter
+}
+
+// SecurityEventRecorder is a single method interface for recording a security event
+type SecurityEventRecorder interface {
+	RecordSecurityEvent(ctx context.Context, event *SecurityEvent) error
+}
+
+// SecurityEventFinder is a single method interface for listing the latest security events
+type SecurityEventFinder interface {
+	FindSecurityEvents(ctx context.Context, limit int64) ([]*SecurityEvent, error)
+}
+
+// SecurityEventRepository defines all possible actions on the security events database
+type SecurityEventRepository interface {
+	SecurityEventRecorder
+	SecurityEventFinder
+}
+
+// AccessTokenFinderByHash is a single method interface for finding a personal access token by the hash of the token
+type AccessTokenFinderByHash interface {
+	FindAccessTokenByHash(ctx context.Context, tokenHash string) (*AccessToken, error)
+}
+
+// AccessTokenFinderByUser is a single method interface for listing the personal access tokens of a user
+type AccessTokenFinderByUser interface {
+	FindAccessTokensByUser(ctx context.Context, userID string) ([]*AccessToken, error)
+}
+
+// AccessTokenStorer is a single method interface for storing a personal access token
+type AccessTokenStorer interface {
+	StoreAccessToken(ctx context.Context, accessToken *AccessToken) error
+}
+
+// AccessTokenToucher is a single method interface for recording the last use of a personal access token
+type AccessTokenToucher interface {
+	TouchAccessToken(ctx context.Context, tokenID string, usedAt time.Time) (int64, error)
+}
+
+// AccessTokenDeleter is a single method interface for revoking a personal access token of a user
+type AccessTokenDeleter interface {
+	DeleteAccessToken(ctx context.Context, userID string, tokenID string) (int64, error)
+}
+
+// AccessTokenRepository defines all possible actions on the personal access tokens database
+type AccessTokenRepository interface {
+	AccessTokenFinderByHash
+	AccessTokenFinderByUser
+	AccessTokenStorer
+	AccessTokenToucher
+	AccessTokenDeleter
 }
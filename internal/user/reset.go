@@ -0,0 +1,24 @@
+package user
+
+import (
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+)
+
+// ErrInvalidResetToken is returned when a password reset token is unknown, expired or already used
+var ErrInvalidResetToken = common.NewError(common.ErrValidation, "invalid password reset token")
+
+// ResetToken allows a user who forgot their password to choose a new one. Only the hash of the token is stored
+type ResetToken struct {
+	common.BaseModel `bson:",inline"`
+	UserID           string     `bson:"user_id"`
+	TokenHash        string     `bson:"token_hash"`
+	ExpiresAt        time.Time  `bson:"expires_at"`
+	UsedAt           *time.Time `bson:"used_at,omitempty"`
+}
+
+// IsUsable returns true if the token was not used and is not expired
+func (t *ResetToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,62 @@
+package user
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InMemoryResetTokenRepository is an in-memory password reset token repository
+type InMemoryResetTokenRepository struct {
+	mu     sync.Mutex
+	tokens []*ResetToken
+}
+
+// NewInMemoryResetTokenRepository inits a new in-memory password reset token repository
+func NewInMemoryResetTokenRepository() ResetTokenRepository {
+	return &InMemoryResetTokenRepository{
+		tokens: []*ResetToken{},
+	}
+}
+
+// StoreResetToken creates a new password reset token
+func (r *InMemoryResetTokenRepository) StoreResetToken(ctx context.Context, userID string, tokenHash string, expiresAt time.Time) (*ResetToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token := &ResetToken{
+		BaseModel: common.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+		UserID:    userID,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+	}
+	r.tokens = append(r.tokens, token)
+
+	copied := *token
+	return &copied, nil
+}
+
+// ConsumeResetToken marks the usable token matching the hash as used
+func (r *InMemoryResetTokenRepository) ConsumeResetToken(ctx context.Context, tokenHash string, now time.Time) (*ResetToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash && token.IsUsable(now) {
+			token.UsedAt = &now
+			token.UpdatedAt = now
+
+			copied := *token
+			return &copied, nil
+		}
+	}
+
+	return nil, ErrInvalidResetToken
+}
@@ -0,0 +1,73 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDBResetTokenRepository is a password reset token repository based on mongodb
+type MongoDBResetTokenRepository struct {
+	ResetTokenCollection *mongo.Collection
+}
+
+// NewMongoDBResetTokenRepository inits a new mongodb password reset token repository
+func NewMongoDBResetTokenRepository(collection *mongo.Collection) ResetTokenRepository {
+	return &MongoDBResetTokenRepository{
+		ResetTokenCollection: collection,
+	}
+}
+
+// StoreResetToken creates a new password reset token
+func (r *MongoDBResetTokenRepository) StoreResetToken(ctx context.Context, userID string, tokenHash string, expiresAt time.Time) (*ResetToken, error) {
+	token := &ResetToken{
+		BaseModel: common.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+		UserID:    userID,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+	}
+
+	if _, err := r.ResetTokenCollection.InsertOne(ctx, token); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// ConsumeResetToken atomically marks the usable token matching the hash as used
+func (r *MongoDBResetTokenRepository) ConsumeResetToken(ctx context.Context, tokenHash string, now time.Time) (*ResetToken, error) {
+	var token ResetToken
+	err := r.ResetTokenCollection.FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"token_hash": tokenHash,
+			"used_at":    bson.M{"$exists": false},
+			"expires_at": bson.M{"$gt": now},
+		},
+		bson.D{
+			{Key: "$set", Value: bson.D{
+				{Key: "used_at", Value: now},
+				{Key: "updated_at", Value: now},
+			}},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&token)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrInvalidResetToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
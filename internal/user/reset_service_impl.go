@@ -0,0 +1,156 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/notify"
+)
+
+// ResetServiceImpl is the concrete implementation of the password reset service interface
+type ResetServiceImpl struct {
+	users    Service
+	tokens   ResetTokenRepository
+	attempts LoginAttemptsRepository
+	notifier notify.Notifier
+	policy   *ResetPolicy
+	validity time.Duration
+	resetURL string
+}
+
+// NewResetService inits a new password reset service. Tokens are valid for validity and are sent through the notifier,
+// appended to resetURL when it is set so that users can follow a link. The requests are counted with the login attempts and limited by the policy
+func NewResetService(users Service, tokens ResetTokenRepository, attempts LoginAttemptsRepository, notifier notify.Notifier, policy *ResetPolicy, validity time.Duration, resetURL string) ResetService {
+	return &ResetServiceImpl{
+		users:    users,
+		tokens:   tokens,
+		attempts: attempts,
+		notifier: notifier,
+		policy:   policy,
+		validity: validity,
+		resetURL: resetURL,
+	}
+}
+
+// RequestPasswordReset sends a reset token to the user in the background. Nothing is sent, and no error is returned, if the user does not exist,
+// has no email address or asked too many times, so that callers cannot find out which users exist nor flood a user with emails.
+// A LockedOutError is returned when the address asked too many times
+func (s *ResetServiceImpl) RequestPasswordReset(ctx context.Context, userName string, ip string) error {
+	now := time.Now()
+	if ip != "" {
+		attempts, err := s.attempts.RecordLoginFailure(ctx, resetIPKey(ip), now, now.Add(s.policy.Window))
+		if err != nil {
+			return err
+		}
+		if s.policy.IPThreshold > 0 && attempts.Failures > s.policy.IPThreshold {
+			return &LockedOutError{Until: attempts.ExpiresAt}
+		}
+	}
+
+	attempts, err := s.attempts.RecordLoginFailure(ctx, resetAccountKey(userName), now, now.Add(s.policy.Window))
+	if err != nil {
+		return err
+	}
+	if s.policy.AccountThreshold > 0 && attempts.Failures > s.policy.AccountThreshold {
+		return nil
+	}
+
+	// the lookup and the delivery take the same time whether the user exists or not for the caller, and a failed delivery is not reported to them
+	go func() {
+		if err := s.sendResetTokenTo(context.Background(), userName); err != nil {
+			log.Printf("cannot send a password reset token : %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// sendResetTokenTo sends a reset token to the user given by name, if they exist and have an email address
+func (s *ResetServiceImpl) sendResetTokenTo(ctx context.Context, userName string) error {
+	user, err := s.users.FindByName(ctx, userName)
+	if err != nil || user.Email == "" {
+		return nil
+	}
+
+	return s.sendResetToken(ctx, user)
+}
+
+// ForcePasswordReset requires the users to reset their password, logs them out everywhere and sends them a reset token.
+// The ids of the users who could not be sent a token because they have no email address are returned along with the number of updated users
+func (s *ResetServiceImpl) ForcePasswordReset(ctx context.Context, userIDs ...string) (int64, []string, error) {
+	n, err := s.users.RequirePasswordReset(ctx, userIDs...)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	unreachable := []string{}
+	for _, userID := range userIDs {
+		user, err := s.users.FindByID(ctx, userID)
+		if err != nil {
+			continue
+		}
+		if user.Email == "" {
+			unreachable = append(unreachable, userID)
+			continue
+		}
+
+		if err := s.sendResetToken(ctx, user); err != nil {
+			return -1, nil, err
+		}
+	}
+
+	return n, unreachable, nil
+}
+
+// sendResetToken stores a new reset token for the user and sends it to their email address
+func (s *ResetServiceImpl) sendResetToken(ctx context.Context, user *User) error {
+	token, hash, err := newSecret()
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.tokens.StoreResetToken(ctx, user.ID.Hex(), hash, time.Now().Add(s.validity)); err != nil {
+		return err
+	}
+
+	return s.notifier.Notify(ctx, &notify.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body:    s.resetBody(user, token),
+	})
+}
+
+// ResetPassword sets the new password of the user the token was issued for and logs them out everywhere. It returns the id of the user
+func (s *ResetServiceImpl) ResetPassword(ctx context.Context, token string, newPassword string) (string, error) {
+	resetToken, err := s.tokens.ConsumeResetToken(ctx, hashSecret(token), time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := s.users.SetPassword(ctx, resetToken.UserID, newPassword); err != nil {
+		return "", err
+	}
+
+	if _, err := s.users.LogoutEverywhere(ctx, resetToken.UserID); err != nil {
+		return "", err
+	}
+
+	return resetToken.UserID, nil
+}
+
+func (s *ResetServiceImpl) resetBody(user *User, token string) string {
+	link := token
+	if s.resetURL != "" {
+		link = fmt.Sprintf("%s?token=%s", s.resetURL, url.QueryEscape(token))
+	}
+
+	return fmt.Sprintf(
+		"Hello %s,\n\nSomeone asked to reset your password. If it was you, use the following within %v:\n\n%s\n\nOtherwise, you can ignore this message.\n",
+		user.Name,
+		s.validity,
+		link,
+	)
+}
@@ -0,0 +1,118 @@
+package user_test
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/notify"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingNotifier keeps the messages instead of delivering them
+type recordingNotifier struct {
+	mu       sync.Mutex
+	messages []*notify.Message
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, message *notify.Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+// sent returns the messages received so far
+func (n *recordingNotifier) sent() []*notify.Message {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return append([]*notify.Message{}, n.messages...)
+}
+
+// sentCount returns a condition true once count messages were received
+func (n *recordingNotifier) sentCount(count int) func() bool {
+	return func() bool {
+		return len(n.sent()) == count
+	}
+}
+
+func TestPasswordReset(t *testing.T) {
+	ctx := context.Background()
+	repo := user.NewInMemoryRepository()
+	sessions := user.NewInMemorySessionRepository()
+	users := user.NewService(repo, sessions, user.NewInMemoryRoleRepository(), user.NewInMemoryAccessTokenRepository(), testHasher, nil, time.Minute, time.Hour)
+	notifier := &recordingNotifier{}
+	srv := user.NewResetService(users, user.NewInMemoryResetTokenRepository(), user.NewInMemoryLoginAttemptsRepository(), notifier, &user.ResetPolicy{}, time.Hour, "https://shoplist.example/reset")
+
+	alice, err := users.Store(ctx, "alice", "forgotten")
+	assert.NoError(t, err)
+	_, err = sessions.StoreSession(ctx, alice.ID.Hex(), "hash", time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+
+	// nothing is sent to unknown users or users without email address, and callers cannot tell
+	assert.NoError(t, srv.RequestPasswordReset(ctx, "nobody", "10.0.0.1"))
+	assert.NoError(t, srv.RequestPasswordReset(ctx, "alice", "10.0.0.1"))
+	assert.Never(t, notifier.sentCount(1), 50*time.Millisecond, time.Millisecond)
+
+	_, err = users.UpdateEmail(ctx, alice.ID.Hex(), "alice@example.org")
+	assert.NoError(t, err)
+	assert.NoError(t, srv.RequestPasswordReset(ctx, "alice", "10.0.0.1"))
+	assert.Eventually(t, notifier.sentCount(1), time.Second, time.Millisecond)
+	assert.Equal(t, "alice@example.org", notifier.sent()[0].To)
+
+	token := regexp.MustCompile(`token=(\S+)`).FindStringSubmatch(notifier.sent()[0].Body)[1]
+
+	_, err = srv.ResetPassword(ctx, "wrong", "new password")
+	assert.ErrorIs(t, err, user.ErrInvalidResetToken)
+	userID, err := srv.ResetPassword(ctx, token, "new password")
+	assert.NoError(t, err)
+	assert.Equal(t, alice.ID.Hex(), userID)
+
+	// the password went through the same hashing as the other updates and the sessions were revoked
+	reset, err := users.FindByID(ctx, alice.ID.Hex())
+	assert.NoError(t, err)
+	assert.NoError(t, testHasher.Verify(reset.Password, "new password"))
+	n, err := users.LogoutEverywhere(ctx, alice.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+
+	// the token can only be used once
+	_, err = srv.ResetPassword(ctx, token, "another password")
+	assert.ErrorIs(t, err, user.ErrInvalidResetToken)
+}
+
+func TestPasswordResetLimits(t *testing.T) {
+	ctx := context.Background()
+	users := user.NewService(user.NewInMemoryRepository(), user.NewInMemorySessionRepository(), user.NewInMemoryRoleRepository(), user.NewInMemoryAccessTokenRepository(), testHasher, nil, time.Minute, time.Hour)
+	notifier := &recordingNotifier{}
+	srv := user.NewResetService(users, user.NewInMemoryResetTokenRepository(), user.NewInMemoryLoginAttemptsRepository(), notifier, &user.ResetPolicy{
+		AccountThreshold: 2,
+		IPThreshold:      4,
+		Window:           time.Hour,
+	}, time.Hour, "")
+
+	alice, err := users.Store(ctx, "alice", "forgotten")
+	assert.NoError(t, err)
+	_, err = users.UpdateEmail(ctx, alice.ID.Hex(), "alice@example.org")
+	assert.NoError(t, err)
+
+	// a user cannot be flooded, even from many addresses, and the dropped requests look accepted
+	assert.NoError(t, srv.RequestPasswordReset(ctx, "alice", "10.0.0.1"))
+	assert.NoError(t, srv.RequestPasswordReset(ctx, "alice", "10.0.0.2"))
+	assert.NoError(t, srv.RequestPasswordReset(ctx, "alice", "10.0.0.3"))
+	assert.Eventually(t, notifier.sentCount(2), time.Second, time.Millisecond)
+	assert.Never(t, notifier.sentCount(3), 50*time.Millisecond, time.Millisecond)
+
+	// an address asking too many times is refused, whether the users exist or not
+	for _, name := range []string{"bob", "carol", "dave", "erin"} {
+		assert.NoError(t, srv.RequestPasswordReset(ctx, name, "10.0.0.4"))
+	}
+	var lockedOut *user.LockedOutError
+	assert.ErrorAs(t, srv.RequestPasswordReset(ctx, "frank", "10.0.0.4"), &lockedOut)
+	assert.NoError(t, srv.RequestPasswordReset(ctx, "frank", "10.0.0.5"))
+}
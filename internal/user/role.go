@@ -0,0 +1,39 @@
+package user
+
+import (
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+)
+
+// Role is a named set of permissions that can be given to users
+type Role struct {
+	common.BaseModel `bson:",inline"`
+	Name             string        `bson:"name" json:"name"`
+	Permissions      []*Permission `bson:"permissions" json:"permissions"`
+}
+
+// DefaultRoles returns the roles every installation starts with
+func DefaultRoles() []*Role {
+	return []*Role{
+		{
+			Name: "viewer",
+			Permissions: []*Permission{
+				{ResourceID: "list-*", Action: "read"},
+			},
+		},
+		{
+			Name: "editor",
+			Permissions: []*Permission{
+				{ResourceID: "list-*", Action: "write"},
+				{ResourceID: "filter-*", Action: "write"},
+				{ResourceID: "stores", Action: "write"},
+				{ResourceID: "invites", Action: "write"},
+			},
+		},
+		{
+			Name: "admin",
+			Permissions: []*Permission{
+				{ResourceID: "*", Action: "*"},
+			},
+		},
+	}
+}
@@ -0,0 +1,108 @@
+package user
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InMemoryRoleRepository is an in-memory role repository
+type InMemoryRoleRepository struct {
+	roles map[string]*Role
+}
+
+// NewInMemoryRoleRepository inits a new in-memory role repository
+func NewInMemoryRoleRepository() RoleRepository {
+	return &InMemoryRoleRepository{
+		roles: make(map[string]*Role),
+	}
+}
+
+// FindRoleByName returns the role with the given name
+func (r *InMemoryRoleRepository) FindRoleByName(ctx context.Context, name string) (*Role, error) {
+	role, exists := r.roles[name]
+	if !exists {
+		return nil, common.NewError(common.ErrNotFound, "Role not found with name %s", name)
+	}
+
+	return role, nil
+}
+
+// FindAllRoles returns all the roles sorted by name
+func (r *InMemoryRoleRepository) FindAllRoles(ctx context.Context) ([]*Role, error) {
+	roles := []*Role{}
+	for _, role := range r.roles {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+
+	return roles, nil
+}
+
+// FindRolesByNames returns the roles with the given names. Unknown names are ignored
+func (r *InMemoryRoleRepository) FindRolesByNames(ctx context.Context, names ...string) ([]*Role, error) {
+	roles := []*Role{}
+	for _, name := range names {
+		if role, exists := r.roles[name]; exists {
+			roles = append(roles, role)
+		}
+	}
+
+	return roles, nil
+}
+
+// StoreRole creates a new role
+func (r *InMemoryRoleRepository) StoreRole(ctx context.Context, name string, permissions ...*Permission) (*Role, error) {
+	if _, exists := r.roles[name]; exists {
+		return nil, common.NewError(common.ErrConflict, "Role %s already exists", name)
+	}
+
+	if permissions == nil {
+		permissions = []*Permission{}
+	}
+
+	role := &Role{
+		BaseModel: common.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+		Name:        name,
+		Permissions: permissions,
+	}
+	r.roles[name] = role
+
+	return role, nil
+}
+
+// UpdateRolePermissions replaces the permissions of a role
+func (r *InMemoryRoleRepository) UpdateRolePermissions(ctx context.Context, name string, permissions ...*Permission) (int64, error) {
+	role, err := r.FindRoleByName(ctx, name)
+	if err != nil {
+		return -1, err
+	}
+
+	if permissions == nil {
+		permissions = []*Permission{}
+	}
+	role.Permissions = permissions
+	role.UpdatedAt = time.Now()
+
+	return 1, nil
+}
+
+// DeleteRole deletes a role
+func (r *InMemoryRoleRepository) DeleteRole(ctx context.Context, name string) (int64, error) {
+	if _, err := r.FindRoleByName(ctx, name); err != nil {
+		return -1, err
+	}
+
+	delete(r.roles, name)
+
+	return 1, nil
+}
@@ -0,0 +1,109 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDBRoleRepository is a role repository based on mongodb
+type MongoDBRoleRepository struct {
+	RoleCollection *mongo.Collection
+}
+
+// NewMongoDBRoleRepository inits a new mongodb role repository
+func NewMongoDBRoleRepository(collection *mongo.Collection) RoleRepository {
+	return &MongoDBRoleRepository{
+		RoleCollection: collection,
+	}
+}
+
+// FindRoleByName returns the role with the given name
+func (r *MongoDBRoleRepository) FindRoleByName(ctx context.Context, name string) (*Role, error) {
+	var role Role
+	if err := r.RoleCollection.FindOne(ctx, bson.M{"name": name}).Decode(&role); err != nil {
+		return nil, common.MongoError(err, "role %v", name)
+	}
+
+	return &role, nil
+}
+
+// FindAllRoles returns all the roles sorted by name
+func (r *MongoDBRoleRepository) FindAllRoles(ctx context.Context) ([]*Role, error) {
+	return r.find(ctx, bson.M{})
+}
+
+// FindRolesByNames returns the roles with the given names. Unknown names are ignored
+func (r *MongoDBRoleRepository) FindRolesByNames(ctx context.Context, names ...string) ([]*Role, error) {
+	return r.find(ctx, bson.M{"name": bson.M{"$in": names}})
+}
+
+func (r *MongoDBRoleRepository) find(ctx context.Context, filter bson.M) ([]*Role, error) {
+	roles := []*Role{}
+	cursor, err := r.RoleCollection.Find(ctx, filter, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(ctx, &roles); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// StoreRole creates a new role
+func (r *MongoDBRoleRepository) StoreRole(ctx context.Context, name string, permissions ...*Permission) (*Role, error) {
+	if permissions == nil {
+		permissions = []*Permission{}
+	}
+
+	role := &Role{
+		BaseModel: common.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+		Name:        name,
+		Permissions: permissions,
+	}
+
+	if _, err := r.RoleCollection.InsertOne(ctx, role); err != nil {
+		return nil, common.MongoError(err, "role %v", name)
+	}
+
+	return role, nil
+}
+
+// UpdateRolePermissions replaces the permissions of a role
+func (r *MongoDBRoleRepository) UpdateRolePermissions(ctx context.Context, name string, permissions ...*Permission) (int64, error) {
+	if permissions == nil {
+		permissions = []*Permission{}
+	}
+
+	result, err := r.RoleCollection.UpdateOne(
+		ctx,
+		bson.M{"name": name},
+		bson.M{"$set": bson.M{"permissions": permissions, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// DeleteRole deletes a role
+func (r *MongoDBRoleRepository) DeleteRole(ctx context.Context, name string) (int64, error) {
+	result, err := r.RoleCollection.DeleteOne(ctx, bson.M{"name": name})
+	if err != nil {
+		return -1, err
+	}
+
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,42 @@
+package user
+
+import (
+	"context"
+	"sync"
+)
+
+// InMemorySecurityEventRepository is an in-memory security events repository
+type InMemorySecurityEventRepository struct {
+	mu     sync.Mutex
+	events []*SecurityEvent
+}
+
+// NewInMemorySecurityEventRepository inits a new in-memory security events repository
+func NewInMemorySecurityEventRepository() SecurityEventRepository {
+	return &InMemorySecurityEventRepository{
+		events: []*SecurityEvent{},
+	}
+}
+
+// RecordSecurityEvent stores the event
+func (r *InMemorySecurityEventRepository) RecordSecurityEvent(ctx context.Context, event *SecurityEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+
+	return nil
+}
+
+// FindSecurityEvents retrieves the latest events, most recent first
+func (r *InMemorySecurityEventRepository) FindSecurityEvents(ctx context.Context, limit int64) ([]*SecurityEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := []*SecurityEvent{}
+	for i := len(r.events) - 1; i >= 0 && int64(len(events)) < limit; i-- {
+		events = append(events, r.events[i])
+	}
+
+	return events, nil
+}
@@ -0,0 +1,43 @@
+package user
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDBSecurityEventRepository is a security events repository based on mongodb
+type MongoDBSecurityEventRepository struct {
+	SecurityEventCollection *mongo.Collection
+}
+
+// NewMongoDBSecurityEventRepository inits a new mongodb security events repository
+func NewMongoDBSecurityEventRepository(collection *mongo.Collection) SecurityEventRepository {
+	return &MongoDBSecurityEventRepository{
+		SecurityEventCollection: collection,
+	}
+}
+
+// RecordSecurityEvent inserts the event
+func (r *MongoDBSecurityEventRepository) RecordSecurityEvent(ctx context.Context, event *SecurityEvent) error {
+	_, err := r.SecurityEventCollection.InsertOne(ctx, event)
+
+	return err
+}
+
+// FindSecurityEvents retrieves the latest events, most recent first
+func (r *MongoDBSecurityEventRepository) FindSecurityEvents(ctx context.Context, limit int64) ([]*SecurityEvent, error) {
+	events := []*SecurityEvent{}
+	cursor, err := r.SecurityEventCollection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"created_at": -1}).SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
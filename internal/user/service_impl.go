@@ -2,24 +2,40 @@ package user
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
-	"github.com/NicolasDutronc/autokey"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"github.com/NicolasDutronc/shoppinglist-be/pkg/keyring"
 	"github.com/golang-jwt/jwt/v4"
-	"golang.org/x/crypto/bcrypt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // ServiceImpl is the concrete implementation of the user service interface
 type ServiceImpl struct {
-	repo        Repository
-	keyConsumer autokey.Consumer
+	repo            Repository
+	sessions        SessionRepository
+	roles           RoleRepository
+	accessTokens    AccessTokenRepository
+	hasher          PasswordHasher
+	signer          keyring.Signer
+	accessDuration  time.Duration
+	refreshDuration time.Duration
 }
 
-// NewService inits a new user service
-func NewService(repo Repository, keyConsumer autokey.Consumer) Service {
+// NewService inits a new user service.
+// Passwords are hashed by hasher, access tokens are valid for accessDuration and sessions expire when they are not refreshed for refreshDuration
+func NewService(repo Repository, sessions SessionRepository, roles RoleRepository, accessTokens AccessTokenRepository, hasher PasswordHasher, signer keyring.Signer, accessDuration time.Duration, refreshDuration time.Duration) Service {
 	return &ServiceImpl{
-		repo:        repo,
-		keyConsumer: keyConsumer,
+		repo:            repo,
+		sessions:        sessions,
+		roles:           roles,
+		accessTokens:    accessTokens,
+		hasher:          hasher,
+		signer:          signer,
+		accessDuration:  accessDuration,
+		refreshDuration: refreshDuration,
 	}
 }
 
@@ -33,14 +49,31 @@ func (s *ServiceImpl) FindByName(ctx context.Context, userName string) (*User, e
 	return s.repo.FindByName(ctx, userName)
 }
 
+// FindWithRoles retrieves an enabled user along with the permissions granted by their roles, for the checks made outside of an authenticated request
+func (s *ServiceImpl) FindWithRoles(ctx context.Context, userID string) (*User, error) {
+	user, err := s.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled {
+		return nil, ErrUserDisabled
+	}
+
+	if err := s.resolveRoles(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
 // Store hashes the password and then calls the repository
 func (s *ServiceImpl) Store(ctx context.Context, name string, password string, permissions ...*Permission) (*User, error) {
-	encryptedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	encryptedPassword, err := s.hasher.Hash(password)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.repo.Store(ctx, name, string(encryptedPassword), permissions...)
+	return s.repo.Store(ctx, name, encryptedPassword, permissions...)
 }
 
 // UpdateName directly calls the repository
@@ -48,23 +81,33 @@ func (s *ServiceImpl) UpdateName(ctx context.Context, userID string, newName str
 	return s.repo.UpdateName(ctx, userID, newName)
 }
 
-// UpdatePassword compares the given password with the one stored in the database. If this is a match, it hashes the new password before calling the repository
+// UpdatePassword compares the given password with the one stored in the database. If this is a match, it sets the new password
 func (s *ServiceImpl) UpdatePassword(ctx context.Context, userID string, currentPassword string, newPassword string) (int64, error) {
 	user, err := s.FindByID(ctx, userID)
 	if err != nil {
 		return -1, err
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
+	if err := s.hasher.Verify(user.Password, currentPassword); err != nil {
 		return -1, err
 	}
 
-	encryptedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	return s.SetPassword(ctx, userID, newPassword)
+}
+
+// SetPassword hashes the new password before calling the repository, without checking the current one
+func (s *ServiceImpl) SetPassword(ctx context.Context, userID string, newPassword string) (int64, error) {
+	encryptedPassword, err := s.hasher.Hash(newPassword)
 	if err != nil {
 		return -1, err
 	}
 
-	return s.repo.UpdatePassword(ctx, userID, string(encryptedPassword))
+	return s.repo.UpdatePassword(ctx, userID, encryptedPassword)
+}
+
+// UpdateEmail directly calls the repository
+func (s *ServiceImpl) UpdateEmail(ctx context.Context, userID string, newEmail string) (int64, error) {
+	return s.repo.UpdateEmail(ctx, userID, newEmail)
 }
 
 // Delete directly calls the repository
@@ -72,8 +115,13 @@ func (s *ServiceImpl) Delete(ctx context.Context, userID string) (int64, error)
 	return s.repo.Delete(ctx, userID)
 }
 
-// AddPermissions adds permissions to the user
-func (s *ServiceImpl) AddPermissions(ctx context.Context, userID string, permissions ...*Permission) (int64, error) {
+// AddPermissions adds permissions to the user. The granter must hold the permissions, so that nobody can grant more than they can do.
+// A nil granter is the application itself, granting the permissions on the resources it creates or transfers
+func (s *ServiceImpl) AddPermissions(ctx context.Context, granter *User, userID string, permissions ...*Permission) (int64, error) {
+	if err := canGrant(granter, permissions); err != nil {
+		return -1, err
+	}
+
 	return s.repo.AddPermissions(ctx, userID, permissions...)
 }
 
@@ -82,55 +130,601 @@ func (s *ServiceImpl) RemovePermissions(ctx context.Context, userID string, perm
 	return s.repo.RemovePermissions(ctx, userID, permissions...)
 }
 
-// Login takes in a user name and a password and returns the corresponding user along with a token.
+// RemoveResourcePermissions takes from every user the permissions they hold on deleted resources
+func (s *ServiceImpl) RemoveResourcePermissions(ctx context.Context, resourceIDs ...string) (int64, error) {
+	return s.repo.RemoveResourcePermissions(ctx, resourceIDs...)
+}
+
+// AddRoles gives existing roles to the user. The granter must hold the permissions of the roles, like for AddPermissions
+func (s *ServiceImpl) AddRoles(ctx context.Context, granter *User, userID string, roles ...string) (int64, error) {
+	for _, name := range roles {
+		role, err := s.roles.FindRoleByName(ctx, name)
+		if errors.Is(err, common.ErrNotFound) {
+			return -1, common.NewError(common.ErrValidation, "role %v does not exist", name)
+		}
+		if err != nil {
+			return -1, err
+		}
+
+		if err := canGrant(granter, role.Permissions); err != nil {
+			return -1, err
+		}
+	}
+
+	return s.repo.AddRoles(ctx, userID, roles...)
+}
+
+// canGrant returns a forbidden error if the granter does not hold one of the permissions. A nil granter can grant anything
+func canGrant(granter *User, permissions []*Permission) error {
+	if granter == nil {
+		return nil
+	}
+
+	for _, p := range permissions {
+		if err := granter.Can(p.Action, p.ResourceID); err != nil {
+			return fmt.Errorf("cannot grant a permission that is not held: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// RemoveRoles takes roles from the user
+func (s *ServiceImpl) RemoveRoles(ctx context.Context, userID string, roles ...string) (int64, error) {
+	return s.repo.RemoveRoles(ctx, userID, roles...)
+}
+
+// SearchUsers returns a page of the users selected by the query, sorted by name
+func (s *ServiceImpl) SearchUsers(ctx context.Context, query *UserQuery) (*UserPage, error) {
+	users, total, err := s.repo.SearchUsers(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserPage{
+		Users:    users,
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}, nil
+}
+
+// DisableUsers prevents the users from logging in and from using their tokens, and revokes their sessions
+func (s *ServiceImpl) DisableUsers(ctx context.Context, userIDs ...string) (int64, error) {
+	n, err := s.repo.UpdateDisabled(ctx, true, userIDs...)
+	if err != nil {
+		return -1, err
+	}
+
+	for _, userID := range userIDs {
+		if _, err := s.sessions.RevokeAllSessions(ctx, userID); err != nil {
+			return -1, err
+		}
+	}
+
+	return n, nil
+}
+
+// EnableUsers lets disabled users log in again
+func (s *ServiceImpl) EnableUsers(ctx context.Context, userIDs ...string) (int64, error) {
+	return s.repo.UpdateDisabled(ctx, false, userIDs...)
+}
+
+// RequirePasswordReset prevents the users from logging in with their password until they set a new one, and revokes their sessions
+func (s *ServiceImpl) RequirePasswordReset(ctx context.Context, userIDs ...string) (int64, error) {
+	n, err := s.repo.UpdatePasswordResetRequired(ctx, true, userIDs...)
+	if err != nil {
+		return -1, err
+	}
+
+	for _, userID := range userIDs {
+		if _, err := s.sessions.RevokeAllSessions(ctx, userID); err != nil {
+			return -1, err
+		}
+	}
+
+	return n, nil
+}
+
+// FindAllRoles directly calls the role repository
+func (s *ServiceImpl) FindAllRoles(ctx context.Context) ([]*Role, error) {
+	return s.roles.FindAllRoles(ctx)
+}
+
+// FindRoleByName directly calls the role repository
+func (s *ServiceImpl) FindRoleByName(ctx context.Context, name string) (*Role, error) {
+	return s.roles.FindRoleByName(ctx, name)
+}
+
+// StoreRole directly calls the role repository
+func (s *ServiceImpl) StoreRole(ctx context.Context, name string, permissions ...*Permission) (*Role, error) {
+	return s.roles.StoreRole(ctx, name, permissions...)
+}
+
+// UpdateRolePermissions directly calls the role repository
+func (s *ServiceImpl) UpdateRolePermissions(ctx context.Context, name string, permissions ...*Permission) (int64, error) {
+	return s.roles.UpdateRolePermissions(ctx, name, permissions...)
+}
+
+// DeleteRole deletes a role. The users keep its name but it does not grant anything anymore
+func (s *ServiceImpl) DeleteRole(ctx context.Context, name string) (int64, error) {
+	return s.roles.DeleteRole(ctx, name)
+}
+
+// EnsureRoles creates the given roles that do not exist yet
+func (s *ServiceImpl) EnsureRoles(ctx context.Context, roles ...*Role) error {
+	existing, err := s.roles.FindAllRoles(ctx)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]bool, len(existing))
+	for _, role := range existing {
+		names[role.Name] = true
+	}
+
+	for _, role := range roles {
+		if names[role.Name] {
+			continue
+		}
+		if _, err := s.roles.StoreRole(ctx, role.Name, role.Permissions...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Login takes in a user name and a password and returns the corresponding user along with a new session tokens.
+// When the user enabled two-factor authentication, a challenge is returned instead of the tokens and has to be completed with VerifySecondFactor.
 // An error is returned instead if it cannot retrieve the user based on the given user name or if the given password does not match or if there is any issue retrieving the key and signing the token
-func (s *ServiceImpl) Login(ctx context.Context, userName string, password string) (*User, string, error) {
+// The stored hash is replaced when it was made with an older algorithm or other parameters than the current ones
+func (s *ServiceImpl) Login(ctx context.Context, userName string, password string) (*User, *Tokens, *Challenge, error) {
 	user, err := s.FindByName(ctx, userName)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, nil, err
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return nil, "", err
+	if err := s.hasher.Verify(user.Password, password); err != nil {
+		return nil, nil, nil, err
 	}
 
-	claims := &jwt.StandardClaims{
-		IssuedAt:  time.Now().Unix(),
-		ExpiresAt: time.Now().Add(24 * time.Hour).Unix(),
-		NotBefore: time.Now().Unix(),
-		Subject:   user.ID.Hex(),
+	if user.PasswordResetRequired {
+		return nil, nil, nil, ErrPasswordResetRequired
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
+	// the password is known here, it is the only chance to upgrade a hash made with an older algorithm or weaker parameters
+	if s.hasher.NeedsRehash(user.Password) {
+		if _, err := s.SetPassword(ctx, user.ID.Hex(), password); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	return s.completeLogin(ctx, user)
+}
 
-	key, err := s.keyConsumer.Get()
+// LoginWithIdentity logs in a user authenticated by an external identity provider, which stands for the password.
+// Like Login, a challenge is returned instead of the tokens when the user enabled two-factor authentication
+func (s *ServiceImpl) LoginWithIdentity(ctx context.Context, userID string) (*User, *Tokens, *Challenge, error) {
+	user, err := s.FindByID(ctx, userID)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, nil, err
+	}
+
+	return s.completeLogin(ctx, user)
+}
+
+// completeLogin starts a session for a user whose first factor was checked, or issues a challenge for the second one
+func (s *ServiceImpl) completeLogin(ctx context.Context, user *User) (*User, *Tokens, *Challenge, error) {
+	if user.Disabled {
+		return nil, nil, nil, ErrUserDisabled
+	}
+
+	if user.HasTwoFactor() {
+		challenge, err := s.issueChallenge(user)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		return user, nil, challenge, nil
 	}
 
-	ss, err := token.SignedString([]byte(key))
+	tokens, err := s.startSession(ctx, user)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, nil, err
 	}
 
-	return user, ss, nil
+	return user, tokens, nil, nil
 }
 
-// Authenticate retrieves a user from a token after validating it
-func (s *ServiceImpl) Authenticate(ctx context.Context, token string) (*User, error) {
-	parsedToken, err := jwt.ParseWithClaims(token, &jwt.StandardClaims{}, func(t *jwt.Token) (interface{}, error) {
-		if t.Method != jwt.SigningMethodHS512 {
-			return nil, jwt.ErrSignatureInvalid
+// ChallengedUser returns the user a login challenge was issued to, so that the attempts to complete it count against their account
+func (s *ServiceImpl) ChallengedUser(ctx context.Context, challenge string) (*User, error) {
+	claims, err := s.parseToken(&jwt.Parser{}, challenge, challengeAudience)
+	if err != nil {
+		return nil, ErrInvalidSecondFactor
+	}
+	if err := claims.Valid(); err != nil {
+		return nil, ErrInvalidSecondFactor
+	}
+
+	user, err := s.FindByID(ctx, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if !user.HasTwoFactor() {
+		return nil, ErrInvalidSecondFactor
+	}
+
+	return user, nil
+}
+
+// VerifySecondFactor completes a login challenge with a TOTP code or a recovery code and returns the session tokens
+func (s *ServiceImpl) VerifySecondFactor(ctx context.Context, challenge string, code string) (*User, *Tokens, error) {
+	user, err := s.ChallengedUser(ctx, challenge)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.Disabled {
+		return nil, nil, ErrUserDisabled
+	}
+
+	if err := s.checkSecondFactor(ctx, user, code); err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := s.startSession(ctx, user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, tokens, nil
+}
+
+// EnrollTOTP generates a new TOTP secret for the user. It is only enabled once a code is confirmed
+func (s *ServiceImpl) EnrollTOTP(ctx context.Context, userID string) (*TOTPEnrollment, error) {
+	user, err := s.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.HasTwoFactor() {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	totp, err := newTOTP()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.UpdateTOTP(ctx, userID, totp); err != nil {
+		return nil, err
+	}
+
+	return totp.Enrollment(user.Name), nil
+}
+
+// ConfirmTOTP enables two-factor authentication if the code matches the enrolled secret and returns the recovery codes.
+// The recovery codes are only given here
+func (s *ServiceImpl) ConfirmTOTP(ctx context.Context, userID string, code string) ([]string, error) {
+	user, err := s.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTP == nil {
+		return nil, ErrTOTPNotEnrolled
+	}
+	if user.TOTP.Enabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	step, ok := user.TOTP.verifyCode(code, time.Now())
+	if !ok {
+		return nil, ErrInvalidSecondFactor
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	user.TOTP.Enabled = true
+	user.TOTP.EnabledAt = time.Now()
+	user.TOTP.LastUsedStep = step
+	user.TOTP.RecoveryCodeHashes = hashes
+	if _, err := s.repo.UpdateTOTP(ctx, userID, user.TOTP); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableTOTP removes two-factor authentication after checking a TOTP code or a recovery code
+func (s *ServiceImpl) DisableTOTP(ctx context.Context, userID string, code string) error {
+	user, err := s.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.HasTwoFactor() {
+		return ErrTOTPNotEnrolled
+	}
+
+	if err := s.checkSecondFactor(ctx, user, code); err != nil {
+		return err
+	}
+
+	_, err = s.repo.UpdateTOTP(ctx, userID, nil)
+	return err
+}
+
+// checkSecondFactor verifies a TOTP code or, failing that, a recovery code and records its use.
+// The use is recorded only if the code is still unused in the database, so that a code used concurrently is only accepted once
+func (s *ServiceImpl) checkSecondFactor(ctx context.Context, user *User, code string) error {
+	var (
+		n   int64
+		err error
+	)
+	if step, ok := user.TOTP.verifyCode(code, time.Now()); ok {
+		n, err = s.repo.UseTOTPStep(ctx, user.ID.Hex(), step)
+	} else if hash, ok := user.TOTP.matchRecoveryCode(code); ok {
+		n, err = s.repo.UseRecoveryCode(ctx, user.ID.Hex(), hash)
+	} else {
+		return ErrInvalidSecondFactor
+	}
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrInvalidSecondFactor
+	}
+
+	return nil
+}
+
+// startSession creates a new session for the user and returns its tokens
+func (s *ServiceImpl) startSession(ctx context.Context, user *User) (*Tokens, error) {
+	secret, hash, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessions.StoreSession(ctx, user.ID.Hex(), hash, time.Now().Add(s.refreshDuration))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, session.ID.Hex(), secret)
+}
+
+// Refresh exchanges a refresh token for new tokens. The refresh token is rotated so that each one can only be used once.
+// Using an already used refresh token revokes the whole session as it was probably stolen
+func (s *ServiceImpl) Refresh(ctx context.Context, refreshToken string) (*User, *Tokens, error) {
+	sessionID, secret, err := parseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session, err := s.sessions.FindSessionByID(ctx, sessionID)
+	if err != nil || !session.IsActive() {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	if !session.matchesRefreshSecret(secret) {
+		if _, err := s.sessions.RevokeSession(ctx, sessionID); err != nil {
+			return nil, nil, err
 		}
+		return nil, nil, ErrInvalidRefreshToken
+	}
 
-		key, err := s.keyConsumer.Get()
-		if err != nil {
+	rotatedSecret, newHash, err := newSecret()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	n, err := s.sessions.RotateSession(ctx, sessionID, session.RefreshTokenHash, newHash, time.Now().Add(s.refreshDuration))
+	if err != nil {
+		return nil, nil, err
+	}
+	if n != 1 {
+		// the token was used concurrently
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.FindByID(ctx, session.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.Disabled {
+		return nil, nil, ErrUserDisabled
+	}
+
+	tokens, err := s.issueTokens(user, sessionID, rotatedSecret)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, tokens, nil
+}
+
+// Logout revokes the session of the given access token. An expired access token can still be used to log out
+func (s *ServiceImpl) Logout(ctx context.Context, accessToken string) error {
+	if isAccessToken(accessToken) {
+		return ErrAccessTokenLogout
+	}
+
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	claims, err := s.parseAccessToken(parser, accessToken)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.sessions.RevokeSession(ctx, claims.Id)
+	return err
+}
+
+// LogoutEverywhere revokes all the sessions of the user
+func (s *ServiceImpl) LogoutEverywhere(ctx context.Context, userID string) (int64, error) {
+	return s.sessions.RevokeAllSessions(ctx, userID)
+}
+
+// Authenticate retrieves a user from a token after validating it and checking that its session was not revoked.
+// Personal access tokens are accepted as well, in which case the user is restricted to the scopes of the token
+func (s *ServiceImpl) Authenticate(ctx context.Context, token string) (*User, error) {
+	if isAccessToken(token) {
+		return s.authenticateAccessToken(ctx, token)
+	}
+
+	claims, err := s.parseAccessToken(&jwt.Parser{}, token)
+	if err != nil {
+		return nil, err
+	}
+	if err := claims.Valid(); err != nil {
+		return nil, err
+	}
+
+	revoked, err := s.sessions.IsRevoked(ctx, claims.Id)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrSessionRevoked
+	}
+
+	user, err := s.FindByID(ctx, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled {
+		return nil, ErrUserDisabled
+	}
+
+	if err := s.resolveRoles(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// authenticateAccessToken retrieves the owner of a personal access token and restricts them to the scopes of the token
+func (s *ServiceImpl) authenticateAccessToken(ctx context.Context, token string) (*User, error) {
+	accessToken, err := s.accessTokens.FindAccessTokenByHash(ctx, hashSecret(token))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if accessToken.IsExpired(now) {
+		return nil, ErrInvalidAccessToken
+	}
+
+	user, err := s.FindByID(ctx, accessToken.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled {
+		return nil, ErrUserDisabled
+	}
+
+	if err := s.resolveRoles(ctx, user); err != nil {
+		return nil, err
+	}
+
+	user.Scopes = []*Permission{}
+	user.Scopes = append(user.Scopes, accessToken.Scopes...)
+
+	// the last use is only an indication, it is not worth a write on every request
+	if accessToken.LastUsedAt == nil || now.Sub(*accessToken.LastUsedAt) >= accessTokenTouchInterval {
+		if _, err := s.accessTokens.TouchAccessToken(ctx, accessToken.ID.Hex(), now); err != nil {
 			return nil, err
 		}
+	}
+
+	return user, nil
+}
+
+// CreateAccessToken creates a personal access token for the user, restricted to the given scopes.
+// The user must hold every scope and cannot be authenticated with a personal access token
+func (s *ServiceImpl) CreateAccessToken(ctx context.Context, creator *User, name string, validity time.Duration, scopes ...*Permission) (*AccessToken, string, error) {
+	if creator.Scopes != nil {
+		return nil, "", ErrScopedCredentials
+	}
 
-		return []byte(key), nil
-	})
+	for _, scope := range scopes {
+		if err := creator.Can(scope.Action, scope.ResourceID); err != nil {
+			return nil, "", err
+		}
+	}
 
+	accessToken, token, err := NewAccessToken(creator.ID.Hex(), name, validity, scopes...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := s.accessTokens.StoreAccessToken(ctx, accessToken); err != nil {
+		return nil, "", err
+	}
+
+	return accessToken, token, nil
+}
+
+// FindAccessTokens directly calls the repository
+func (s *ServiceImpl) FindAccessTokens(ctx context.Context, userID string) ([]*AccessToken, error) {
+	return s.accessTokens.FindAccessTokensByUser(ctx, userID)
+}
+
+// RevokeAccessToken directly calls the repository
+func (s *ServiceImpl) RevokeAccessToken(ctx context.Context, userID string, tokenID string) (int64, error) {
+	return s.accessTokens.DeleteAccessToken(ctx, userID, tokenID)
+}
+
+// resolveRoles sets the permissions granted by the roles of the user
+func (s *ServiceImpl) resolveRoles(ctx context.Context, user *User) error {
+	user.RolePermissions = []*Permission{}
+	if len(user.Roles) == 0 {
+		return nil
+	}
+
+	roles, err := s.roles.FindRolesByNames(ctx, user.Roles...)
+	if err != nil {
+		return err
+	}
+
+	for _, role := range roles {
+		user.RolePermissions = append(user.RolePermissions, role.Permissions...)
+	}
+
+	return nil
+}
+
+// issueTokens signs a new access token for the session and builds the refresh token from the secret
+func (s *ServiceImpl) issueTokens(user *User, sessionID string, secret string) (*Tokens, error) {
+	claims := &jwt.StandardClaims{
+		Id:        sessionID,
+		IssuedAt:  time.Now().Unix(),
+		ExpiresAt: time.Now().Add(s.accessDuration).Unix(),
+		NotBefore: time.Now().Unix(),
+		Subject:   user.ID.Hex(),
+	}
+
+	ss, err := s.signer.Sign(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tokens{
+		AccessToken:  ss,
+		RefreshToken: formatRefreshToken(sessionID, secret),
+		ExpiresIn:    int64(s.accessDuration.Seconds()),
+	}, nil
+}
+
+// parseAccessToken checks the signature of the token and returns its claims
+func (s *ServiceImpl) parseAccessToken(parser *jwt.Parser, token string) (*jwt.StandardClaims, error) {
+	return s.parseToken(parser, token, "")
+}
+
+// parseToken checks the signature and the audience of the token and returns its claims.
+// The audience keeps the challenges from being used as access tokens, and the other way around
+func (s *ServiceImpl) parseToken(parser *jwt.Parser, token string, audience string) (*jwt.StandardClaims, error) {
+	parsedToken, err := parser.ParseWithClaims(token, &jwt.StandardClaims{}, s.signer.Keyfunc)
 	if err != nil {
 		return nil, err
 	}
@@ -139,9 +733,34 @@ func (s *ServiceImpl) Authenticate(ctx context.Context, token string) (*User, er
 	if !ok {
 		return nil, jwt.NewValidationError("Claims are not standard claims", jwt.ValidationErrorClaimsInvalid)
 	}
-	if err := claims.Valid(); err != nil {
+	if claims.Audience != audience {
+		return nil, jwt.NewValidationError("the token is not meant for this use", jwt.ValidationErrorAudience)
+	}
+	if claims.Id == "" {
+		return nil, jwt.NewValidationError("the token is not bound to a session", jwt.ValidationErrorId)
+	}
+
+	return claims, nil
+}
+
+// issueChallenge signs a short-lived token proving that the user gave the right password
+func (s *ServiceImpl) issueChallenge(user *User) (*Challenge, error) {
+	claims := &jwt.StandardClaims{
+		Audience:  challengeAudience,
+		Id:        primitive.NewObjectID().Hex(),
+		IssuedAt:  time.Now().Unix(),
+		ExpiresAt: time.Now().Add(challengeDuration).Unix(),
+		NotBefore: time.Now().Unix(),
+		Subject:   user.ID.Hex(),
+	}
+
+	ss, err := s.signer.Sign(claims)
+	if err != nil {
 		return nil, err
 	}
 
-	return s.FindByID(ctx, claims.Subject)
+	return &Challenge{
+		Token:     ss,
+		ExpiresIn: int64(challengeDuration.Seconds()),
+	}, nil
 }
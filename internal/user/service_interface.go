@@ -1,10 +1,33 @@
 package user
 
-import context "context"
+import (
+	context "context"
+	io "io"
+	time "time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/attachment"
+)
 
 // LoginService defines the login interface
 type LoginService interface {
-	Login(ctx context.Context, userName string, password string) (*User, string, error)
+	Login(ctx context.Context, userName string, password string) (*User, *Tokens, *Challenge, error)
+	LoginWithIdentity(ctx context.Context, userID string) (*User, *Tokens, *Challenge, error)
+	ChallengedUser(ctx context.Context, challenge string) (*User, error)
+	VerifySecondFactor(ctx context.Context, challenge string, code string) (*User, *Tokens, error)
+}
+
+// TwoFactorService defines the two-factor authentication management
+type TwoFactorService interface {
+	EnrollTOTP(ctx context.Context, userID string) (*TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID string, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, userID string, code string) error
+}
+
+// TokenService defines the session operations of the logged in users
+type TokenService interface {
+	Refresh(ctx context.Context, refreshToken string) (*User, *Tokens, error)
+	Logout(ctx context.Context, accessToken string) error
+	LogoutEverywhere(ctx context.Context, userID string) (int64, error)
 }
 
 // AuthenticateService defines the authenticate interface
@@ -12,24 +35,91 @@ type AuthenticateService interface {
 	Authenticate(ctx context.Context, token string) (*User, error)
 }
 
+// AccessTokenService defines the personal access tokens management
+type AccessTokenService interface {
+	CreateAccessToken(ctx context.Context, creator *User, name string, validity time.Duration, scopes ...*Permission) (*AccessToken, string, error)
+	FindAccessTokens(ctx context.Context, userID string) ([]*AccessToken, error)
+	RevokeAccessToken(ctx context.Context, userID string, tokenID string) (int64, error)
+}
+
+// DirectoryService defines the administration of the users in bulk
+type DirectoryService interface {
+	SearchUsers(ctx context.Context, query *UserQuery) (*UserPage, error)
+	DisableUsers(ctx context.Context, userIDs ...string) (int64, error)
+	EnableUsers(ctx context.Context, userIDs ...string) (int64, error)
+	RequirePasswordReset(ctx context.Context, userIDs ...string) (int64, error)
+}
+
+// RoleService defines the roles management
+type RoleService interface {
+	FindAllRoles(ctx context.Context) ([]*Role, error)
+	FindRoleByName(ctx context.Context, name string) (*Role, error)
+	StoreRole(ctx context.Context, name string, permissions ...*Permission) (*Role, error)
+	UpdateRolePermissions(ctx context.Context, name string, permissions ...*Permission) (int64, error)
+	DeleteRole(ctx context.Context, name string) (int64, error)
+	EnsureRoles(ctx context.Context, roles ...*Role) error
+}
+
 // Service defines the user service
 type Service interface {
 	LoginService
 	AuthenticateService
+	TokenService
+	RoleService
+	TwoFactorService
+	AccessTokenService
+	DirectoryService
 
 	FindByID(ctx context.Context, userID string) (*User, error)
 
 	FindByName(ctx context.Context, userName string) (*User, error)
 
+	FindWithRoles(ctx context.Context, userID string) (*User, error)
+
 	Store(ctx context.Context, name string, password string, permissions ...*Permission) (*User, error)
 
 	UpdateName(ctx context.Context, userID string, newName string) (int64, error)
 
 	UpdatePassword(ctx context.Context, userID string, currentPassword string, newPassword string) (int64, error)
 
+	SetPassword(ctx context.Context, userID string, newPassword string) (int64, error)
+
+	UpdateEmail(ctx context.Context, userID string, newEmail string) (int64, error)
+
 	Delete(ctx context.Context, userID string) (int64, error)
 
-	AddPermissions(ctx context.Context, userID string, permissions ...*Permission) (int64, error)
+	AddPermissions(ctx context.Context, granter *User, userID string, permissions ...*Permission) (int64, error)
 
 	RemovePermissions(ctx context.Context, userID string, permissions ...*Permission) (int64, error)
+
+	RemoveResourcePermissions(ctx context.Context, resourceIDs ...string) (int64, error)
+
+	AddRoles(ctx context.Context, granter *User, userID string, roles ...string) (int64, error)
+
+	RemoveRoles(ctx context.Context, userID string, roles ...string) (int64, error)
+}
+
+// ResetService defines the password reset of the users who forgot their password
+type ResetService interface {
+	RequestPasswordReset(ctx context.Context, userName string, ip string) error
+	ResetPassword(ctx context.Context, token string, newPassword string) (string, error)
+	ForcePasswordReset(ctx context.Context, userIDs ...string) (int64, []string, error)
+}
+
+// ProfileService defines how users present themselves and their preferences, which other features read through FindProfile
+type ProfileService interface {
+	FindProfile(ctx context.Context, userID string) (*Profile, error)
+	UpdateProfile(ctx context.Context, userID string, profile *Profile) (int64, error)
+	UpdateAvatar(ctx context.Context, userID string, filename string, contentType string, content io.Reader) (*attachment.Attachment, error)
+	OpenAvatar(ctx context.Context, userID string) (*attachment.Attachment, io.ReadCloser, error)
+	RemoveAvatar(ctx context.Context, userID string) (int64, error)
+}
+
+// LockoutService defines the brute-force protection of the logins. Failures are counted per account and per address
+type LockoutService interface {
+	CheckLogin(ctx context.Context, userName string, ip string) error
+	RecordLoginFailure(ctx context.Context, userName string, ip string) error
+	RecordLoginSuccess(ctx context.Context, userName string, ip string) error
+	Unlock(ctx context.Context, userName string, unlockedBy string) error
+	FindSecurityEvents(ctx context.Context, limit int64) ([]*SecurityEvent, error)
 }
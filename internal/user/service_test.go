@@ -2,22 +2,31 @@ package user_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/NicolasDutronc/autokey"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
 	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/NicolasDutronc/shoppinglist-be/pkg/keyring"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
-	"golang.org/x/crypto/bcrypt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type UserServiceTestSuite struct {
 	suite.Suite
 	srv      *user.ServiceImpl
 	repo     *user.MockRepository
+	sessions *user.MockSessionRepository
+	roles    *user.MockRoleRepository
+	tokens   *user.MockAccessTokenRepository
 	consumer *autokey.MockConsumer
 	u        *user.User
 }
@@ -31,8 +40,11 @@ func (s *UserServiceTestSuite) SetupTest() {
 		ResourceID: "otherResourceID",
 	})
 	s.repo = &user.MockRepository{}
+	s.sessions = &user.MockSessionRepository{}
+	s.roles = &user.MockRoleRepository{}
+	s.tokens = &user.MockAccessTokenRepository{}
 	s.consumer = &autokey.MockConsumer{}
-	s.srv = user.NewService(s.repo, s.consumer).(*user.ServiceImpl)
+	s.srv = user.NewService(s.repo, s.sessions, s.roles, s.tokens, testHasher, keyring.NewSymmetric(s.consumer), 15*time.Minute, 24*time.Hour).(*user.ServiceImpl)
 }
 
 func (s *UserServiceTestSuite) TestFindByID() {
@@ -73,7 +85,7 @@ func (s *UserServiceTestSuite) TestStore() {
 	user, err := s.srv.Store(ctx, s.u.Name, s.u.Password, s.u.Permissions...)
 	assert.NoError(s.T(), err)
 
-	assert.NoError(s.T(), bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(s.u.Password)))
+	assert.NoError(s.T(), testHasher.Verify(user.Password, s.u.Password))
 }
 
 func (s *UserServiceTestSuite) TestUpdateName() {
@@ -99,7 +111,7 @@ func (s *UserServiceTestSuite) TestUpdatePassword() {
 				return nil
 			}
 
-			encrypted, err := bcrypt.GenerateFromPassword([]byte(s.u.Password), bcrypt.DefaultCost)
+			encrypted, err := testHasher.Hash(s.u.Password)
 			if err != nil {
 				return nil
 			}
@@ -107,7 +119,7 @@ func (s *UserServiceTestSuite) TestUpdatePassword() {
 			return &user.User{
 				BaseModel: s.u.BaseModel,
 				Name:      s.u.Name,
-				Password:  string(encrypted),
+				Password:  encrypted,
 			}
 
 		},
@@ -121,14 +133,14 @@ func (s *UserServiceTestSuite) TestUpdatePassword() {
 	).Times(3)
 	s.repo.On("UpdatePassword", ctx, s.u.ID.Hex(), mock.AnythingOfType("string")).Return(
 		func(_ context.Context, _ string, newPasswordHashed string) int64 {
-			if err := bcrypt.CompareHashAndPassword([]byte(newPasswordHashed), []byte(newPassword)); err != nil {
+			if err := testHasher.Verify(newPasswordHashed, newPassword); err != nil {
 				return -1
 			}
 
 			return 1
 		},
 		func(_ context.Context, _ string, newPasswordHashed string) error {
-			if err := bcrypt.CompareHashAndPassword([]byte(newPasswordHashed), []byte(newPassword)); err != nil {
+			if err := testHasher.Verify(newPasswordHashed, newPassword); err != nil {
 				return err
 			}
 
@@ -170,7 +182,7 @@ func (s *UserServiceTestSuite) TestLogin() {
 				return nil
 			}
 
-			encrypted, err := bcrypt.GenerateFromPassword([]byte(s.u.Password), bcrypt.DefaultCost)
+			encrypted, err := testHasher.Hash(s.u.Password)
 			if err != nil {
 				return nil
 			}
@@ -178,7 +190,7 @@ func (s *UserServiceTestSuite) TestLogin() {
 			return &user.User{
 				BaseModel: s.u.BaseModel,
 				Name:      name,
-				Password:  string(encrypted),
+				Password:  encrypted,
 			}
 		},
 		func(_ context.Context, name string) error {
@@ -193,12 +205,18 @@ func (s *UserServiceTestSuite) TestLogin() {
 	key := "superSecretKey"
 	s.consumer.On("Get").Return(key, nil)
 
-	user, token, err := s.srv.Login(ctx, s.u.Name, s.u.Password)
+	session := &user.Session{BaseModel: common.BaseModel{ID: primitive.NewObjectID()}, UserID: s.u.ID.Hex()}
+	s.sessions.On("StoreSession", ctx, s.u.ID.Hex(), mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(session, nil)
+
+	user, tokens, challenge, err := s.srv.Login(ctx, s.u.Name, s.u.Password)
 	assert.NoError(s.T(), err)
+	assert.Nil(s.T(), challenge)
 	assert.NotNil(s.T(), user)
-	assert.Greater(s.T(), len(token), 0)
+	assert.Greater(s.T(), len(tokens.AccessToken), 0)
+	assert.True(s.T(), strings.HasPrefix(tokens.RefreshToken, session.ID.Hex()+"."))
+	assert.Equal(s.T(), int64(15*60), tokens.ExpiresIn)
 
-	parsedToken, err := jwt.ParseWithClaims(token, &jwt.StandardClaims{}, func(t *jwt.Token) (interface{}, error) {
+	parsedToken, err := jwt.ParseWithClaims(tokens.AccessToken, &jwt.StandardClaims{}, func(t *jwt.Token) (interface{}, error) {
 		if t.Method != jwt.SigningMethodHS512 {
 			return nil, jwt.ErrSignatureInvalid
 		}
@@ -207,6 +225,7 @@ func (s *UserServiceTestSuite) TestLogin() {
 
 	assert.NoError(s.T(), parsedToken.Claims.Valid())
 	assert.IsType(s.T(), &jwt.StandardClaims{}, parsedToken.Claims)
+	assert.Equal(s.T(), session.ID.Hex(), parsedToken.Claims.(*jwt.StandardClaims).Id)
 
 }
 
@@ -215,7 +234,9 @@ func (s *UserServiceTestSuite) TestAuthenticate() {
 
 	// create token
 	key := "superSecretKey"
+	sessionID := primitive.NewObjectID().Hex()
 	claims := &jwt.StandardClaims{
+		Id:        sessionID,
 		IssuedAt:  time.Now().Unix(),
 		ExpiresAt: time.Now().Add(24 * time.Hour).Unix(),
 		NotBefore: time.Now().Unix(),
@@ -231,12 +252,161 @@ func (s *UserServiceTestSuite) TestAuthenticate() {
 	s.consumer.On("Get").Return(key, nil)
 
 	s.repo.On("FindByID", ctx, s.u.ID.Hex()).Return(s.u, nil)
+	s.sessions.On("IsRevoked", ctx, sessionID).Return(false, nil).Once()
+
+	authenticated, err := s.srv.Authenticate(ctx, ss)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), s.u.Name, authenticated.Name)
+
+	// once revoked, the token is rejected
+	s.sessions.On("IsRevoked", ctx, sessionID).Return(true, nil).Once()
+	authenticated, err = s.srv.Authenticate(ctx, ss)
+	assert.Nil(s.T(), authenticated)
+	assert.ErrorIs(s.T(), err, user.ErrSessionRevoked)
+}
+
+func (s *UserServiceTestSuite) TestRefresh() {
+	ctx := context.Background()
+	s.consumer.On("Get").Return("superSecretKey", nil)
+	s.repo.On("FindByID", ctx, s.u.ID.Hex()).Return(s.u, nil)
+
+	var stored *user.Session
+	s.sessions.On("StoreSession", ctx, mock.Anything, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, userID string, hash string, expiresAt time.Time) *user.Session {
+			stored = &user.Session{
+				BaseModel:        common.BaseModel{ID: primitive.NewObjectID()},
+				UserID:           userID,
+				RefreshTokenHash: hash,
+				ExpiresAt:        expiresAt,
+			}
+			return stored
+		},
+		nil,
+	)
+	s.sessions.On("FindSessionByID", ctx, mock.Anything).Return(
+		func(context.Context, string) *user.Session {
+			copied := *stored
+			return &copied
+		},
+		nil,
+	)
+	s.sessions.On("RotateSession", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, _ string, currentHash string, newHash string, _ time.Time) int64 {
+			if currentHash != stored.RefreshTokenHash {
+				return 0
+			}
+			stored.RefreshTokenHash = newHash
+			return 1
+		},
+		nil,
+	)
+	s.sessions.On("RevokeSession", ctx, mock.Anything).Return(int64(1), nil)
+
+	s.repo.On("FindByName", ctx, s.u.Name).Return(s.hashedUser(), nil)
+	_, tokens, _, err := s.srv.Login(ctx, s.u.Name, s.u.Password)
+	assert.NoError(s.T(), err)
+
+	_, refreshed, err := s.srv.Refresh(ctx, tokens.RefreshToken)
+	assert.NoError(s.T(), err)
+	assert.NotEqual(s.T(), tokens.RefreshToken, refreshed.RefreshToken)
+	s.sessions.AssertNotCalled(s.T(), "RevokeSession", ctx, mock.Anything)
+
+	// reusing the first refresh token revokes the session
+	_, _, err = s.srv.Refresh(ctx, tokens.RefreshToken)
+	assert.ErrorIs(s.T(), err, user.ErrInvalidRefreshToken)
+	s.sessions.AssertCalled(s.T(), "RevokeSession", ctx, stored.ID.Hex())
+
+	_, _, err = s.srv.Refresh(ctx, "malformed")
+	assert.ErrorIs(s.T(), err, user.ErrInvalidRefreshToken)
+}
+
+// hashedUser returns the test user with its password hashed as it is stored
+func (s *UserServiceTestSuite) hashedUser() *user.User {
+	encrypted, err := testHasher.Hash(s.u.Password)
+	if err != nil {
+		s.FailNow(err.Error())
+	}
+
+	return &user.User{
+		BaseModel: s.u.BaseModel,
+		Name:      s.u.Name,
+		Password:  encrypted,
+	}
+}
+
+func (s *UserServiceTestSuite) TestAuthenticateResolvesRoles() {
+	ctx := context.Background()
+	key := "superSecretKey"
+	sessionID := primitive.NewObjectID().Hex()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS512, &jwt.StandardClaims{
+		Id:        sessionID,
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		Subject:   s.u.ID.Hex(),
+	}).SignedString([]byte(key))
+	if err != nil {
+		s.FailNow(err.Error())
+	}
+
+	s.u.Roles = []string{"viewer", "deleted role"}
+	s.consumer.On("Get").Return(key, nil)
+	s.sessions.On("IsRevoked", ctx, sessionID).Return(false, nil)
+	s.repo.On("FindByID", ctx, s.u.ID.Hex()).Return(s.u, nil)
+	s.roles.On("FindRolesByNames", ctx, "viewer", "deleted role").Return(user.DefaultRoles()[:1], nil)
 
-	user, err := s.srv.Authenticate(ctx, ss)
+	authenticated, err := s.srv.Authenticate(ctx, token)
 	assert.NoError(s.T(), err)
-	assert.Equal(s.T(), s.u.Name, user.Name)
+	assert.NoError(s.T(), authenticated.Can("read", "list-1234"))
+	assert.Error(s.T(), authenticated.Can("write", "list-1234"))
+}
+
+func TestPermissionAllows(t *testing.T) {
+	cases := []struct {
+		permission *user.Permission
+		action     string
+		resourceID string
+		allowed    bool
+	}{
+		{&user.Permission{ResourceID: "*", Action: "*"}, "write", "list-1", true},
+		{&user.Permission{ResourceID: "list-1", Action: "read"}, "read", "list-1", true},
+		{&user.Permission{ResourceID: "list-1", Action: "read"}, "read", "list-2", false},
+		{&user.Permission{ResourceID: "list-*", Action: "read"}, "read", "list-2", true},
+		{&user.Permission{ResourceID: "list-*", Action: "read"}, "read", "filter-2", false},
+		{&user.Permission{ResourceID: "list-*", Action: "read|delete"}, "delete", "list-2", true},
+		{&user.Permission{ResourceID: "list-*", Action: "read|delete"}, "write", "list-2", false},
+		// write implies read but not the other way around
+		{&user.Permission{ResourceID: "list-1", Action: "write"}, "read", "list-1", true},
+		{&user.Permission{ResourceID: "list-1", Action: "read"}, "write", "list-1", false},
+		{&user.Permission{ResourceID: "list-[", Action: "read"}, "read", "list-[", false},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.allowed, c.permission.Allows(c.action, c.resourceID), "%+v %s %s", c.permission, c.action, c.resourceID)
+	}
 }
 
 func TestUserServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(UserServiceTestSuite))
 }
+
+func (s *UserServiceTestSuite) TestSecondFactorUsedConcurrently() {
+	ctx := context.Background()
+	secret := []byte("12345678901234567890")
+	recoveryCodeHash := sha256.Sum256([]byte("abcdefgh"))
+	s.u.TOTP = &user.TOTP{
+		Secret:             base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret),
+		Enabled:            true,
+		RecoveryCodeHashes: []string{hex.EncodeToString(recoveryCodeHash[:])},
+	}
+	s.repo.On("FindByID", ctx, s.u.ID.Hex()).Return(s.u, nil)
+
+	// another login used the codes between the read and the write
+	s.repo.On("UseTOTPStep", ctx, s.u.ID.Hex(), mock.AnythingOfType("int64")).Return(int64(0), nil)
+	s.repo.On("UseRecoveryCode", ctx, s.u.ID.Hex(), hex.EncodeToString(recoveryCodeHash[:])).Return(int64(0), nil)
+
+	err := s.srv.DisableTOTP(ctx, s.u.ID.Hex(), user.TOTPCode(secret, time.Now().Unix()/30))
+	assert.ErrorIs(s.T(), err, user.ErrInvalidSecondFactor)
+	err = s.srv.DisableTOTP(ctx, s.u.ID.Hex(), "ABCD-EFGH")
+	assert.ErrorIs(s.T(), err, user.ErrInvalidSecondFactor)
+
+	s.repo.AssertNotCalled(s.T(), "UpdateTOTP", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,77 @@
+package user
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+)
+
+// ErrInvalidRefreshToken is returned when a refresh token is malformed, unknown, expired or revoked
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+// ErrSessionRevoked is returned when authenticating with an access token whose session was revoked
+var ErrSessionRevoked = errors.New("the session was revoked")
+
+// Session is a login of a user. It holds the hash of the current refresh token and is referenced by the access tokens it issued
+type Session struct {
+	common.BaseModel `bson:",inline"`
+	UserID           string     `bson:"user_id" json:"user_id"`
+	RefreshTokenHash string     `bson:"refresh_token_hash" json:"-"`
+	ExpiresAt        time.Time  `bson:"expires_at" json:"expires_at"`
+	RevokedAt        *time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+}
+
+// IsActive returns true if the session is neither expired nor revoked
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// Tokens are the credentials returned to a client on login and refresh
+type Tokens struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// newSecret returns a random secret and its hash. Only the hash is stored
+func newSecret() (string, string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(secret)
+	return encoded, hashSecret(encoded), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// formatRefreshToken builds the refresh token given to the client from the session id and the secret
+func formatRefreshToken(sessionID string, secret string) string {
+	return sessionID + "." + secret
+}
+
+// parseRefreshToken splits a refresh token into the session id and the secret
+func parseRefreshToken(token string) (string, string, error) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", ErrInvalidRefreshToken
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// matchesRefreshSecret compares the secret with the hash stored in the session in constant time
+func (s *Session) matchesRefreshSecret(secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(s.RefreshTokenHash), []byte(hashSecret(secret))) == 1
+}
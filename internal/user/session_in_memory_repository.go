@@ -0,0 +1,123 @@
+package user
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InMemorySessionRepository is an in-memory session repository
+type InMemorySessionRepository struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// NewInMemorySessionRepository inits a new in-memory session repository
+func NewInMemorySessionRepository() SessionRepository {
+	return &InMemorySessionRepository{
+		sessions: make(map[string]*Session),
+	}
+}
+
+// FindSessionByID returns the session with the given id
+func (r *InMemorySessionRepository) FindSessionByID(ctx context.Context, sessionID string) (*Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, exists := r.sessions[sessionID]
+	if !exists {
+		return nil, common.NewError(common.ErrNotFound, "Session not found for id %s", sessionID)
+	}
+
+	copied := *session
+	return &copied, nil
+}
+
+// StoreSession creates a new session
+func (r *InMemorySessionRepository) StoreSession(ctx context.Context, userID string, refreshTokenHash string, expiresAt time.Time) (*Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session := &Session{
+		BaseModel: common.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+		UserID:           userID,
+		RefreshTokenHash: refreshTokenHash,
+		ExpiresAt:        expiresAt,
+	}
+	r.sessions[session.ID.Hex()] = session
+
+	copied := *session
+	return &copied, nil
+}
+
+// RotateSession replaces the refresh token hash of an active session if the current one matches
+func (r *InMemorySessionRepository) RotateSession(ctx context.Context, sessionID string, currentHash string, newHash string, expiresAt time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, exists := r.sessions[sessionID]
+	if !exists || session.RevokedAt != nil || session.RefreshTokenHash != currentHash {
+		return 0, nil
+	}
+
+	session.RefreshTokenHash = newHash
+	session.ExpiresAt = expiresAt
+	session.UpdatedAt = time.Now()
+
+	return 1, nil
+}
+
+// RevokeSession marks a session as revoked
+func (r *InMemorySessionRepository) RevokeSession(ctx context.Context, sessionID string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, exists := r.sessions[sessionID]
+	if !exists || session.RevokedAt != nil {
+		return 0, nil
+	}
+
+	now := time.Now()
+	session.RevokedAt = &now
+	session.UpdatedAt = now
+
+	return 1, nil
+}
+
+// RevokeAllSessions marks all the sessions of a user as revoked
+func (r *InMemorySessionRepository) RevokeAllSessions(ctx context.Context, userID string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+			session.UpdatedAt = now
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// IsRevoked returns true if the session is revoked, expired or does not exist
+func (r *InMemorySessionRepository) IsRevoked(ctx context.Context, sessionID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, exists := r.sessions[sessionID]
+	if !exists {
+		return true, nil
+	}
+
+	return !session.IsActive(), nil
+}
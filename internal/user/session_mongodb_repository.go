@@ -0,0 +1,140 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoDBSessionRepository is a session repository based on mongodb
+type MongoDBSessionRepository struct {
+	SessionCollection *mongo.Collection
+}
+
+// NewMongoDBSessionRepository inits a new mongodb session repository
+func NewMongoDBSessionRepository(collection *mongo.Collection) SessionRepository {
+	return &MongoDBSessionRepository{
+		SessionCollection: collection,
+	}
+}
+
+// FindSessionByID returns the session with the given id
+func (r *MongoDBSessionRepository) FindSessionByID(ctx context.Context, sessionID string) (*Session, error) {
+	objectID, err := common.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var session Session
+	if err := r.SessionCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&session); err != nil {
+		return nil, common.MongoError(err, "session %v", sessionID)
+	}
+
+	return &session, nil
+}
+
+// StoreSession creates a new session
+func (r *MongoDBSessionRepository) StoreSession(ctx context.Context, userID string, refreshTokenHash string, expiresAt time.Time) (*Session, error) {
+	session := &Session{
+		BaseModel: common.BaseModel{
+			ID:        primitive.NewObjectID(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+		UserID:           userID,
+		RefreshTokenHash: refreshTokenHash,
+		ExpiresAt:        expiresAt,
+	}
+
+	if _, err := r.SessionCollection.InsertOne(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// RotateSession replaces the refresh token hash of an active session if the current one matches
+func (r *MongoDBSessionRepository) RotateSession(ctx context.Context, sessionID string, currentHash string, newHash string, expiresAt time.Time) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.SessionCollection.UpdateOne(
+		ctx,
+		bson.M{
+			"_id":                objectID,
+			"refresh_token_hash": currentHash,
+			"revoked_at":         bson.M{"$exists": false},
+		},
+		bson.M{
+			"$set": bson.M{
+				"refresh_token_hash": newHash,
+				"expires_at":         expiresAt,
+				"updated_at":         time.Now(),
+			},
+		},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// RevokeSession marks a session as revoked
+func (r *MongoDBSessionRepository) RevokeSession(ctx context.Context, sessionID string) (int64, error) {
+	objectID, err := common.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := r.SessionCollection.UpdateOne(
+		ctx,
+		bson.M{
+			"_id":        objectID,
+			"revoked_at": bson.M{"$exists": false},
+		},
+		bson.M{"$set": bson.M{"revoked_at": time.Now(), "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// RevokeAllSessions marks all the sessions of a user as revoked
+func (r *MongoDBSessionRepository) RevokeAllSessions(ctx context.Context, userID string) (int64, error) {
+	result, err := r.SessionCollection.UpdateMany(
+		ctx,
+		bson.M{
+			"user_id":    userID,
+			"revoked_at": bson.M{"$exists": false},
+		},
+		bson.M{"$set": bson.M{"revoked_at": time.Now(), "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// IsRevoked returns true if the session is revoked, expired or does not exist anymore
+func (r *MongoDBSessionRepository) IsRevoked(ctx context.Context, sessionID string) (bool, error) {
+	session, err := r.FindSessionByID(ctx, sessionID)
+	if errors.Is(err, common.ErrNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return !session.IsActive(), nil
+}
@@ -0,0 +1,172 @@
+package user
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/common"
+)
+
+const (
+	// totpIssuer is the name of the account issuer displayed by authenticator apps
+	totpIssuer = "Shoplist"
+	// totpPeriod is the validity of a code, as recommended by RFC 6238
+	totpPeriod = 30 * time.Second
+	// totpSkew is the number of periods before and after the current one whose codes are accepted to tolerate clock drifts
+	totpSkew = 1
+	// totpDigits is the length of the codes
+	totpDigits = 6
+	// recoveryCodesCount is the number of recovery codes given on enrollment
+	recoveryCodesCount = 10
+	// challengeDuration is the time given to enter the code after the password
+	challengeDuration = 5 * time.Minute
+	// challengeAudience is the audience of the challenge tokens which distinguishes them from access tokens
+	challengeAudience = "second-factor"
+)
+
+var (
+	// ErrTOTPAlreadyEnabled is returned when enrolling a user who already enabled two-factor authentication
+	ErrTOTPAlreadyEnabled = common.NewError(common.ErrConflict, "two-factor authentication is already enabled")
+	// ErrTOTPNotEnrolled is returned when confirming or disabling two-factor authentication before enrolling
+	ErrTOTPNotEnrolled = common.NewError(common.ErrConflict, "two-factor authentication was not enrolled")
+	// ErrInvalidSecondFactor is returned when a TOTP or recovery code is wrong, or the challenge is invalid or expired
+	ErrInvalidSecondFactor = errors.New("invalid second factor")
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTP holds the RFC 6238 two-factor authentication settings of a user
+type TOTP struct {
+	Secret             string    `bson:"secret"`
+	Enabled            bool      `bson:"enabled"`
+	EnabledAt          time.Time `bson:"enabled_at,omitempty"`
+	RecoveryCodeHashes []string  `bson:"recovery_code_hashes"`
+	// LastUsedStep is the period of the last accepted code so that a code cannot be replayed
+	LastUsedStep int64 `bson:"last_used_step"`
+}
+
+// TOTPEnrollment is given to the user to register the secret in an authenticator app
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// Challenge is returned on login instead of tokens when the user has to give a second factor
+type Challenge struct {
+	Token     string `json:"challenge"`
+	ExpiresIn int64  `json:"expires_in"`
+}
+
+// newTOTP generates a new secret. The settings are not enabled until a first code is verified
+func newTOTP() (*TOTP, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	return &TOTP{
+		Secret:             totpEncoding.EncodeToString(secret),
+		RecoveryCodeHashes: []string{},
+	}, nil
+}
+
+// Enrollment returns the secret along with the otpauth URI authenticator apps read from QR codes
+func (t *TOTP) Enrollment(accountName string) *TOTPEnrollment {
+	label := url.PathEscape(fmt.Sprintf("%s:%s", totpIssuer, accountName))
+	query := url.Values{}
+	query.Set("secret", t.Secret)
+	query.Set("issuer", totpIssuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(int(totpPeriod.Seconds())))
+
+	return &TOTPEnrollment{
+		Secret: t.Secret,
+		URI:    fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode()),
+	}
+}
+
+// verifyCode checks the code against the periods around now and returns the matching period.
+// Codes of the last used period or before are rejected so that each code can only be used once
+func (t *TOTP) verifyCode(code string, now time.Time) (int64, bool) {
+	secret, err := totpEncoding.DecodeString(t.Secret)
+	if err != nil {
+		return 0, false
+	}
+
+	current := now.Unix() / int64(totpPeriod.Seconds())
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= t.LastUsedStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(TOTPCode(secret, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// matchRecoveryCode returns the hash of the recovery code if it is one of the remaining ones. It returns false if the code is unknown
+func (t *TOTP) matchRecoveryCode(code string) (string, bool) {
+	hash := hashSecret(normalizeRecoveryCode(code))
+	for _, recoveryCodeHash := range t.RecoveryCodeHashes {
+		if subtle.ConstantTimeCompare([]byte(recoveryCodeHash), []byte(hash)) == 1 {
+			return recoveryCodeHash, true
+		}
+	}
+
+	return "", false
+}
+
+// TOTPCode computes the RFC 6238 code of the secret for a period
+func TOTPCode(secret []byte, step int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		modulo *= 10
+	}
+
+	return fmt.Sprintf("%0*d", totpDigits, value%modulo)
+}
+
+// newRecoveryCodes returns recovery codes along with their hashes. Only the hashes are stored
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodesCount)
+	hashes := make([]string, recoveryCodesCount)
+	for i := range codes {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+
+		encoded := strings.ToLower(totpEncoding.EncodeToString(raw))
+		codes[i] = encoded[:4] + "-" + encoded[4:]
+		hashes[i] = hashSecret(normalizeRecoveryCode(codes[i]))
+	}
+
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode ignores the case and the dashes of a recovery code
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
@@ -0,0 +1,95 @@
+package user_test
+
+import (
+	"context"
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/NicolasDutronc/autokey"
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/NicolasDutronc/shoppinglist-be/pkg/keyring"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// test vectors of RFC 6238 for SHA1, truncated to 6 digits
+	secret := []byte("12345678901234567890")
+	cases := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for at, expected := range cases {
+		assert.Equal(t, expected, user.TOTPCode(secret, at/30), at)
+	}
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	ctx := context.Background()
+	consumer := &autokey.MockConsumer{}
+	consumer.On("Get").Return("superSecretKey", nil)
+	srv := user.NewService(user.NewInMemoryRepository(), user.NewInMemorySessionRepository(), user.NewInMemoryRoleRepository(), user.NewInMemoryAccessTokenRepository(), testHasher, keyring.NewSymmetric(consumer), time.Minute, time.Hour)
+
+	alice, err := srv.Store(ctx, "alice", "password")
+	assert.NoError(t, err)
+
+	enrollment, err := srv.EnrollTOTP(ctx, alice.ID.Hex())
+	assert.NoError(t, err)
+	uri, err := url.Parse(enrollment.URI)
+	assert.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, enrollment.Secret, uri.Query().Get("secret"))
+
+	// login stays one step until a code is confirmed
+	_, tokens, challenge, err := srv.Login(ctx, "alice", "password")
+	assert.NoError(t, err)
+	assert.NotNil(t, tokens)
+	assert.Nil(t, challenge)
+
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+	assert.NoError(t, err)
+	code := user.TOTPCode(secret, time.Now().Unix()/30)
+
+	_, err = srv.ConfirmTOTP(ctx, alice.ID.Hex(), "000000")
+	assert.ErrorIs(t, err, user.ErrInvalidSecondFactor)
+	recoveryCodes, err := srv.ConfirmTOTP(ctx, alice.ID.Hex(), code)
+	assert.NoError(t, err)
+	assert.Len(t, recoveryCodes, 10)
+
+	_, tokens, challenge, err = srv.Login(ctx, "alice", "password")
+	assert.NoError(t, err)
+	assert.Nil(t, tokens)
+	assert.NotNil(t, challenge)
+
+	// the challenge is not an access token
+	_, err = srv.Authenticate(ctx, challenge.Token)
+	assert.Error(t, err)
+
+	challenged, err := srv.ChallengedUser(ctx, challenge.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, alice.ID, challenged.ID)
+	_, err = srv.ChallengedUser(ctx, "forged")
+	assert.ErrorIs(t, err, user.ErrInvalidSecondFactor)
+
+	// the code used to confirm cannot be replayed
+	_, _, err = srv.VerifySecondFactor(ctx, challenge.Token, code)
+	assert.ErrorIs(t, err, user.ErrInvalidSecondFactor)
+
+	// a recovery code only works once
+	_, tokens, err = srv.VerifySecondFactor(ctx, challenge.Token, recoveryCodes[0])
+	assert.NoError(t, err)
+	_, err = srv.Authenticate(ctx, tokens.AccessToken)
+	assert.NoError(t, err)
+	_, _, err = srv.VerifySecondFactor(ctx, challenge.Token, recoveryCodes[0])
+	assert.ErrorIs(t, err, user.ErrInvalidSecondFactor)
+
+	assert.NoError(t, srv.DisableTOTP(ctx, alice.ID.Hex(), recoveryCodes[1]))
+	_, tokens, challenge, err = srv.Login(ctx, "alice", "password")
+	assert.NoError(t, err)
+	assert.NotNil(t, tokens)
+	assert.Nil(t, challenge)
+}
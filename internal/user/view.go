@@ -0,0 +1,68 @@
+package user
+
+import "time"
+
+// Visibility is the level of detail a viewer has on a user
+type Visibility int
+
+const (
+	// VisibilityPublic only shows the profile of the user
+	VisibilityPublic Visibility = iota
+	// VisibilityPrivate also shows the email address, the preferences, the permissions and the roles of the user
+	VisibilityPrivate
+)
+
+// View is the representation of a user sent to clients. The password hash is never part of it
+type View struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Avatar      string `json:"avatar,omitempty"`
+
+	Email       string        `json:"email,omitempty"`
+	Preferences *Profile      `json:"preferences,omitempty"`
+	Permissions []*Permission `json:"permissions,omitempty"`
+	Roles       []string      `json:"roles,omitempty"`
+	TwoFactor   bool          `json:"two_factor,omitempty"`
+	Disabled    bool          `json:"disabled,omitempty"`
+	ResetNeeded bool          `json:"password_reset_required,omitempty"`
+	CreatedAt   *time.Time    `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time    `json:"updated_at,omitempty"`
+}
+
+// VisibilityFor returns the visibility the viewer has on the user. Users see their own details, and so do the users allowed to read permissions
+func (u *User) VisibilityFor(viewer *User) Visibility {
+	if viewer == nil {
+		return VisibilityPublic
+	}
+
+	if viewer.ID == u.ID || viewer.Can("read", "permissions") == nil {
+		return VisibilityPrivate
+	}
+
+	return VisibilityPublic
+}
+
+// View projects the user with the given visibility
+func (u *User) View(visibility Visibility) *View {
+	view := &View{
+		ID:          u.ID.Hex(),
+		Name:        u.Name,
+		DisplayName: u.DisplayedName(),
+		Avatar:      u.Avatar,
+	}
+
+	if visibility == VisibilityPrivate {
+		view.Email = u.Email
+		view.Preferences = u.Preferences()
+		view.Permissions = u.Permissions
+		view.Roles = u.Roles
+		view.TwoFactor = u.HasTwoFactor()
+		view.Disabled = u.Disabled
+		view.ResetNeeded = u.PasswordResetRequired
+		view.CreatedAt = &u.CreatedAt
+		view.UpdatedAt = &u.UpdatedAt
+	}
+
+	return view
+}
@@ -0,0 +1,36 @@
+package user_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/NicolasDutronc/shoppinglist-be/internal/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserView(t *testing.T) {
+	alice := user.NewUser("alice", "hash", &user.Permission{ResourceID: "list-1", Action: "write"})
+	alice.Avatar = "https://example.org/alice.png"
+	bob := user.NewUser("bob", "hash")
+	admin := user.NewUser("admin", "hash", &user.Permission{ResourceID: "*", Action: "*"})
+
+	assert.Equal(t, user.VisibilityPrivate, alice.VisibilityFor(alice))
+	assert.Equal(t, user.VisibilityPrivate, alice.VisibilityFor(admin))
+	assert.Equal(t, user.VisibilityPublic, alice.VisibilityFor(bob))
+	assert.Equal(t, user.VisibilityPublic, alice.VisibilityFor(nil))
+
+	public := alice.View(user.VisibilityPublic)
+	assert.Equal(t, &user.View{ID: alice.ID.Hex(), Name: "alice", DisplayName: "alice", Avatar: "https://example.org/alice.png"}, public)
+
+	private := alice.View(user.VisibilityPrivate)
+	assert.Equal(t, alice.Permissions, private.Permissions)
+	assert.Equal(t, user.DefaultProfile(), private.Preferences)
+
+	// the hash never leaves the server, even when the user itself is serialized
+	for _, v := range []interface{}{public, private, alice} {
+		body, err := json.Marshal(v)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(body), "hash")
+		assert.NotContains(t, string(body), "password")
+	}
+}
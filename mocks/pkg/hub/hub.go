@@ -56,6 +56,20 @@ func (_m *Hub) DeleteTopic(ctx context.Context, topic hub.Topic) error {
 	return r0
 }
 
+// DisconnectIdentity provides a mock function with given fields: ctx, identityID
+func (_m *Hub) DisconnectIdentity(ctx context.Context, identityID string) error {
+	ret := _m.Called(ctx, identityID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, identityID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetProcessor provides a mock function with given fields: ctx, processorID
 func (_m *Hub) GetProcessor(ctx context.Context, processorID string) (hub.Processor, error) {
 	ret := _m.Called(ctx, processorID)
@@ -144,6 +158,20 @@ func (_m *Hub) RegisterProcessor(ctx context.Context, p hub.Processor) error {
 	return r0
 }
 
+// RegisterSubscriptionHook provides a mock function with given fields: ctx, hook
+func (_m *Hub) RegisterSubscriptionHook(ctx context.Context, hook hub.SubscriptionHook) error {
+	ret := _m.Called(ctx, hook)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, hub.SubscriptionHook) error); ok {
+		r0 = rf(ctx, hook)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Run provides a mock function with given fields: ctx, interrupt
 func (_m *Hub) Run(ctx context.Context, interrupt chan struct{}) error {
 	ret := _m.Called(ctx, interrupt)
@@ -200,6 +228,20 @@ func (_m *Hub) UnregisterProcessor(ctx context.Context, p hub.Processor) error {
 	return r0
 }
 
+// UnregisterSubscriptionHook provides a mock function with given fields: ctx, hook
+func (_m *Hub) UnregisterSubscriptionHook(ctx context.Context, hook hub.SubscriptionHook) error {
+	ret := _m.Called(ctx, hook)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, hub.SubscriptionHook) error); ok {
+		r0 = rf(ctx, hook)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Unsubscribe provides a mock function with given fields: ctx, p, topic
 func (_m *Hub) Unsubscribe(ctx context.Context, p hub.Processor, topic hub.Topic) error {
 	ret := _m.Called(ctx, p, topic)
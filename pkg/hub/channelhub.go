@@ -15,6 +15,7 @@ type ChannelHub struct {
 	unregister  chan Processor
 	subscribe   chan *SubscriptionMessage
 	unsubscribe chan *UnsubscriptionMessage
+	disconnect  chan string
 	topics      chan *topicOpMessage
 	done        chan struct{}
 }
@@ -28,6 +29,7 @@ func NewChannelHub(ctx context.Context, storage *Storage, topics ...Topic) (Hub,
 		unregister:  make(chan Processor),
 		subscribe:   make(chan *SubscriptionMessage),
 		unsubscribe: make(chan *UnsubscriptionMessage),
+		disconnect:  make(chan string),
 		topics:      make(chan *topicOpMessage),
 		done:        make(chan struct{}),
 	}
@@ -71,6 +73,13 @@ func (h *ChannelHub) UnregisterProcessor(ctx context.Context, p Processor) error
 	return nil
 }
 
+// DisconnectIdentity closes the processors bound to the identity. The processors unregister themselves once closed
+func (h *ChannelHub) DisconnectIdentity(ctx context.Context, identityID string) error {
+	h.disconnect <- identityID
+
+	return nil
+}
+
 // GetTopics returns the topics of this hub
 func (h *ChannelHub) GetTopics(ctx context.Context) ([]Topic, error) {
 	return h.state.ListTopics(ctx)
@@ -185,6 +194,16 @@ func (h *ChannelHub) UnregisterMessageHook(ctx context.Context, hook Processor)
 	return h.state.UnregisterMessageHook(ctx, hook)
 }
 
+// RegisterSubscriptionHook adds a hook called each time a processor subscribes to or leaves a topic
+func (h *ChannelHub) RegisterSubscriptionHook(ctx context.Context, hook SubscriptionHook) error {
+	return h.state.RegisterSubscriptionHook(ctx, hook)
+}
+
+// UnregisterSubscriptionHook removes a subscription hook
+func (h *ChannelHub) UnregisterSubscriptionHook(ctx context.Context, hook SubscriptionHook) error {
+	return h.state.UnregisterSubscriptionHook(ctx, hook)
+}
+
 // Close safely stops the hub by unregistering all processors
 func (h *ChannelHub) Close(ctx context.Context) error {
 	processors, err := h.state.ListProcessors(ctx)
@@ -228,6 +247,7 @@ func (h *ChannelHub) Run(ctx context.Context, interrupt chan struct{}) error {
 					if err := h.state.Unsubscribe(ctx, processor, topicOp.topic); err != nil {
 						return err
 					}
+					h.onUnsubscription(ctx, processor, topicOp.topic)
 				}
 
 				if err := h.state.DeleteTopic(ctx, topicOp.topic); err != nil {
@@ -255,6 +275,7 @@ func (h *ChannelHub) Run(ctx context.Context, interrupt chan struct{}) error {
 					if err := h.state.Unsubscribe(ctx, unregistration, topic); err != nil {
 						return err
 					}
+					h.onUnsubscription(ctx, unregistration, topic)
 				}
 			}
 			if err := h.state.UnregisterProcessor(ctx, unregistration); err != nil {
@@ -263,12 +284,28 @@ func (h *ChannelHub) Run(ctx context.Context, interrupt chan struct{}) error {
 			close(unregistration.GetMsgChannel())
 			log.Printf("Unregistered processor %v", unregistration.GetID())
 
+		case identityID := <-h.disconnect:
+			for _, processor := range h.state.Processors {
+				identified, ok := processor.(Identified)
+				if !ok || identified.GetIdentity() == nil || identified.GetIdentity().ID != identityID {
+					continue
+				}
+				disconnectable, ok := processor.(Disconnectable)
+				if !ok {
+					continue
+				}
+				// the processor unregisters itself through the hub, which cannot wait for it here
+				go disconnectable.Disconnect()
+				log.Printf("Disconnecting processor %v", processor.GetID())
+			}
+
 		case subscription := <-h.subscribe:
 			if err := h.state.Subscribe(ctx, subscription.Processor, subscription.Topic); err != nil {
 				log.Printf("error in subscription : %v", err)
 				return err
 			}
 			log.Printf("Processor %v successfully subscribed to topic %v", subscription.Processor.GetID(), subscription.Topic)
+			h.onSubscription(ctx, subscription.Processor, subscription.Topic)
 
 			h.broadcast <- &payload{
 				ID:    subscription.GetID(),
@@ -282,6 +319,7 @@ func (h *ChannelHub) Run(ctx context.Context, interrupt chan struct{}) error {
 				return err
 			}
 			log.Printf("Processor %v unsubscribed from topic %v", unsubscription.Processor.GetID(), unsubscription.Topic)
+			h.onUnsubscription(ctx, unsubscription.Processor, unsubscription.Topic)
 
 			h.broadcast <- &payload{
 				ID:    unsubscription.GetID(),
@@ -309,3 +347,25 @@ func (h *ChannelHub) Run(ctx context.Context, interrupt chan struct{}) error {
 		}
 	}
 }
+
+// onSubscription calls the subscription hooks. A failing hook does not stop the hub
+func (h *ChannelHub) onSubscription(ctx context.Context, p Processor, topic Topic) {
+	for hook := range h.state.SubscriptionHooks {
+		if err := hook.OnSubscription(ctx, p, topic); err != nil {
+			log.Printf("error in subscription hook : %v", err)
+		}
+	}
+}
+
+// onUnsubscription calls the subscription hooks that are also unsubscription hooks. A failing hook does not stop the hub
+func (h *ChannelHub) onUnsubscription(ctx context.Context, p Processor, topic Topic) {
+	for hook := range h.state.SubscriptionHooks {
+		unsubscriptionHook, ok := hook.(UnsubscriptionHook)
+		if !ok {
+			continue
+		}
+		if err := unsubscriptionHook.OnUnsubscription(ctx, p, topic); err != nil {
+			log.Printf("error in unsubscription hook : %v", err)
+		}
+	}
+}
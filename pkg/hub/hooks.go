@@ -10,6 +10,14 @@ type TopicDeletionHook interface {
 	OnTopicDeletion(ctx context.Context, topic Topic) error
 }
 
+// SubscriptionHook is called by the hub each time a processor subscribes to a topic.
+// Hooks are called from the hub loop so they must not call the hub synchronously
 type SubscriptionHook interface {
 	OnSubscription(ctx context.Context, processor Processor, topic Topic) error
 }
+
+// UnsubscriptionHook is called by the hub each time a processor leaves a topic, either explicitly, by being unregistered or because the topic is deleted.
+// A subscription hook also implementing this interface is called on unsubscriptions
+type UnsubscriptionHook interface {
+	OnUnsubscription(ctx context.Context, processor Processor, topic Topic) error
+}
@@ -2,6 +2,7 @@ package hub
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -44,6 +45,7 @@ type Hub interface {
 	GetProcessor(ctx context.Context, processorID string) (Processor, error)
 	RegisterProcessor(ctx context.Context, p Processor) error
 	UnregisterProcessor(ctx context.Context, p Processor) error
+	DisconnectIdentity(ctx context.Context, identityID string) error
 
 	GetTopics(ctx context.Context) ([]Topic, error)
 	AddTopic(ctx context.Context, topic Topic) error
@@ -55,13 +57,42 @@ type Hub interface {
 
 	RegisterMessageHook(ctx context.Context, hook Processor) error
 	UnregisterMessageHook(ctx context.Context, hook Processor) error
+	RegisterSubscriptionHook(ctx context.Context, hook SubscriptionHook) error
+	UnregisterSubscriptionHook(ctx context.Context, hook SubscriptionHook) error
 
 	Run(ctx context.Context, interrupt chan struct{}) error
 	Close(ctx context.Context) error
 }
 
-// SubscriptionHandler is a gin http handler for topic subscription
-func SubscriptionHandler(h Hub) gin.HandlerFunc {
+// ErrNotProcessorOwner is returned when someone tries to (un)subscribe a processor bound to another identity
+var ErrNotProcessorOwner = errors.New("the processor belongs to someone else")
+
+// Authorizer returns an error if the identity is not allowed to follow the topic. The identity is nil for an anonymous processor
+type Authorizer func(ctx context.Context, identity *Identity, topic Topic) error
+
+// checkOwner returns ErrNotProcessorOwner if the processor is not bound to the identity of the client.
+// Without identifier, the processors are anonymous and anyone can use them
+func checkOwner(c *gin.Context, identify Identifier, p Processor) error {
+	if identify == nil {
+		return nil
+	}
+
+	identity, err := identify(c)
+	if err != nil {
+		return err
+	}
+
+	owner := identityOf(p)
+	if owner == nil || identity == nil || owner.ID != identity.ID {
+		return ErrNotProcessorOwner
+	}
+
+	return nil
+}
+
+// SubscriptionHandler is a gin http handler for topic subscription.
+// The processor must be bound to the identity of the client, which the authorizer must allow to follow the topic. A nil authorizer allows every topic
+func SubscriptionHandler(h Hub, identify Identifier, authorize Authorizer) gin.HandlerFunc {
 	type request struct {
 		Topic       string `json:"topic"`
 		ProcessorID string `json:"processor"`
@@ -79,7 +110,20 @@ func SubscriptionHandler(h Hub) gin.HandlerFunc {
 			return
 		}
 
-		if err := h.Subscribe(c.Request.Context(), p, TopicFromString(req.Topic)); err != nil {
+		if err := checkOwner(c, identify, p); err != nil {
+			c.AbortWithError(http.StatusForbidden, err)
+			return
+		}
+
+		topic := TopicFromString(req.Topic)
+		if authorize != nil {
+			if err := authorize(c.Request.Context(), identityOf(p), topic); err != nil {
+				c.AbortWithError(http.StatusForbidden, err)
+				return
+			}
+		}
+
+		if err := h.Subscribe(c.Request.Context(), p, topic); err != nil {
 			c.AbortWithError(http.StatusInternalServerError, err)
 			return
 		}
@@ -93,8 +137,8 @@ func SubscriptionHandler(h Hub) gin.HandlerFunc {
 	}
 }
 
-// UnsubscriptionHandler is a gin http handler for topic unsubscription
-func UnsubscriptionHandler(h Hub) gin.HandlerFunc {
+// UnsubscriptionHandler is a gin http handler for topic unsubscription. The processor must be bound to the identity of the client
+func UnsubscriptionHandler(h Hub, identify Identifier) gin.HandlerFunc {
 	type request struct {
 		Topic       string `json:"topic"`
 		ProcessorID string `json:"processor"`
@@ -113,6 +157,11 @@ func UnsubscriptionHandler(h Hub) gin.HandlerFunc {
 			return
 		}
 
+		if err := checkOwner(c, identify, p); err != nil {
+			c.AbortWithError(http.StatusForbidden, err)
+			return
+		}
+
 		if err := h.Unsubscribe(c.Request.Context(), p, TopicFromString(req.Topic)); err != nil {
 			c.AbortWithError(http.StatusInternalServerError, err)
 			return
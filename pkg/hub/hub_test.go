@@ -0,0 +1,93 @@
+package hub_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mocks "github.com/NicolasDutronc/shoppinglist-be/mocks/pkg/hub"
+	"github.com/NicolasDutronc/shoppinglist-be/pkg/hub"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSubscriptionHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	alice := &hub.Identity{ID: "1", Name: "alice"}
+	bob := &hub.Identity{ID: "2", Name: "bob"}
+	aliceTab := &identifiedProcessor{BaseProcessor: hub.BaseProcessor{ID: "a1"}, identity: alice}
+
+	h := &mocks.Hub{}
+	h.On("GetProcessor", mock.Anything, "a1").Return(aliceTab, nil)
+	h.On("Subscribe", mock.Anything, aliceTab, hub.TopicFromString("shared")).Return(nil)
+	h.On("Unsubscribe", mock.Anything, aliceTab, hub.TopicFromString("shared")).Return(nil)
+
+	// the client is given by a header and only the shared topic can be followed
+	identify := func(c *gin.Context) (*hub.Identity, error) {
+		if c.GetHeader("X-User") == "bob" {
+			return bob, nil
+		}
+		return alice, nil
+	}
+	authorize := func(ctx context.Context, identity *hub.Identity, topic hub.Topic) error {
+		if topic != hub.TopicFromString("shared") {
+			return errors.New("private topic")
+		}
+		return nil
+	}
+
+	r := gin.New()
+	r.POST("/subscribe", hub.SubscriptionHandler(h, identify, authorize))
+	r.POST("/unsubscribe", hub.UnsubscriptionHandler(h, identify))
+	send := func(path string, client string, topic string) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"processor": "a1", "topic": "`+topic+`"}`))
+		req.Header.Set("X-User", client)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// bob cannot use the processor of alice
+	assert.Equal(t, http.StatusForbidden, send("/subscribe", "bob", "shared"))
+	assert.Equal(t, http.StatusForbidden, send("/unsubscribe", "bob", "shared"))
+	// alice cannot follow a topic they are not allowed to
+	assert.Equal(t, http.StatusForbidden, send("/subscribe", "alice", "private"))
+	h.AssertNotCalled(t, "Subscribe", mock.Anything, mock.Anything, mock.Anything)
+
+	assert.Equal(t, http.StatusOK, send("/subscribe", "alice", "shared"))
+	assert.Equal(t, http.StatusOK, send("/unsubscribe", "alice", "shared"))
+}
+
+type digestMessage struct {
+	hub.BaseMessage
+	source hub.Topic
+}
+
+func (msg *digestMessage) GetType() string         { return "digest" }
+func (msg *digestMessage) RestrictedTo() hub.Topic { return msg.source }
+
+func TestDeliverable(t *testing.T) {
+	ctx := context.Background()
+	alice := &hub.Identity{ID: "1", Name: "alice"}
+	authorize := func(ctx context.Context, identity *hub.Identity, topic hub.Topic) error {
+		if topic != hub.TopicFromString("shared") {
+			return errors.New("private topic")
+		}
+		return nil
+	}
+
+	shared := &digestMessage{BaseMessage: hub.NewBaseMessage(1, hub.TopicFromString("digest")), source: hub.TopicFromString("shared")}
+	private := &digestMessage{BaseMessage: hub.NewBaseMessage(2, hub.TopicFromString("digest")), source: hub.TopicFromString("private")}
+	join := &hub.JoinMessage{BaseMessage: hub.NewBaseMessage(3, hub.TopicFromString("private")), Member: alice}
+
+	assert.True(t, hub.Deliverable(ctx, authorize, alice, shared))
+	assert.False(t, hub.Deliverable(ctx, authorize, alice, private))
+	// the messages that are not restricted only depend on the subscription
+	assert.True(t, hub.Deliverable(ctx, authorize, alice, join))
+	assert.True(t, hub.Deliverable(ctx, nil, alice, private))
+}
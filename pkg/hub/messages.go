@@ -1,6 +1,7 @@
 package hub
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 )
@@ -12,6 +13,23 @@ type Message interface {
 	GetTopic() Topic
 }
 
+// Restricted is implemented by the messages relaying the content of another topic, like a digest of several topics.
+// They are only delivered to the processors whose identity may follow that topic
+type Restricted interface {
+	RestrictedTo() Topic
+}
+
+// Deliverable returns true if the message can be sent to the identity: either it is not restricted or the authorizer allows the identity to follow its source topic.
+// A nil authorizer allows every message
+func Deliverable(ctx context.Context, authorize Authorizer, identity *Identity, msg Message) bool {
+	restricted, ok := msg.(Restricted)
+	if !ok || authorize == nil {
+		return true
+	}
+
+	return authorize(ctx, identity, restricted.RestrictedTo()) == nil
+}
+
 // EncodeJSON provides a way to encode and send messages to json
 func EncodeJSON(w io.Writer, msg Message) error {
 	type payload struct {
@@ -0,0 +1,194 @@
+package hub
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Identity describes who is behind a processor
+type Identity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Principal is what the identifier authenticated, like a user with its restrictions, for the authorizers to check. It is never sent to the clients
+	Principal interface{} `json:"-"`
+}
+
+// Identified is implemented by the processors bound to an identity
+type Identified interface {
+	GetIdentity() *Identity
+}
+
+// JoinMessage is published on a topic when someone starts following it
+type JoinMessage struct {
+	BaseMessage
+	Member *Identity `json:"member"`
+}
+
+// GetType returns the type of the message
+func (msg *JoinMessage) GetType() string {
+	return "join"
+}
+
+// LeaveMessage is published on a topic when someone stops following it
+type LeaveMessage struct {
+	BaseMessage
+	Member *Identity `json:"member"`
+}
+
+// GetType returns the type of the message
+func (msg *LeaveMessage) GetType() string {
+	return "leave"
+}
+
+// Presence is a subscription hook tracking who follows each topic.
+// Someone joins a topic with their first subscribed processor and leaves it with their last one
+type Presence struct {
+	mu      sync.RWMutex
+	members map[Topic]map[string]*presenceEntry
+
+	h       Hub
+	queueMu sync.Mutex
+	queue   []Message
+	notify  chan struct{}
+}
+
+type presenceEntry struct {
+	identity   *Identity
+	processors map[string]bool
+}
+
+// NewPresence creates a presence tracker publishing the join and leave messages in the given hub.
+// It has to be registered as a subscription hook of the hub
+func NewPresence(h Hub) *Presence {
+	p := &Presence{
+		members: make(map[Topic]map[string]*presenceEntry),
+		h:       h,
+		queue:   []Message{},
+		notify:  make(chan struct{}, 1),
+	}
+	go p.forward()
+
+	return p
+}
+
+// Members returns the identities following the topic sorted by name
+func (p *Presence) Members(topic Topic) []*Identity {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	members := []*Identity{}
+	for _, entry := range p.members[topic] {
+		members = append(members, entry.identity)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Name < members[j].Name
+	})
+
+	return members
+}
+
+// OnSubscription records the identity of the processor and publishes a join message if it is its first processor on the topic
+func (p *Presence) OnSubscription(ctx context.Context, processor Processor, topic Topic) error {
+	identity := identityOf(processor)
+	if identity == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	topicMembers, exists := p.members[topic]
+	if !exists {
+		topicMembers = make(map[string]*presenceEntry)
+		p.members[topic] = topicMembers
+	}
+
+	entry, exists := topicMembers[identity.ID]
+	if !exists {
+		entry = &presenceEntry{
+			identity:   identity,
+			processors: make(map[string]bool),
+		}
+		topicMembers[identity.ID] = entry
+		p.enqueue(&JoinMessage{
+			BaseMessage: NewBaseMessage(time.Now().Unix(), topic),
+			Member:      identity,
+		})
+	}
+	entry.processors[processor.GetID()] = true
+
+	return nil
+}
+
+// OnUnsubscription forgets the processor and publishes a leave message if it was the last processor of its identity on the topic
+func (p *Presence) OnUnsubscription(ctx context.Context, processor Processor, topic Topic) error {
+	identity := identityOf(processor)
+	if identity == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, exists := p.members[topic][identity.ID]
+	if !exists {
+		return nil
+	}
+
+	delete(entry.processors, processor.GetID())
+	if len(entry.processors) > 0 {
+		return nil
+	}
+
+	delete(p.members[topic], identity.ID)
+	if len(p.members[topic]) == 0 {
+		delete(p.members, topic)
+	}
+	p.enqueue(&LeaveMessage{
+		BaseMessage: NewBaseMessage(time.Now().Unix(), topic),
+		Member:      identity,
+	})
+
+	return nil
+}
+
+func identityOf(processor Processor) *Identity {
+	identified, ok := processor.(Identified)
+	if !ok {
+		return nil
+	}
+
+	return identified.GetIdentity()
+}
+
+// enqueue keeps the message to be published by the forward loop.
+// Hooks run inside the hub loop which would block on a direct publication
+func (p *Presence) enqueue(msg Message) {
+	p.queueMu.Lock()
+	p.queue = append(p.queue, msg)
+	p.queueMu.Unlock()
+
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+// forward publishes the queued messages in order
+func (p *Presence) forward() {
+	for range p.notify {
+		p.queueMu.Lock()
+		messages := p.queue
+		p.queue = []Message{}
+		p.queueMu.Unlock()
+
+		for _, msg := range messages {
+			if err := p.h.Publish(context.Background(), msg); err != nil {
+				log.Printf("error publishing presence message : %v", err)
+			}
+		}
+	}
+}
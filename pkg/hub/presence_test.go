@@ -0,0 +1,61 @@
+package hub_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mocks "github.com/NicolasDutronc/shoppinglist-be/mocks/pkg/hub"
+	"github.com/NicolasDutronc/shoppinglist-be/pkg/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type identifiedProcessor struct {
+	hub.BaseProcessor
+	identity *hub.Identity
+}
+
+func (p *identifiedProcessor) GetDoneChannel() <-chan struct{} { return nil }
+func (p *identifiedProcessor) Process(hub.Message) error       { return nil }
+func (p *identifiedProcessor) HandleClose()                    {}
+func (p *identifiedProcessor) GetIdentity() *hub.Identity      { return p.identity }
+
+func TestPresence(t *testing.T) {
+	ctx := context.Background()
+	topic := hub.TopicFromString("list")
+
+	published := make(chan hub.Message, 10)
+	h := &mocks.Hub{}
+	h.On("Publish", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		published <- args.Get(1).(hub.Message)
+	}).Return(nil)
+
+	alice := &hub.Identity{ID: "1", Name: "alice"}
+	bob := &hub.Identity{ID: "2", Name: "bob"}
+	aliceTab1 := &identifiedProcessor{BaseProcessor: hub.BaseProcessor{ID: "a1"}, identity: alice}
+	aliceTab2 := &identifiedProcessor{BaseProcessor: hub.BaseProcessor{ID: "a2"}, identity: alice}
+	bobTab := &identifiedProcessor{BaseProcessor: hub.BaseProcessor{ID: "b1"}, identity: bob}
+
+	presence := hub.NewPresence(h)
+	assert.NoError(t, presence.OnSubscription(ctx, aliceTab1, topic))
+	assert.NoError(t, presence.OnSubscription(ctx, aliceTab2, topic))
+	assert.NoError(t, presence.OnSubscription(ctx, bobTab, topic))
+	assert.Equal(t, []*hub.Identity{alice, bob}, presence.Members(topic))
+
+	// alice keeps a tab open
+	assert.NoError(t, presence.OnUnsubscription(ctx, aliceTab1, topic))
+	assert.NoError(t, presence.OnUnsubscription(ctx, bobTab, topic))
+	assert.Equal(t, []*hub.Identity{alice}, presence.Members(topic))
+
+	expected := []string{"join", "join", "leave"}
+	for _, msgType := range expected {
+		select {
+		case msg := <-published:
+			assert.Equal(t, msgType, msg.GetType())
+			assert.Equal(t, topic, msg.GetTopic())
+		case <-time.After(time.Second):
+			t.Fatalf("missing %v message", msgType)
+		}
+	}
+}
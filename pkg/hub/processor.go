@@ -11,6 +11,11 @@ type Processor interface {
 	GetID() string
 }
 
+// Disconnectable is implemented by the processors the server can close, such as the websocket ones
+type Disconnectable interface {
+	Disconnect()
+}
+
 // BaseProcessor is a partial implementation of the processor API to implement the methods that are common to all processors
 type BaseProcessor struct {
 	ID       string
@@ -187,3 +187,17 @@ func (hs *Storage) GetMessageHooks(ctx context.Context) ([]Processor, error) {
 
 	return hooks, nil
 }
+
+// RegisterSubscriptionHook adds a hook called on every subscription
+func (hs *Storage) RegisterSubscriptionHook(ctx context.Context, hook SubscriptionHook) error {
+	hs.SubscriptionHooks[hook] = true
+
+	return nil
+}
+
+// UnregisterSubscriptionHook removes a subscription hook
+func (hs *Storage) UnregisterSubscriptionHook(ctx context.Context, hook SubscriptionHook) error {
+	delete(hs.SubscriptionHooks, hook)
+
+	return nil
+}
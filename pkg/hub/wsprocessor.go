@@ -25,11 +25,25 @@ type WebSocketProcessor struct {
 	pingPeriod time.Duration
 	writeWait  time.Duration
 
-	hub Hub
+	hub       Hub
+	identity  *Identity
+	authorize Authorizer
 }
 
-// Process encodes the message in JSON and writes it
+// Identifier returns the identity of the client opening a websocket
+type Identifier func(c *gin.Context) (*Identity, error)
+
+// GetIdentity returns the identity the processor is bound to, nil for an anonymous processor
+func (p *WebSocketProcessor) GetIdentity() *Identity {
+	return p.identity
+}
+
+// Process encodes the message in JSON and writes it. The restricted messages the identity cannot follow are dropped
 func (p *WebSocketProcessor) Process(msg Message) error {
+	if !Deliverable(context.TODO(), p.authorize, p.identity, msg) {
+		return nil
+	}
+
 	p.conn.SetWriteDeadline(time.Now().Add(p.writeWait))
 	w, err := p.conn.NextWriter(websocket.TextMessage)
 	if err != nil {
@@ -53,6 +67,11 @@ func (p *WebSocketProcessor) HandleClose() {
 	p.hub.UnregisterProcessor(context.TODO(), p)
 }
 
+// Disconnect asks the processor to close the connection, as if the client had left
+func (p *WebSocketProcessor) Disconnect() {
+	p.close <- struct{}{}
+}
+
 // GetDoneChannel returns the done channel
 func (p *WebSocketProcessor) GetDoneChannel() <-chan struct{} {
 	return p.close
@@ -98,6 +117,12 @@ func (p *WebSocketProcessor) handleMessages() error {
 
 		switch message.MsgType {
 		case "SUB":
+			if p.authorize != nil {
+				if err := p.authorize(context.TODO(), p.identity, Topic(message.Topic)); err != nil {
+					log.Printf("subscription to %v refused : %v", message.Topic, err)
+					continue
+				}
+			}
 			p.hub.Subscribe(context.TODO(), p, Topic(message.Topic))
 		case "UNSUB":
 			p.hub.Unsubscribe(context.TODO(), p, Topic(message.Topic))
@@ -110,12 +135,24 @@ func (p *WebSocketProcessor) handleMessages() error {
 	return nil
 }
 
-// WebsocketHandler retrieves the processor id, sets up the websocket, initiates the processor and starts it
-func WebsocketHandler(h Hub, writeWait time.Duration, maxMessageSize int64, pongWait time.Duration) gin.HandlerFunc {
+// WebsocketHandler retrieves the processor id, sets up the websocket, initiates the processor and starts it.
+// The processor is bound to the identity returned by the identifier, a nil identifier creates anonymous processors.
+// The topics subscribed through the websocket are checked by the authorizer, a nil authorizer allows every topic
+func WebsocketHandler(h Hub, identify Identifier, authorize Authorizer, writeWait time.Duration, maxMessageSize int64, pongWait time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		processorID := uuid.NewString()
 
+		var identity *Identity
+		if identify != nil {
+			var err error
+			identity, err = identify(c)
+			if err != nil {
+				c.AbortWithError(http.StatusUnauthorized, err)
+				return
+			}
+		}
+
 		upgrader := &websocket.Upgrader{
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
@@ -138,6 +175,8 @@ func WebsocketHandler(h Hub, writeWait time.Duration, maxMessageSize int64, pong
 			readLimit:  maxMessageSize,
 			writeWait:  writeWait,
 			hub:        h,
+			identity:   identity,
+			authorize:  authorize,
 		}
 
 		processor.conn.SetCloseHandler(func(code int, text string) error {
@@ -0,0 +1,84 @@
+package jsonpatch_test
+
+import (
+	"testing"
+
+	"github.com/NicolasDutronc/shoppinglist-be/pkg/jsonpatch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergePatch(t *testing.T) {
+	// the example of RFC 7396
+	document := `{"title": "Goodbye!", "author": {"givenName": "John", "familyName": "Doe"}, "tags": ["example", "sample"], "content": "This will be unchanged"}`
+	patch := `{"title": "Hello!", "phoneNumber": "+01-123-456-7890", "author": {"familyName": null}, "tags": ["example"]}`
+
+	patched, err := jsonpatch.MergePatch([]byte(document), []byte(patch))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"title": "Hello!", "author": {"givenName": "John"}, "tags": ["example"], "content": "This will be unchanged", "phoneNumber": "+01-123-456-7890"}`, string(patched))
+
+	// a patch that is not an object replaces the whole document
+	patched, err = jsonpatch.MergePatch([]byte(document), []byte(`["a"]`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `["a"]`, string(patched))
+
+	_, err = jsonpatch.MergePatch([]byte(document), []byte(`{"title":`))
+	assert.ErrorIs(t, err, jsonpatch.ErrInvalidPatch)
+}
+
+func TestApply(t *testing.T) {
+	// examples of the appendix of RFC 6902
+	for name, tc := range map[string]struct {
+		document string
+		patch    string
+		expected string
+	}{
+		"add an object member":    {`{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": "qux"}]`, `{"baz": "qux", "foo": "bar"}`},
+		"add an array element":    {`{"foo": ["bar", "baz"]}`, `[{"op": "add", "path": "/foo/1", "value": "qux"}]`, `{"foo": ["bar", "qux", "baz"]}`},
+		"append to an array":      {`{"foo": ["bar"]}`, `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`, `{"foo": ["bar", ["abc", "def"]]}`},
+		"remove an object member": {`{"baz": "qux", "foo": "bar"}`, `[{"op": "remove", "path": "/baz"}]`, `{"foo": "bar"}`},
+		"remove an array element": {`{"foo": ["bar", "qux", "baz"]}`, `[{"op": "remove", "path": "/foo/1"}]`, `{"foo": ["bar", "baz"]}`},
+		"replace a value":         {`{"baz": "qux", "foo": "bar"}`, `[{"op": "replace", "path": "/baz", "value": "boo"}]`, `{"baz": "boo", "foo": "bar"}`},
+		"move a value": {
+			`{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			`[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			`{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+		},
+		"move an array element": {`{"foo": ["all", "grass", "cows", "eat"]}`, `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`, `{"foo": ["all", "cows", "eat", "grass"]}`},
+		"copy a value":          {`{"foo": {"bar": 1}}`, `[{"op": "copy", "from": "/foo", "path": "/baz"}, {"op": "replace", "path": "/baz/bar", "value": 2}]`, `{"foo": {"bar": 1}, "baz": {"bar": 2}}`},
+		"test a value":          {`{"baz": "qux", "foo": ["a", 2, "c"]}`, `[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2}]`, `{"baz": "qux", "foo": ["a", 2, "c"]}`},
+		"escaped pointers":      {`{"/": 9, "~1": 10}`, `[{"op": "test", "path": "/~01", "value": 10}, {"op": "remove", "path": "/~1"}]`, `{"~1": 10}`},
+		"add a null value":      {`{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": null}]`, `{"baz": null, "foo": "bar"}`},
+	} {
+		patched, err := jsonpatch.Apply([]byte(tc.document), []byte(tc.patch))
+		assert.NoError(t, err, name)
+		assert.JSONEq(t, tc.expected, string(patched), name)
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	document := []byte(`{"foo": "bar", "items": ["a"]}`)
+
+	for name, tc := range map[string]struct {
+		patch    string
+		expected error
+	}{
+		"not an array of operations": {`{"op": "remove", "path": "/foo"}`, jsonpatch.ErrInvalidPatch},
+		"unknown operation":          {`[{"op": "merge", "path": "/foo"}]`, jsonpatch.ErrInvalidPatch},
+		"missing value":              {`[{"op": "add", "path": "/baz"}]`, jsonpatch.ErrInvalidPatch},
+		"relative pointer":           {`[{"op": "remove", "path": "foo"}]`, jsonpatch.ErrInvalidPatch},
+		"missing member":             {`[{"op": "remove", "path": "/baz"}]`, jsonpatch.ErrInvalidPath},
+		"missing parent":             {`[{"op": "add", "path": "/baz/bat", "value": 1}]`, jsonpatch.ErrInvalidPath},
+		"index out of bounds":        {`[{"op": "add", "path": "/items/2", "value": "b"}]`, jsonpatch.ErrInvalidPath},
+		"leading zero":               {`[{"op": "replace", "path": "/items/00", "value": "b"}]`, jsonpatch.ErrInvalidPath},
+		"move into a child":          {`[{"op": "move", "from": "/items", "path": "/items/0"}]`, jsonpatch.ErrInvalidPath},
+		"failed test":                {`[{"op": "test", "path": "/foo", "value": "baz"}]`, jsonpatch.ErrTestFailed},
+	} {
+		_, err := jsonpatch.Apply(document, []byte(tc.patch))
+		assert.ErrorIs(t, err, tc.expected, name)
+	}
+
+	// a failing operation fails the whole patch
+	patched, err := jsonpatch.Apply(document, []byte(`[{"op": "replace", "path": "/foo", "value": "baz"}, {"op": "test", "path": "/foo", "value": "bar"}]`))
+	assert.ErrorIs(t, err, jsonpatch.ErrTestFailed)
+	assert.Nil(t, patched)
+}
@@ -0,0 +1,383 @@
+// Package keyring signs JWTs with rotating keys and publishes the public keys verifying them
+package keyring
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// rsaKeyBits is the size of the generated RSA keys
+	rsaKeyBits = 2048
+	// syncInterval is the longest time an instance goes without picking up the keys rotated by the others
+	syncInterval = time.Minute
+	// fetchTimeout bounds the lookup of an unknown key in the store
+	fetchTimeout = 5 * time.Second
+)
+
+// ErrUnknownKey is returned when verifying a token signed by a key that is not, or no longer, in the key ring
+var ErrUnknownKey = errors.New("the token was not signed by a known key")
+
+// Signer signs JWTs and resolves the keys verifying them
+type Signer interface {
+	Sign(claims jwt.Claims) (string, error)
+	Keyfunc(token *jwt.Token) (interface{}, error)
+	PublicKeys() *JSONWebKeySet
+}
+
+// key is a signing key of the key ring. A retired key no longer signs but still verifies until the end of the grace period
+type key struct {
+	id        string
+	method    jwt.SigningMethod
+	private   crypto.Signer
+	createdAt time.Time
+	retiredAt *time.Time
+}
+
+// KeyRing signs with its current key and verifies with the keys retired less than a grace period ago,
+// so that rotating the key does not invalidate the outstanding tokens. The keys are kept in a store,
+// which lets the instances sharing it verify each other's tokens and keep them valid across restarts
+type KeyRing struct {
+	store    Store
+	method   jwt.SigningMethod
+	rotation time.Duration
+	grace    time.Duration
+
+	mu   sync.RWMutex
+	keys []*key
+
+	stopChan chan struct{}
+}
+
+// New inits a key ring generating EdDSA or RS256 keys and loads its keys from the store. A key is generated when the store has no current key,
+// or when it is older than rotation or of another algorithm. Once started, the key is rotated every rotation,
+// and the previous keys keep verifying tokens for the grace period, which should outlast the tokens
+func New(ctx context.Context, store Store, algorithm string, rotation time.Duration, grace time.Duration) (*KeyRing, error) {
+	method, err := signingMethod(algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &KeyRing{
+		store:    store,
+		method:   method,
+		rotation: rotation,
+		grace:    grace,
+	}
+	if err := r.sync(ctx); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// signingMethod returns the supported signing method of the algorithm
+func signingMethod(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case jwt.SigningMethodEdDSA.Alg():
+		return jwt.SigningMethodEdDSA, nil
+	case jwt.SigningMethodRS256.Alg():
+		return jwt.SigningMethodRS256, nil
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %s", algorithm)
+	}
+}
+
+// Rotate stores a new signing key, retires the previous ones and forgets the keys whose grace period is over.
+// The other instances sharing the store sign with the new key from their next sync on, and verify its tokens meanwhile
+func (r *KeyRing) Rotate(ctx context.Context) error {
+	next, err := r.generate()
+	if err != nil {
+		return err
+	}
+
+	private, err := x509.MarshalPKCS8PrivateKey(next.private)
+	if err != nil {
+		return err
+	}
+	stored := &StoredKey{
+		ID:        next.id,
+		Algorithm: next.method.Alg(),
+		Private:   private,
+		CreatedAt: next.createdAt,
+	}
+	if err := r.store.InsertKey(ctx, stored); err != nil {
+		return err
+	}
+
+	if _, err := r.store.RetireKeys(ctx, stored, next.createdAt); err != nil {
+		return err
+	}
+	if _, err := r.store.DeleteKeys(ctx, next.createdAt.Add(-r.grace)); err != nil {
+		return err
+	}
+
+	return r.load(ctx)
+}
+
+// sync reloads the keys from the store and rotates the current key when it is missing, due or of another algorithm
+func (r *KeyRing) sync(ctx context.Context) error {
+	if err := r.load(ctx); err != nil {
+		return err
+	}
+
+	r.mu.RLock()
+	due := len(r.keys) == 0
+	if !due {
+		current := r.keys[len(r.keys)-1]
+		due = current.retiredAt != nil || current.method != r.method || time.Since(current.createdAt) >= r.rotation
+	}
+	r.mu.RUnlock()
+
+	if due {
+		return r.Rotate(ctx)
+	}
+
+	return nil
+}
+
+// load replaces the keys of the ring by the keys of the store still verifying tokens
+func (r *KeyRing) load(ctx context.Context) error {
+	stored, err := r.store.FindKeys(ctx, time.Now().Add(-r.grace))
+	if err != nil {
+		return err
+	}
+
+	keys := make([]*key, 0, len(stored))
+	for _, s := range stored {
+		k, err := parseKey(s)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, k)
+	}
+
+	sortKeys(keys)
+
+	r.mu.Lock()
+	r.keys = keys
+	r.mu.Unlock()
+
+	return nil
+}
+
+// fetch looks the key up in the store, for the tokens signed by a key created by another instance since the last sync.
+// The key is kept in the ring, as the current one if it is the most recent key not retired
+func (r *KeyRing) fetch(id string) (*key, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	stored, err := r.store.FindKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	k, err := parseKey(stored)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, known := range r.keys {
+		if known.id == k.id {
+			return known, nil
+		}
+	}
+	r.keys = append(r.keys, k)
+	sortKeys(r.keys)
+
+	return k, nil
+}
+
+// sortKeys puts the retired keys first and then orders the keys by creation, so that the current key is the last one
+func sortKeys(keys []*key) {
+	sort.SliceStable(keys, func(i, j int) bool {
+		if (keys[i].retiredAt == nil) != (keys[j].retiredAt == nil) {
+			return keys[i].retiredAt != nil
+		}
+
+		return keys[i].createdAt.Before(keys[j].createdAt)
+	})
+}
+
+// parseKey decodes a key of the store
+func parseKey(stored *StoredKey) (*key, error) {
+	method, err := signingMethod(stored.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(stored.Private)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode the key %s : %w", stored.ID, err)
+	}
+	private, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("the key %s cannot sign", stored.ID)
+	}
+
+	return &key{
+		id:        stored.ID,
+		method:    method,
+		private:   private,
+		createdAt: stored.CreatedAt,
+		retiredAt: stored.RetiredAt,
+	}, nil
+}
+
+// generate returns a new key for the signing method of the key ring
+func (r *KeyRing) generate() (*key, error) {
+	var private crypto.Signer
+	var err error
+	switch r.method {
+	case jwt.SigningMethodEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	return &key{
+		id:      base64.RawURLEncoding.EncodeToString(id),
+		method:  r.method,
+		private: private,
+		// the stores keep milliseconds
+		createdAt: time.Now().UTC().Truncate(time.Millisecond),
+	}, nil
+}
+
+// Sign signs the claims with the current key, whose id is set in the kid header
+func (r *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	r.mu.RLock()
+	if len(r.keys) == 0 {
+		r.mu.RUnlock()
+		return "", ErrUnknownKey
+	}
+	current := r.keys[len(r.keys)-1]
+	r.mu.RUnlock()
+
+	token := jwt.NewWithClaims(current.method, claims)
+	token.Header["kid"] = current.id
+
+	return token.SignedString(current.private)
+}
+
+// Keyfunc returns the public key matching the kid header of the token, as long as it was not retired more than a grace period ago.
+// A kid the ring does not know yet is looked up in the store. It is meant to be given to the jwt parser
+func (r *KeyRing) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, ErrUnknownKey
+	}
+
+	r.mu.RLock()
+	var k *key
+	for _, known := range r.keys {
+		if known.id == kid {
+			k = known
+			break
+		}
+	}
+	r.mu.RUnlock()
+
+	if k == nil {
+		var err error
+		if k, err = r.fetch(kid); err != nil {
+			return nil, err
+		}
+	}
+
+	if token.Method != k.method {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	if k.retiredAt != nil && time.Since(*k.retiredAt) >= r.grace {
+		return nil, ErrUnknownKey
+	}
+
+	return k.private.Public(), nil
+}
+
+// PublicKeys returns the keys that verify tokens, the current one last
+func (r *KeyRing) PublicKeys() *JSONWebKeySet {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	set := &JSONWebKeySet{Keys: []*JSONWebKey{}}
+	for _, k := range r.keys {
+		if k.retiredAt != nil && now.Sub(*k.retiredAt) >= r.grace {
+			continue
+		}
+
+		jwk, err := NewJSONWebKey(k.id, k.method.Alg(), k.private.Public())
+		if err != nil {
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+// Start syncs the keys with the store, and rotates the key when it is due, until the key ring is stopped.
+// A failed sync is retried on the next tick
+func (r *KeyRing) Start(interrupt chan struct{}) error {
+	defer func() {
+		interrupt <- struct{}{}
+	}()
+
+	r.mu.Lock()
+	r.stopChan = make(chan struct{})
+	stopChan := r.stopChan
+	r.mu.Unlock()
+
+	interval := r.rotation
+	if interval > syncInterval {
+		interval = syncInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.sync(context.Background()); err != nil {
+				log.Printf("cannot sync the signing keys : %v", err)
+			}
+		case <-stopChan:
+			return nil
+		}
+	}
+}
+
+// Stop stops the rotation
+func (r *KeyRing) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopChan != nil {
+		close(r.stopChan)
+		r.stopChan = nil
+	}
+}
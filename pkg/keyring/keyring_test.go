@@ -0,0 +1,175 @@
+package keyring_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NicolasDutronc/shoppinglist-be/pkg/keyring"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func newClaims() *jwt.StandardClaims {
+	return &jwt.StandardClaims{
+		Subject:   "alice",
+		ExpiresAt: time.Now().Add(time.Minute).Unix(),
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	ctx := context.Background()
+
+	for _, algorithm := range []string{"EdDSA", "RS256"} {
+		ring, err := keyring.New(ctx, keyring.NewInMemoryStore(), algorithm, time.Hour, time.Hour)
+		assert.NoError(t, err)
+
+		signed, err := ring.Sign(newClaims())
+		assert.NoError(t, err)
+
+		token, err := jwt.ParseWithClaims(signed, &jwt.StandardClaims{}, ring.Keyfunc)
+		assert.NoError(t, err, algorithm)
+		assert.Equal(t, algorithm, token.Method.Alg())
+		assert.NotEmpty(t, token.Header["kid"])
+
+		// the published key verifies the token on its own
+		set := ring.PublicKeys()
+		assert.Len(t, set.Keys, 1)
+		assert.Equal(t, token.Header["kid"], set.Keys[0].Kid)
+		public, err := set.Keys[0].PublicKey()
+		assert.NoError(t, err)
+		_, err = jwt.ParseWithClaims(signed, &jwt.StandardClaims{}, func(*jwt.Token) (interface{}, error) {
+			return public, nil
+		})
+		assert.NoError(t, err, algorithm)
+	}
+}
+
+func TestUnknownAlgorithm(t *testing.T) {
+	_, err := keyring.New(context.Background(), keyring.NewInMemoryStore(), "none", time.Hour, time.Hour)
+	assert.Error(t, err)
+}
+
+func TestRotationGracePeriod(t *testing.T) {
+	ctx := context.Background()
+
+	ring, err := keyring.New(ctx, keyring.NewInMemoryStore(), "EdDSA", time.Hour, time.Hour)
+	assert.NoError(t, err)
+	signed, err := ring.Sign(newClaims())
+	assert.NoError(t, err)
+
+	// the previous key keeps verifying during the grace period
+	assert.NoError(t, ring.Rotate(ctx))
+	_, err = jwt.ParseWithClaims(signed, &jwt.StandardClaims{}, ring.Keyfunc)
+	assert.NoError(t, err)
+	assert.Len(t, ring.PublicKeys().Keys, 2)
+
+	withoutGrace, err := keyring.New(ctx, keyring.NewInMemoryStore(), "EdDSA", time.Hour, 0)
+	assert.NoError(t, err)
+	signed, err = withoutGrace.Sign(newClaims())
+	assert.NoError(t, err)
+
+	assert.NoError(t, withoutGrace.Rotate(ctx))
+	_, err = jwt.ParseWithClaims(signed, &jwt.StandardClaims{}, withoutGrace.Keyfunc)
+	assert.ErrorIs(t, err, keyring.ErrUnknownKey)
+	assert.Len(t, withoutGrace.PublicKeys().Keys, 1)
+}
+
+func TestAlgorithmConfusion(t *testing.T) {
+	ctx := context.Background()
+
+	ring, err := keyring.New(ctx, keyring.NewInMemoryStore(), "RS256", time.Hour, time.Hour)
+	assert.NoError(t, err)
+	signed, err := ring.Sign(newClaims())
+	assert.NoError(t, err)
+	genuine, _, err := new(jwt.Parser).ParseUnverified(signed, &jwt.StandardClaims{})
+	assert.NoError(t, err)
+
+	// a token claiming the kid of the ring but signed with HMAC is rejected before any key is used
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, newClaims())
+	forged.Header["kid"] = genuine.Header["kid"]
+	forgedString, err := forged.SignedString([]byte("guessed"))
+	assert.NoError(t, err)
+
+	_, err = jwt.ParseWithClaims(forgedString, &jwt.StandardClaims{}, ring.Keyfunc)
+	assert.Error(t, err)
+}
+
+func TestSharedStore(t *testing.T) {
+	ctx := context.Background()
+	store := keyring.NewInMemoryStore()
+
+	first, err := keyring.New(ctx, store, "EdDSA", time.Hour, time.Hour)
+	assert.NoError(t, err)
+	signed, err := first.Sign(newClaims())
+	assert.NoError(t, err)
+
+	// another instance, or the same one restarted, signs with the stored key and verifies the tokens of the first
+	second, err := keyring.New(ctx, store, "EdDSA", time.Hour, time.Hour)
+	assert.NoError(t, err)
+	_, err = jwt.ParseWithClaims(signed, &jwt.StandardClaims{}, second.Keyfunc)
+	assert.NoError(t, err)
+	assert.Equal(t, first.PublicKeys(), second.PublicKeys())
+
+	// a key rotated by one instance is looked up in the store by the other
+	assert.NoError(t, second.Rotate(ctx))
+	rotated, err := second.Sign(newClaims())
+	assert.NoError(t, err)
+	_, err = jwt.ParseWithClaims(rotated, &jwt.StandardClaims{}, first.Keyfunc)
+	assert.NoError(t, err)
+	_, err = jwt.ParseWithClaims(signed, &jwt.StandardClaims{}, first.Keyfunc)
+	assert.NoError(t, err)
+
+	// and the other instance signs with it from then on
+	resigned, err := first.Sign(newClaims())
+	assert.NoError(t, err)
+	a, _, err := new(jwt.Parser).ParseUnverified(rotated, &jwt.StandardClaims{})
+	assert.NoError(t, err)
+	b, _, err := new(jwt.Parser).ParseUnverified(resigned, &jwt.StandardClaims{})
+	assert.NoError(t, err)
+	assert.Equal(t, a.Header["kid"], b.Header["kid"])
+}
+
+func TestSharedStoreGracePeriod(t *testing.T) {
+	ctx := context.Background()
+	store := keyring.NewInMemoryStore()
+
+	first, err := keyring.New(ctx, store, "EdDSA", time.Hour, 0)
+	assert.NoError(t, err)
+	signed, err := first.Sign(newClaims())
+	assert.NoError(t, err)
+
+	// once its grace period is over, the retired key is refused by the instance that rotated it and by the ones loading the keys afterwards
+	second, err := keyring.New(ctx, store, "EdDSA", time.Hour, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, second.Rotate(ctx))
+	_, err = jwt.ParseWithClaims(signed, &jwt.StandardClaims{}, second.Keyfunc)
+	assert.ErrorIs(t, err, keyring.ErrUnknownKey)
+
+	restarted, err := keyring.New(ctx, store, "EdDSA", time.Hour, 0)
+	assert.NoError(t, err)
+	_, err = jwt.ParseWithClaims(signed, &jwt.StandardClaims{}, restarted.Keyfunc)
+	assert.ErrorIs(t, err, keyring.ErrUnknownKey)
+}
+
+func TestAlgorithmChange(t *testing.T) {
+	ctx := context.Background()
+	store := keyring.NewInMemoryStore()
+
+	rsaRing, err := keyring.New(ctx, store, "RS256", time.Hour, time.Hour)
+	assert.NoError(t, err)
+	signed, err := rsaRing.Sign(newClaims())
+	assert.NoError(t, err)
+
+	// switching the algorithm generates a new key, the tokens of the previous one stay valid during the grace period
+	edRing, err := keyring.New(ctx, store, "EdDSA", time.Hour, time.Hour)
+	assert.NoError(t, err)
+	_, err = jwt.ParseWithClaims(signed, &jwt.StandardClaims{}, edRing.Keyfunc)
+	assert.NoError(t, err)
+
+	resigned, err := edRing.Sign(newClaims())
+	assert.NoError(t, err)
+	token, err := jwt.ParseWithClaims(resigned, &jwt.StandardClaims{}, edRing.Keyfunc)
+	assert.NoError(t, err)
+	assert.Equal(t, "EdDSA", token.Method.Alg())
+}
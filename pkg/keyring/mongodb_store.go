@@ -0,0 +1,90 @@
+package keyring
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDBStore keeps the keys in a collection shared by all the instances
+type MongoDBStore struct {
+	coll *mongo.Collection
+}
+
+// NewMongoDBStore is a constructor of MongoDBStore
+func NewMongoDBStore(coll *mongo.Collection) Store {
+	return &MongoDBStore{
+		coll: coll,
+	}
+}
+
+// FindKeys returns the keys still verifying after the given time, oldest first
+func (s *MongoDBStore) FindKeys(ctx context.Context, retiredAfter time.Time) ([]*StoredKey, error) {
+	cursor, err := s.coll.Find(
+		ctx,
+		bson.M{"$or": bson.A{
+			bson.M{"retired_at": nil},
+			bson.M{"retired_at": bson.M{"$gt": retiredAfter}},
+		}},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []*StoredKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// FindKey returns the key with the id
+func (s *MongoDBStore) FindKey(ctx context.Context, id string) (*StoredKey, error) {
+	var key StoredKey
+	if err := s.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&key); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUnknownKey
+		}
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// InsertKey inserts the key
+func (s *MongoDBStore) InsertKey(ctx context.Context, key *StoredKey) error {
+	_, err := s.coll.InsertOne(ctx, key)
+
+	return err
+}
+
+// RetireKeys sets the retirement time of the keys other than the current one created until it, that are not retired yet.
+// A key rotated concurrently by another instance after the current one is left for it
+func (s *MongoDBStore) RetireKeys(ctx context.Context, current *StoredKey, retiredAt time.Time) (int64, error) {
+	res, err := s.coll.UpdateMany(
+		ctx,
+		bson.M{"_id": bson.M{"$ne": current.ID}, "created_at": bson.M{"$lte": current.CreatedAt}, "retired_at": nil},
+		bson.M{"$set": bson.M{"retired_at": retiredAt}},
+	)
+	if err != nil {
+		return -1, err
+	}
+
+	return res.ModifiedCount, nil
+}
+
+// DeleteKeys removes the keys retired before the given time
+func (s *MongoDBStore) DeleteKeys(ctx context.Context, retiredBefore time.Time) (int64, error) {
+	res, err := s.coll.DeleteMany(ctx, bson.M{"retired_at": bson.M{"$lt": retiredBefore}})
+	if err != nil {
+		return -1, err
+	}
+
+	return res.DeletedCount, nil
+}
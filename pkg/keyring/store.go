@@ -0,0 +1,124 @@
+package keyring
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// StoredKey is a signing key as kept in a store. The private key is PKCS #8 encoded, so the store must be protected like the database credentials
+type StoredKey struct {
+	ID        string     `bson:"_id"`
+	Algorithm string     `bson:"algorithm"`
+	Private   []byte     `bson:"private"`
+	CreatedAt time.Time  `bson:"created_at"`
+	RetiredAt *time.Time `bson:"retired_at,omitempty"`
+}
+
+// Store keeps the keys of a key ring, so that every instance sharing it signs and verifies with the same keys
+type Store interface {
+	// FindKeys returns the keys that are not retired or were retired after the given time, oldest first
+	FindKeys(ctx context.Context, retiredAfter time.Time) ([]*StoredKey, error)
+	// FindKey returns the key with the id, retired or not, or ErrUnknownKey
+	FindKey(ctx context.Context, id string) (*StoredKey, error)
+	InsertKey(ctx context.Context, key *StoredKey) error
+	// RetireKeys retires the keys other than the current one created until it, that are not retired yet
+	RetireKeys(ctx context.Context, current *StoredKey, retiredAt time.Time) (int64, error)
+	// DeleteKeys removes the keys retired before the given time
+	DeleteKeys(ctx context.Context, retiredBefore time.Time) (int64, error)
+}
+
+// InMemoryStore keeps the keys of a single instance
+type InMemoryStore struct {
+	mu   sync.Mutex
+	keys []*StoredKey
+}
+
+// NewInMemoryStore is a constructor of InMemoryStore
+func NewInMemoryStore() Store {
+	return &InMemoryStore{
+		keys: []*StoredKey{},
+	}
+}
+
+// FindKeys returns copies of the keys still verifying after the given time, oldest first
+func (s *InMemoryStore) FindKeys(ctx context.Context, retiredAfter time.Time) ([]*StoredKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := []*StoredKey{}
+	for _, k := range s.keys {
+		if k.RetiredAt == nil || k.RetiredAt.After(retiredAfter) {
+			copied := *k
+			keys = append(keys, &copied)
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys, nil
+}
+
+// FindKey returns a copy of the key with the id
+func (s *InMemoryStore) FindKey(ctx context.Context, id string) (*StoredKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, k := range s.keys {
+		if k.ID == id {
+			copied := *k
+			return &copied, nil
+		}
+	}
+
+	return nil, ErrUnknownKey
+}
+
+// InsertKey appends a copy of the key
+func (s *InMemoryStore) InsertKey(ctx context.Context, key *StoredKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *key
+	s.keys = append(s.keys, &copied)
+
+	return nil
+}
+
+// RetireKeys sets the retirement time of the keys other than the current one created until it, that are not retired yet
+func (s *InMemoryStore) RetireKeys(ctx context.Context, current *StoredKey, retiredAt time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for _, k := range s.keys {
+		if k.RetiredAt == nil && k.ID != current.ID && !k.CreatedAt.After(current.CreatedAt) {
+			at := retiredAt
+			k.RetiredAt = &at
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// DeleteKeys removes the keys retired before the given time
+func (s *InMemoryStore) DeleteKeys(ctx context.Context, retiredBefore time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	keys := []*StoredKey{}
+	for _, k := range s.keys {
+		if k.RetiredAt != nil && k.RetiredAt.Before(retiredBefore) {
+			n++
+			continue
+		}
+		keys = append(keys, k)
+	}
+	s.keys = keys
+
+	return n, nil
+}
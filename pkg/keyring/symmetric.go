@@ -0,0 +1,48 @@
+package keyring
+
+import (
+	"github.com/NicolasDutronc/autokey"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Symmetric signs with the HS512 secret of an autokey consumer. The tokens cannot be verified by other services,
+// and they are all invalidated when the secret rotates
+type Symmetric struct {
+	consumer autokey.Consumer
+}
+
+// NewSymmetric inits a signer using the secret of the consumer
+func NewSymmetric(consumer autokey.Consumer) Signer {
+	return &Symmetric{
+		consumer: consumer,
+	}
+}
+
+// Sign signs the claims with the current secret
+func (s *Symmetric) Sign(claims jwt.Claims) (string, error) {
+	secret, err := s.consumer.Get()
+	if err != nil {
+		return "", err
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS512, claims).SignedString([]byte(secret))
+}
+
+// Keyfunc returns the current secret if the token is signed with HS512
+func (s *Symmetric) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if token.Method != jwt.SigningMethodHS512 {
+		return nil, jwt.ErrSignatureInvalid
+	}
+
+	secret, err := s.consumer.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(secret), nil
+}
+
+// PublicKeys returns an empty set as the secret cannot be published
+func (s *Symmetric) PublicKeys() *JSONWebKeySet {
+	return &JSONWebKeySet{Keys: []*JSONWebKey{}}
+}